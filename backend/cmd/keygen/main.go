@@ -0,0 +1,65 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// keygen writes a new access token signing key to <dir>/<kid>.pem. The kid is the current UTC time,
+// so without auth.accessTokenActiveKeyID the new key becomes the active one on the next reload,
+// while the older keys keep verifying the tokens they signed.
+func main() {
+	dir := flag.String("dir", "keys", "directory of the access token keys, auth.accessTokenKeyDir")
+	algorithm := flag.String("alg", "RS256", "RS256 or EdDSA")
+	flag.Parse()
+
+	var (
+		der []byte
+		err error
+	)
+	switch *algorithm {
+	case "RS256":
+		var key *rsa.PrivateKey
+		if key, err = rsa.GenerateKey(rand.Reader, 2048); err == nil {
+			der, err = x509.MarshalPKCS8PrivateKey(key)
+		}
+	case "EdDSA":
+		var key ed25519.PrivateKey
+		if _, key, err = ed25519.GenerateKey(rand.Reader); err == nil {
+			der, err = x509.MarshalPKCS8PrivateKey(key)
+		}
+	default:
+		log.Fatalf("unsupported algorithm %q", *algorithm)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := os.MkdirAll(*dir, 0o700); err != nil {
+		log.Fatal(err)
+	}
+
+	keyID := time.Now().UTC().Format("20060102T150405Z")
+	path := filepath.Join(*dir, keyID+".pem")
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer file.Close()
+
+	if err := pem.Encode(file, &pem.Block{Type: "PRIVATE KEY", Bytes: der}); err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(path)
+}
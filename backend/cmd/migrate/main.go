@@ -0,0 +1,108 @@
+package main
+
+import (
+	"backend/db/migrate"
+	"backend/internal/config"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+)
+
+const usage = `Usage: go run ./cmd/migrate <command>
+
+Commands:
+  up              apply every pending migration
+  down [steps]    revert the last applied migration, or the last <steps> migrations
+  status          list every migration and whether it has been applied
+  to <version>    migrate up or down until <version> is the last applied migration`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+
+	viperConfig := config.NewViper()
+	db := config.NewDatabase(viperConfig)
+
+	migrator, err := migrate.NewMigrator(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx := context.Background()
+
+	switch os.Args[1] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		printMigrations("applied", applied)
+		if err != nil {
+			log.Fatal(err)
+		}
+	case "down":
+		steps := 1
+		if len(os.Args) > 2 {
+			if steps, err = strconv.Atoi(os.Args[2]); err != nil || steps < 1 {
+				log.Fatalf("invalid steps %q", os.Args[2])
+			}
+		}
+
+		reverted, err := migrator.Down(ctx, steps)
+		printMigrations("reverted", reverted)
+		if err != nil {
+			log.Fatal(err)
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		printStatus(statuses)
+	case "to":
+		if len(os.Args) < 3 {
+			log.Fatal("missing version")
+		}
+		version, err := strconv.ParseInt(os.Args[2], 10, 64)
+		if err != nil || version < 0 {
+			log.Fatalf("invalid version %q", os.Args[2])
+		}
+
+		changed, err := migrator.To(ctx, version)
+		printMigrations("migrated", changed)
+		if err != nil {
+			log.Fatal(err)
+		}
+	default:
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+}
+
+func printMigrations(action string, migrations []migrate.Migration) {
+	if len(migrations) == 0 {
+		fmt.Println("nothing to do")
+		return
+	}
+
+	for _, migration := range migrations {
+		fmt.Printf("%s %06d_%s\n", action, migration.Version, migration.Name)
+	}
+}
+
+func printStatus(statuses []migrate.MigrationStatus) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+
+	for _, status := range statuses {
+		appliedAt := "pending"
+		if status.Applied {
+			appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%06d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+	}
+
+	w.Flush()
+}
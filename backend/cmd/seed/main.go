@@ -0,0 +1,69 @@
+package main
+
+import (
+	"backend/db/seeder"
+	"backend/internal/config"
+	"backend/internal/repository"
+	"flag"
+	"log"
+	"strings"
+)
+
+// fileList collects every --file flag
+type fileList []string
+
+func (f *fileList) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *fileList) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+func main() {
+	var files fileList
+	flag.Var(&files, "file", "fixture file (.yaml, .yml or .json) to seed, can be repeated")
+	seed := flag.Int64("seed", 1, "random seed, the same seed always produces the same data")
+	count := flag.Int("count", 0, "generate this many users instead of loading fixtures")
+	postsPerUser := flag.Int("posts-per-user", 3, "posts generated for each user with --count")
+	password := flag.String("password", "password", "password of every user generated with --count")
+	promoteAdmin := flag.String("promote-admin", "", "give the admin role to the existing user with this email, then exit without seeding")
+	flag.Parse()
+
+	viperConfig := config.NewViper()
+	db := config.NewDatabase(viperConfig)
+
+	s := seeder.NewSeeder(db, *seed, repository.NewUserRepository(), repository.NewPostRepository(),
+		repository.NewPostSlugRepository(), repository.NewPostRevisionRepository())
+
+	if *promoteAdmin != "" {
+		if err := s.PromoteAdmin(*promoteAdmin); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("promoted %s to admin", *promoteAdmin)
+		return
+	}
+
+	var (
+		fixtures *seeder.Fixtures
+		err      error
+	)
+	switch {
+	case *count > 0:
+		fixtures = s.Generate(*count, *postsPerUser, *password)
+	case len(files) > 0:
+		fixtures, err = seeder.LoadFixtures(files...)
+	default:
+		fixtures, err = seeder.DefaultFixtures()
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := s.Seed(fixtures); err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("seeded %d users and %d posts", len(fixtures.Users), len(fixtures.Posts))
+}
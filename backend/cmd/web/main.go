@@ -2,26 +2,51 @@ package main
 
 import (
 	"backend/internal/config"
+	"context"
 	"log"
-
-	"github.com/go-playground/validator/v10"
+	"os"
+	"os/signal"
+	"syscall"
 )
 
 func main() {
 	viperConfig := config.NewViper()
-	app := config.NewEcho()
+	app := config.NewEcho(viperConfig)
 	db := config.NewDatabase(viperConfig)
 	redis := config.NewRedisClient(viperConfig)
-	validate := validator.New()
+	validate := config.NewValidator()
+	keyRing := config.NewKeyRing(viperConfig)
+	mailer := config.NewMailer(viperConfig)
+	spamChecker := config.NewSpamChecker(viperConfig)
+	jobs := config.NewScheduler()
 
 	config.Bootstrap(&config.BootstrapConfig{
-		App:      app,
-		DB:       db,
-		Redis:    redis,
-		Validate: validate,
-		Config:   viperConfig,
+		App:         app,
+		DB:          db,
+		Redis:       redis,
+		Validate:    validate,
+		Config:      viperConfig,
+		KeyRing:     keyRing,
+		Mailer:      mailer,
+		SpamChecker: spamChecker,
+		Scheduler:   jobs,
 	})
 
+	jobs.Start(context.Background())
+
+	// Reload the access token keys and the active key ID on SIGHUP, so a new key can be rotated in without a restart
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	go func() {
+		for range reload {
+			if err := config.ReloadKeyRing(keyRing); err != nil {
+				log.Println("reloading access token keys:", err)
+				continue
+			}
+			log.Println("access token keys reloaded")
+		}
+	}()
+
 	port := viperConfig.GetString("web.port")
 	log.Fatal(app.Start(":" + port))
 }
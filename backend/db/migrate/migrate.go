@@ -1,31 +1,313 @@
 package migrate
 
 import (
-	"backend/internal/entity"
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
 
 	"gorm.io/gorm"
 )
 
-func Migrate(db *gorm.DB) error {
-	if err := db.AutoMigrate(&entity.User{}); err != nil {
-		return err
+// lockKey is the key passed to pg_advisory_xact_lock, so only one process
+// at a time can change the schema
+const lockKey int64 = 7_361_902_184
+
+var migrationFileRegex = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+//go:embed sql/*.sql
+var migrationFiles embed.FS
+
+// Migration is a single numbered schema change, loaded from
+// sql/<version>_<name>.up.sql and sql/<version>_<name>.down.sql
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus tells whether a migration has been applied and when
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+}
+
+type schemaMigration struct {
+	Version   int64 `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+type Migrator struct {
+	DB         *gorm.DB
+	Migrations []Migration
+}
+
+// NewMigrator returns a Migrator holding every embedded migration, sorted by version
+func NewMigrator(db *gorm.DB) (*Migrator, error) {
+	migrations, err := LoadMigrations(migrationFiles, "sql")
+	if err != nil {
+		return nil, err
 	}
 
-	if err := db.AutoMigrate(&entity.Post{}); err != nil {
-		return err
+	return &Migrator{
+		DB:         db,
+		Migrations: migrations,
+	}, nil
+}
+
+// LoadMigrations reads every <version>_<name>.(up|down).sql file in dir.
+// Every version must have both an up and a down file.
+func LoadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		matches := migrationFileRegex.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			continue
+		}
+
+		version, err := strconv.ParseInt(matches[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: matches[2]}
+			byVersion[version] = migration
+		} else if migration.Name != matches[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, migration.Name, matches[2])
+		}
+
+		if matches[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both up and down files", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
 }
 
-func Drop(db *gorm.DB) error {
-	if err := db.Migrator().DropTable(&entity.User{}); err != nil {
-		return err
+// Up applies every pending migration and returns the applied ones
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	return m.To(ctx, m.LatestVersion())
+}
+
+// Down reverts the last `steps` applied migrations and returns the reverted ones
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var reverted []Migration
+
+	err := m.withLock(ctx, func(tx *gorm.DB) error {
+		applied, err := m.appliedVersions(tx)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.Migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			migration := m.Migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+			if err := m.revert(tx, migration); err != nil {
+				return err
+			}
+			reverted = append(reverted, migration)
+		}
+
+		return nil
+	})
+
+	return reverted, err
+}
+
+// To migrates the schema up or down until `version` is the last applied migration.
+// Version 0 reverts every migration.
+func (m *Migrator) To(ctx context.Context, version int64) ([]Migration, error) {
+	if version != 0 && m.find(version) == nil {
+		return nil, fmt.Errorf("migration %d doesn't exist", version)
 	}
 
-	if err := db.Migrator().DropTable(&entity.Post{}); err != nil {
+	var changed []Migration
+
+	err := m.withLock(ctx, func(tx *gorm.DB) error {
+		applied, err := m.appliedVersions(tx)
+		if err != nil {
+			return err
+		}
+
+		// Revert newer migrations first, starting from the latest one
+		for i := len(m.Migrations) - 1; i >= 0; i-- {
+			migration := m.Migrations[i]
+			if _, ok := applied[migration.Version]; !ok || migration.Version <= version {
+				continue
+			}
+			if err := m.revert(tx, migration); err != nil {
+				return err
+			}
+			changed = append(changed, migration)
+		}
+
+		// Then apply every pending migration up to version
+		for _, migration := range m.Migrations {
+			if _, ok := applied[migration.Version]; ok || migration.Version > version {
+				continue
+			}
+			if err := m.apply(tx, migration); err != nil {
+				return err
+			}
+			changed = append(changed, migration)
+		}
+
+		return nil
+	})
+
+	return changed, err
+}
+
+// Reset reverts every migration and applies them again, leaving an empty schema
+func (m *Migrator) Reset(ctx context.Context) error {
+	if _, err := m.To(ctx, 0); err != nil {
 		return err
 	}
 
+	_, err := m.Up(ctx)
+
+	return err
+}
+
+// Status lists every known migration and whether it has been applied
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	var statuses []MigrationStatus
+
+	err := m.withLock(ctx, func(tx *gorm.DB) error {
+		applied, err := m.appliedVersions(tx)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.Migrations {
+			status := MigrationStatus{
+				Version: migration.Version,
+				Name:    migration.Name,
+			}
+			if row, ok := applied[migration.Version]; ok {
+				appliedAt := row.AppliedAt
+				status.Applied = true
+				status.AppliedAt = &appliedAt
+			}
+			statuses = append(statuses, status)
+		}
+
+		return nil
+	})
+
+	return statuses, err
+}
+
+// LatestVersion returns the version of the newest known migration
+func (m *Migrator) LatestVersion() int64 {
+	if len(m.Migrations) == 0 {
+		return 0
+	}
+
+	return m.Migrations[len(m.Migrations)-1].Version
+}
+
+// withLock runs fn inside a single transaction holding the migration advisory lock.
+// Postgres DDL is transactional, so a failing migration leaves the schema untouched,
+// and a second replica starting at the same time waits here until the first one is done.
+func (m *Migrator) withLock(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	return m.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", lockKey).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+			version bigint PRIMARY KEY,
+			name text NOT NULL,
+			applied_at timestamptz NOT NULL DEFAULT now()
+		)`).Error; err != nil {
+			return err
+		}
+
+		return fn(tx)
+	})
+}
+
+func (m *Migrator) appliedVersions(tx *gorm.DB) (map[int64]schemaMigration, error) {
+	var rows []schemaMigration
+	if err := tx.Order("version asc").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int64]schemaMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+
+	return applied, nil
+}
+
+func (m *Migrator) apply(tx *gorm.DB, migration Migration) error {
+	if err := tx.Exec(migration.Up).Error; err != nil {
+		return fmt.Errorf("applying migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	return tx.Create(&schemaMigration{
+		Version:   migration.Version,
+		Name:      migration.Name,
+		AppliedAt: time.Now(),
+	}).Error
+}
+
+func (m *Migrator) revert(tx *gorm.DB, migration Migration) error {
+	if err := tx.Exec(migration.Down).Error; err != nil {
+		return fmt.Errorf("reverting migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	return tx.Delete(&schemaMigration{}, "version = ?", migration.Version).Error
+}
+
+func (m *Migrator) find(version int64) *Migration {
+	for i := range m.Migrations {
+		if m.Migrations[i].Version == version {
+			return &m.Migrations[i]
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,6 @@
+ALTER TABLE users
+    ADD COLUMN role text NOT NULL DEFAULT 'reader'
+    CONSTRAINT chk_users_role CHECK (role IN ('reader', 'author', 'editor', 'admin'));
+
+-- Every existing user could post before roles existed, so keep them as authors
+UPDATE users SET role = 'author';
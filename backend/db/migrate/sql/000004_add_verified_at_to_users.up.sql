@@ -0,0 +1,4 @@
+ALTER TABLE users ADD COLUMN verified_at timestamptz;
+
+-- Users registered before email verification existed are trusted as verified
+UPDATE users SET verified_at = created_at;
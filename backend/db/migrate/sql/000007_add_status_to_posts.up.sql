@@ -0,0 +1,7 @@
+ALTER TABLE posts ADD COLUMN status text NOT NULL DEFAULT 'draft';
+ALTER TABLE posts ADD COLUMN published_at timestamptz;
+
+-- Posts written before drafts existed were public right away
+UPDATE posts SET status = 'published', published_at = created_at;
+
+CREATE INDEX IF NOT EXISTS idx_posts_status_published_at ON posts (status, published_at);
@@ -0,0 +1,17 @@
+ALTER TABLE posts ADD COLUMN slug text;
+
+-- Existing posts get a slug from the ASCII letters and digits of their title, the ID keeps it unique
+UPDATE posts SET slug = coalesce(nullif(trim(BOTH '-' FROM regexp_replace(lower(title), '[^a-z0-9]+', '-', 'g')), ''), 'post')
+    || '-' || id;
+
+ALTER TABLE posts ALTER COLUMN slug SET NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_posts_slug ON posts (slug);
+
+-- Previous slugs of renamed posts, so old links keep resolving to the post
+CREATE TABLE IF NOT EXISTS post_slugs (
+    slug text PRIMARY KEY,
+    post_id bigint NOT NULL REFERENCES posts (id) ON DELETE CASCADE,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_post_slugs_post_id ON post_slugs (post_id);
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS post_revisions (
+    id bigserial PRIMARY KEY,
+    post_id bigint NOT NULL REFERENCES posts (id) ON DELETE CASCADE,
+    number integer NOT NULL,
+    title text NOT NULL,
+    content text NOT NULL,
+    user_id text REFERENCES users (id) ON DELETE SET NULL,
+    restored_from integer,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_post_revisions_post_id_number ON post_revisions (post_id, number);
+
+-- The current version of every existing post is its first revision
+INSERT INTO post_revisions (post_id, number, title, content, user_id, created_at)
+SELECT id, 1, title, content, user_id, created_at FROM posts;
@@ -0,0 +1,5 @@
+-- Posts in the trash would come back, so they are removed for good
+DELETE FROM posts WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_posts_deleted_at;
+ALTER TABLE posts DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS categories (
+    id bigserial PRIMARY KEY,
+    name text NOT NULL,
+    slug text NOT NULL,
+    parent_id bigint REFERENCES categories (id) ON DELETE RESTRICT,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_slug ON categories (slug);
+CREATE INDEX IF NOT EXISTS idx_categories_parent_id ON categories (parent_id);
+
+-- Posts of a deleted category are left without one
+ALTER TABLE posts ADD COLUMN category_id bigint REFERENCES categories (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_posts_category_id ON posts (category_id);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS comments (
+    id bigserial PRIMARY KEY,
+    post_id bigint NOT NULL REFERENCES posts (id) ON DELETE CASCADE,
+    parent_id bigint REFERENCES comments (id) ON DELETE CASCADE,
+    user_id text NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    content text NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    -- Deleted comments with replies stay in the thread without their content
+    deleted_at timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_comments_post_id ON comments (post_id, parent_id);
+CREATE INDEX IF NOT EXISTS idx_comments_parent_id ON comments (parent_id);
@@ -0,0 +1,5 @@
+ALTER TABLE users DROP COLUMN IF EXISTS trusted_commenter;
+
+DROP INDEX IF EXISTS idx_comments_status;
+ALTER TABLE comments DROP COLUMN IF EXISTS spam_reason;
+ALTER TABLE comments DROP COLUMN IF EXISTS status;
@@ -0,0 +1,9 @@
+ALTER TABLE comments ADD COLUMN status text NOT NULL DEFAULT 'pending';
+ALTER TABLE comments ADD COLUMN spam_reason text;
+
+-- Comments written before moderation were visible already
+UPDATE comments SET status = 'approved';
+
+CREATE INDEX IF NOT EXISTS idx_comments_status ON comments (status, id);
+
+ALTER TABLE users ADD COLUMN trusted_commenter boolean NOT NULL DEFAULT false;
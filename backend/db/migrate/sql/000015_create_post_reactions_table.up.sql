@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS post_reactions (
+    post_id bigint NOT NULL REFERENCES posts (id) ON DELETE CASCADE,
+    user_id text NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    kind text NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (post_id, user_id, kind)
+);
+
+-- Counts are kept in Redis and reconciled here by a background job
+CREATE TABLE IF NOT EXISTS post_reaction_counts (
+    post_id bigint NOT NULL REFERENCES posts (id) ON DELETE CASCADE,
+    kind text NOT NULL,
+    count bigint NOT NULL,
+    PRIMARY KEY (post_id, kind)
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS reading_list_items;
+DROP TABLE IF EXISTS reading_lists;
+DROP TABLE IF EXISTS bookmarks;
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS bookmarks (
+    user_id text NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    post_id bigint NOT NULL REFERENCES posts (id) ON DELETE CASCADE,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, post_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bookmarks_post_id ON bookmarks (post_id);
+
+CREATE TABLE IF NOT EXISTS reading_lists (
+    id bigserial PRIMARY KEY,
+    user_id text NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name text NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reading_lists_user_id_name ON reading_lists (user_id, name);
+
+CREATE TABLE IF NOT EXISTS reading_list_items (
+    reading_list_id bigint NOT NULL REFERENCES reading_lists (id) ON DELETE CASCADE,
+    post_id bigint NOT NULL REFERENCES posts (id) ON DELETE CASCADE,
+    position int NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (reading_list_id, post_id)
+);
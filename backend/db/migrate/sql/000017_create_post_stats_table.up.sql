@@ -0,0 +1,8 @@
+-- Views are counted in Redis and flushed here by a background job, a row per post and day (UTC)
+CREATE TABLE IF NOT EXISTS post_stats (
+    post_id bigint NOT NULL REFERENCES posts (id) ON DELETE CASCADE,
+    date date NOT NULL,
+    views bigint NOT NULL DEFAULT 0,
+    uniques bigint NOT NULL DEFAULT 0,
+    PRIMARY KEY (post_id, date)
+);
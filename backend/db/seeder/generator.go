@@ -0,0 +1,98 @@
+package seeder
+
+import (
+	"backend/internal/constant"
+	"backend/internal/utils"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	firstNames = []string{"Adi", "Ayu", "Budi", "Citra", "Dewi", "Eko", "Fajar", "Gita", "Hana", "Indra",
+		"Joko", "Kartika", "Lestari", "Maya", "Nanda", "Oscar", "Putri", "Rizky", "Sari", "Tono",
+		"Umar", "Vina", "Wahyu", "Yusuf", "Zahra", "Alice", "Bob", "Carol", "David", "Emma"}
+	lastNames = []string{"Pratama", "Santoso", "Wijaya", "Saputra", "Hidayat", "Kusuma", "Lubis", "Nasution",
+		"Siregar", "Gunawan", "Halim", "Setiawan", "Susanto", "Tanjung", "Utomo", "Smith", "Johnson", "Brown"}
+	titleWords = []string{"Building", "Scaling", "Testing", "Debugging", "Designing", "Deploying", "Understanding",
+		"Refactoring", "Monitoring", "Securing"}
+	topics = []string{"Go services", "React components", "PostgreSQL indexes", "Redis caches", "REST APIs",
+		"Docker images", "JWT authentication", "background jobs", "database migrations", "CI pipelines"}
+	suffixes = []string{"in Production", "from Scratch", "the Right Way", "for Beginners", "at Scale",
+		"Without Tears", "in Practice", "Step by Step"}
+	contentWords = strings.Fields(`the a we our this that it with without for from into over under
+		service request response handler database query index cache token user post server client
+		deploy build test review release latency throughput error retry timeout schema migration
+		simple fast reliable careful small large new old better worse first last every each
+		write read update delete measure improve ship learn explain keep avoid prefer`)
+)
+
+// generatedBaseTime anchors generated created_at values, so they are reproducible too
+var generatedBaseTime = time.Date(2024, time.January, 1, 8, 0, 0, 0, time.UTC)
+
+// Generate returns userCount users with postsPerUser posts each. Everything, including
+// emails and titles, is drawn from the seeder's random source.
+func (s *Seeder) Generate(userCount int, postsPerUser int, password string) *Fixtures {
+	fixtures := new(Fixtures)
+
+	for i := 1; i <= userCount; i++ {
+		firstName := firstNames[s.Rand.Intn(len(firstNames))]
+		lastName := lastNames[s.Rand.Intn(len(lastNames))]
+
+		user := UserFixture{
+			Name:     firstName + " " + lastName,
+			Email:    fmt.Sprintf("%s.%s%d@example.com", strings.ToLower(firstName), strings.ToLower(lastName), i),
+			Password: password,
+			Role:     constant.ROLE_AUTHOR,
+		}
+		fixtures.Users = append(fixtures.Users, user)
+
+		for j := 1; j <= postsPerUser; j++ {
+			createdAt := generatedBaseTime.Add(time.Duration(s.Rand.Intn(365*24)) * time.Hour)
+			title := s.generateTitle()
+
+			fixtures.Posts = append(fixtures.Posts, PostFixture{
+				// Titles repeat, the user and post numbers keep slugs unique since posts are upserted by slug
+				Slug:      fmt.Sprintf("%s-%d-%d", utils.Slugify(title), i, j),
+				Title:     title,
+				Content:   s.generateContent(3 + s.Rand.Intn(5)),
+				Author:    user.Email,
+				CreatedAt: &createdAt,
+			})
+		}
+	}
+
+	return fixtures
+}
+
+func (s *Seeder) generateTitle() string {
+	return fmt.Sprintf("%s %s %s",
+		titleWords[s.Rand.Intn(len(titleWords))],
+		topics[s.Rand.Intn(len(topics))],
+		suffixes[s.Rand.Intn(len(suffixes))])
+}
+
+// generateContent returns paragraphCount paragraphs of 3 to 6 sentences
+func (s *Seeder) generateContent(paragraphCount int) string {
+	paragraphs := make([]string, paragraphCount)
+
+	for i := range paragraphs {
+		sentences := make([]string, 3+s.Rand.Intn(4))
+		for j := range sentences {
+			sentences[j] = s.generateSentence(6 + s.Rand.Intn(10))
+		}
+		paragraphs[i] = strings.Join(sentences, " ")
+	}
+
+	return strings.Join(paragraphs, "\n\n")
+}
+
+func (s *Seeder) generateSentence(wordCount int) string {
+	words := make([]string, wordCount)
+	for i := range words {
+		words[i] = contentWords[s.Rand.Intn(len(contentWords))]
+	}
+	words[0] = strings.ToUpper(words[0][:1]) + words[0][1:]
+
+	return strings.Join(words, " ") + "."
+}
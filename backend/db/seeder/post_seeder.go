@@ -1,33 +1,77 @@
 package seeder
 
 import (
+	"backend/internal/constant"
 	"backend/internal/entity"
-	"backend/internal/repository"
 	"backend/internal/utils"
+	"errors"
+	"fmt"
 	"time"
 
 	"gorm.io/gorm"
 )
 
-func SeedsPost(db *gorm.DB, postRepository *repository.PostRepository, users *[]entity.User) ([]entity.Post, error) {
-	var posts []entity.Post
+// seedPosts upserts posts by slug
+func (s *Seeder) seedPosts(tx *gorm.DB, fixtures []PostFixture, usersByEmail map[string]*entity.User) error {
+	for _, fixture := range fixtures {
+		if len(fixture.Slug) == 0 || utils.Slugify(fixture.Slug) != fixture.Slug {
+			return fmt.Errorf("post %q needs a slug of lowercase ASCII words joined by dashes, like %q", fixture.Title, utils.Slugify(fixture.Title))
+		}
+
+		author, ok := usersByEmail[fixture.Author]
+		if !ok {
+			author = new(entity.User)
+			if err := s.UserRepository.FindByEmail(tx, author, fixture.Author); err != nil {
+				return fmt.Errorf("author %q of post %q: %w", fixture.Author, fixture.Title, err)
+			}
+			usersByEmail[author.Email] = author
+		}
+
+		post := new(entity.Post)
+		err := s.PostRepository.FindBySlug(tx, post, fixture.Slug)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			post = &entity.Post{
+				Slug:      fixture.Slug,
+				CreatedAt: time.Now(),
+			}
+			if fixture.CreatedAt != nil {
+				post.CreatedAt = *fixture.CreatedAt
+			}
 
-	for _, user := range *users {
-		tx := db.Begin()
-		postCreated := &entity.Post{
-			Title:     "Title" + utils.GenerateRandomString(10),
-			Content:   "Content " + utils.GenerateRandomString(100),
-			CreatedAt: time.Now(),
-			UserID:    user.ID,
+			// A renamed post may have used the slug before, the fixture takes it over so the slug finds one post
+			if err := s.PostSlugRepository.DeleteBySlug(tx, post.Slug); err != nil {
+				return err
+			}
 		}
-		err := postRepository.Repository.Save(tx, postCreated)
-		if err != nil {
-			return posts, err
+
+		// Like an edit through the API, a new title or content is a new revision
+		edited := post.ID == 0 || post.Title != fixture.Title || post.Content != fixture.Content
+		post.Title = fixture.Title
+		post.Content = fixture.Content
+		post.UserID = author.ID
+
+		// Seeded posts are public unless the fixture says otherwise, published when they were created
+		post.Status = fixture.Status
+		if len(post.Status) == 0 {
+			post.Status = constant.POST_STATUS_PUBLISHED
+		}
+		post.PublishedAt = fixture.PublishedAt
+		if post.PublishedAt == nil && (post.Status == constant.POST_STATUS_PUBLISHED || post.Status == constant.POST_STATUS_ARCHIVED) {
+			post.PublishedAt = &post.CreatedAt
 		}
-		tx.Commit()
 
-		posts = append(posts, *postCreated)
+		if err := s.PostRepository.Repository.Save(tx, post); err != nil {
+			return err
+		}
+		if edited {
+			if _, err := s.PostRevisionRepository.Record(tx, post, author.ID, nil); err != nil {
+				return err
+			}
+		}
 	}
 
-	return posts, nil
+	return nil
 }
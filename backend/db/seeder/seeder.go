@@ -0,0 +1,165 @@
+package seeder
+
+import (
+	"backend/internal/constant"
+	"backend/internal/repository"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+)
+
+//go:embed fixtures/default.yaml
+var defaultFixtures []byte
+
+// Fixtures holds every entity that can be seeded. A new entity gets its own field here
+// and its own seed function in Seed, so the fixture files stay one document per dataset.
+type Fixtures struct {
+	Users []UserFixture `json:"users" yaml:"users"`
+	Posts []PostFixture `json:"posts" yaml:"posts"`
+}
+
+type UserFixture struct {
+	Name       string `json:"name" yaml:"name"`
+	Email      string `json:"email" yaml:"email"`
+	Password   string `json:"password" yaml:"password"`
+	Role       string `json:"role" yaml:"role"`             // Defaults to reader, like a registered user
+	Unverified bool   `json:"unverified" yaml:"unverified"` // Seeded users have a verified email unless set
+}
+
+type PostFixture struct {
+	Slug      string     `json:"slug" yaml:"slug"` // Identifies the post, so it is unique across every fixture
+	Title     string     `json:"title" yaml:"title"`
+	Content   string     `json:"content" yaml:"content"`
+	Author    string     `json:"author" yaml:"author"` // Email of the author, must be seeded in the same run or already exist
+	Status    string     `json:"status" yaml:"status"` // draft, scheduled, published or archived, defaults to published
+	CreatedAt *time.Time `json:"created_at" yaml:"created_at"`
+
+	// When a scheduled post gets published, required for scheduled posts. Published and archived
+	// posts default to their creation time, and drafts have none.
+	PublishedAt *time.Time `json:"published_at" yaml:"published_at"`
+}
+
+// Validate checks the values the database would otherwise take as they are, like a status the
+// scheduler never picks up, and names the first fixture that is wrong
+func (f *Fixtures) Validate() error {
+	for _, user := range f.Users {
+		if len(user.Role) > 0 && !slices.Contains(constant.ROLES, user.Role) {
+			return fmt.Errorf("user %q has role %q, want one of %s", user.Email, user.Role, strings.Join(constant.ROLES, ", "))
+		}
+	}
+
+	for _, post := range f.Posts {
+		if len(post.Status) > 0 && !slices.Contains(constant.POST_STATUSES, post.Status) {
+			return fmt.Errorf("post %q has status %q, want one of %s", post.Slug, post.Status, strings.Join(constant.POST_STATUSES, ", "))
+		}
+		if post.Status == constant.POST_STATUS_SCHEDULED && post.PublishedAt == nil {
+			return fmt.Errorf("post %q is scheduled, so it needs a published_at", post.Slug)
+		}
+		if post.Status == constant.POST_STATUS_DRAFT && post.PublishedAt != nil {
+			return fmt.Errorf("post %q is a draft, so it can't have a published_at", post.Slug)
+		}
+	}
+
+	return nil
+}
+
+// Append adds every entity of other after the entities of f
+func (f *Fixtures) Append(other *Fixtures) {
+	f.Users = append(f.Users, other.Users...)
+	f.Posts = append(f.Posts, other.Posts...)
+}
+
+type Seeder struct {
+	DB                     *gorm.DB
+	Rand                   *rand.Rand
+	UserRepository         *repository.UserRepository
+	PostRepository         *repository.PostRepository
+	PostSlugRepository     *repository.PostSlugRepository
+	PostRevisionRepository *repository.PostRevisionRepository
+}
+
+// NewSeeder returns a seeder whose generated IDs and data only depend on seed,
+// so running it twice with the same seed produces the same rows
+func NewSeeder(db *gorm.DB, seed int64, userRepository *repository.UserRepository,
+	postRepository *repository.PostRepository, postSlugRepository *repository.PostSlugRepository,
+	postRevisionRepository *repository.PostRevisionRepository) *Seeder {
+	return &Seeder{
+		DB:                     db,
+		Rand:                   rand.New(rand.NewSource(seed)),
+		UserRepository:         userRepository,
+		PostRepository:         postRepository,
+		PostSlugRepository:     postSlugRepository,
+		PostRevisionRepository: postRevisionRepository,
+	}
+}
+
+// DefaultFixtures returns the fixtures embedded in fixtures/default.yaml
+func DefaultFixtures() (*Fixtures, error) {
+	fixtures := new(Fixtures)
+	if err := yaml.Unmarshal(defaultFixtures, fixtures); err != nil {
+		return nil, err
+	}
+	if err := fixtures.Validate(); err != nil {
+		return nil, fmt.Errorf("loading default fixtures: %w", err)
+	}
+
+	return fixtures, nil
+}
+
+// LoadFixtures reads and merges fixture files, decoding each one as JSON or YAML by its extension
+func LoadFixtures(paths ...string) (*Fixtures, error) {
+	fixtures := new(Fixtures)
+
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		fileFixtures := new(Fixtures)
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".json":
+			err = json.Unmarshal(content, fileFixtures)
+		case ".yaml", ".yml":
+			err = yaml.Unmarshal(content, fileFixtures)
+		default:
+			err = fmt.Errorf("unsupported fixture file extension %q", filepath.Ext(path))
+		}
+		if err == nil {
+			err = fileFixtures.Validate()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("loading %s: %w", path, err)
+		}
+
+		fixtures.Append(fileFixtures)
+	}
+
+	return fixtures, nil
+}
+
+// Seed upserts every fixture in a single transaction. Users are matched by email and
+// posts by slug, so seeding the same fixtures again updates rows instead of duplicating them.
+func (s *Seeder) Seed(fixtures *Fixtures) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		usersByEmail, err := s.seedUsers(tx, fixtures.Users)
+		if err != nil {
+			return err
+		}
+
+		if err := s.seedPosts(tx, fixtures.Posts, usersByEmail); err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
@@ -1,38 +1,89 @@
 package seeder
 
 import (
+	"backend/internal/constant"
 	"backend/internal/entity"
-	"backend/internal/repository"
 	"backend/internal/utils"
+	"errors"
 	"fmt"
 	"time"
 
 	"gorm.io/gorm"
 )
 
-// Seeds `users` table by populating dummy user such as user1@mail.com, etc.
-func SeedsUser(db *gorm.DB, userRepository *repository.UserRepository) ([]entity.User, error) {
-	var users []entity.User
+// seedUsers upserts users by email and returns every seeded user keyed by email
+func (s *Seeder) seedUsers(tx *gorm.DB, fixtures []UserFixture) (map[string]*entity.User, error) {
+	usersByEmail := make(map[string]*entity.User, len(fixtures))
 
-	for i := 1; i <= 3; i++ {
-		userPassword, _ := utils.HashUserPassword(fmt.Sprintf("user%d", i))
+	// Generated users share the same password, so only hash each distinct password once
+	hashedPasswords := make(map[string]string)
 
-		tx := db.Begin()
-		userCreated := entity.User{
-			ID:        "USR-" + utils.GenerateRandomString(20),
-			Name:      fmt.Sprintf("user %d", i),
-			Email:     fmt.Sprintf("user%d@mail.com", i),
-			Password:  userPassword,
-			CreatedAt: time.Now(),
+	for _, fixture := range fixtures {
+		if fixture.Email == "" || fixture.Password == "" {
+			return nil, fmt.Errorf("user %q must have an email and a password", fixture.Name)
 		}
-		err := userRepository.Repository.Save(tx, &userCreated)
-		if err != nil {
-			return users, err
+
+		hashed, ok := hashedPasswords[fixture.Password]
+		if !ok {
+			var err error
+			if hashed, err = utils.HashUserPassword(fixture.Password); err != nil {
+				return nil, err
+			}
+			hashedPasswords[fixture.Password] = hashed
+		}
+
+		// The ID is drawn even when the user exists, so the following IDs don't depend on the database state
+		newID := "USR" + utils.GenerateRandomStringWithRand(s.Rand, 20)
+
+		user := new(entity.User)
+		err := s.UserRepository.FindByEmail(tx, user, fixture.Email)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			user = &entity.User{
+				ID:        newID,
+				Email:     fixture.Email,
+				CreatedAt: time.Now(),
+			}
+		}
+
+		user.Name = fixture.Name
+		user.Password = hashed
+		user.Role = fixture.Role
+		if user.Role == "" {
+			user.Role = constant.ROLE_READER
 		}
-		tx.Commit()
 
-		users = append(users, userCreated)
+		if fixture.Unverified {
+			user.VerifiedAt = nil
+		} else if user.VerifiedAt == nil {
+			verifiedAt := time.Now()
+			user.VerifiedAt = &verifiedAt
+		}
+
+		if err := s.UserRepository.Save(tx, user); err != nil {
+			return nil, err
+		}
+
+		usersByEmail[user.Email] = user
 	}
 
-	return users, nil
+	return usersByEmail, nil
+}
+
+// PromoteAdmin gives the admin role to the existing user with email and leaves every other column as it is,
+// so it can bootstrap the first admin of a database without reseeding anyone's password
+func (s *Seeder) PromoteAdmin(email string) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		user := new(entity.User)
+		if err := s.UserRepository.FindByEmail(tx, user, email); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("no user with email %q", email)
+			}
+			return err
+		}
+
+		return tx.Model(user).Update("role", constant.ROLE_ADMIN).Error
+	})
 }
@@ -0,0 +1,49 @@
+package apperror
+
+import "strings"
+
+// Codes sent to clients, they must not change once released
+const (
+	CODE_INTERNAL          = "INTERNAL"
+	CODE_BAD_REQUEST       = "BAD_REQUEST"
+	CODE_VALIDATION_FAILED = "VALIDATION_FAILED"
+	CODE_UNAUTHORIZED      = "UNAUTHORIZED"
+
+	CODE_EMPTY_TOKEN   = "EMPTY_TOKEN"   // Frontend will redirect to login page
+	CODE_INVALID_TOKEN = "INVALID_TOKEN" // Frontend will redirect to login page
+	CODE_EXPIRED_TOKEN = "EXPIRED_TOKEN" // Frontend will request a new access token to /api/auth/refresh
+
+	CODE_INVALID_CREDENTIALS        = "INVALID_CREDENTIALS"
+	CODE_WRONG_PASSWORD             = "WRONG_PASSWORD"
+	CODE_INVALID_MFA_CODE           = "INVALID_MFA_CODE"
+	CODE_INVALID_VERIFICATION_TOKEN = "INVALID_VERIFICATION_TOKEN"
+	CODE_INVALID_RESET_TOKEN        = "INVALID_RESET_TOKEN"
+	CODE_TOTP_ALREADY_ENABLED       = "TOTP_ALREADY_ENABLED"
+	CODE_TOTP_NOT_ENABLED           = "TOTP_NOT_ENABLED"
+	CODE_TOTP_NOT_ENROLLED          = "TOTP_NOT_ENROLLED"
+
+	CODE_EMAIL_NOT_VERIFIED  = "EMAIL_NOT_VERIFIED"
+	CODE_ROLE_NOT_ALLOWED    = "ROLE_NOT_ALLOWED"
+	CODE_PERMISSION_DENIED   = "PERMISSION_DENIED"
+	CODE_OWN_ROLE_CHANGE     = "OWN_ROLE_CHANGE"
+	CODE_ACCOUNT_LOCKED      = "ACCOUNT_LOCKED"
+	CODE_TOO_MANY_LOGINS     = "TOO_MANY_LOGIN_ATTEMPTS"
+	CODE_EMAIL_SENT_RECENTLY = "EMAIL_SENT_RECENTLY"
+	CODE_UNSUPPORTED_LOCALE  = "UNSUPPORTED_LOCALE"
+
+	CODE_INVALID_PUBLISH_TIME       = "INVALID_PUBLISH_TIME"
+	CODE_INVALID_TAG                = "INVALID_TAG"
+	CODE_CATEGORY_CYCLE             = "CATEGORY_CYCLE"
+	CODE_CATEGORY_NOT_EMPTY         = "CATEGORY_NOT_EMPTY"
+	CODE_INVALID_READING_LIST_ORDER = "INVALID_READING_LIST_ORDER"
+	CODE_INVALID_DATE_RANGE         = "INVALID_DATE_RANGE"
+
+	// Translations of entity errors fall back to these codes, with the entity as parameter
+	CODE_ENTITY_NOT_FOUND      = "ENTITY_NOT_FOUND"
+	CODE_ENTITY_ALREADY_EXISTS = "ENTITY_ALREADY_EXISTS"
+)
+
+// entityCode turns an entity name like "recovery code" into RECOVERY_CODE
+func entityCode(entity string) string {
+	return strings.ToUpper(strings.ReplaceAll(entity, " ", "_"))
+}
@@ -0,0 +1,189 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Kind tells what went wrong in terms of the domain, the delivery layer maps each kind to a response status
+type Kind int
+
+const (
+	KIND_INTERNAL Kind = iota
+	KIND_BAD_REQUEST
+	KIND_VALIDATION
+	KIND_UNAUTHORIZED
+	KIND_FORBIDDEN
+	KIND_NOT_FOUND
+	KIND_CONFLICT
+	KIND_RATE_LIMITED
+)
+
+// Error is the error returned by usecases. Code is a stable, machine-readable identifier clients
+// can rely on, while Message is meant for humans and may change.
+type Error struct {
+	Kind       Kind
+	Code       string
+	Message    string
+	Params     map[string]string // Values of the placeholders in the translations of Code
+	Fields     []FieldError      // Set for KIND_VALIDATION
+	RetryAfter time.Duration     // Set for KIND_RATE_LIMITED
+	Err        error             // The cause, it is logged but never sent to clients
+}
+
+// FieldError is a request field failing a validation rule
+type FieldError struct {
+	Field string // Name of the struct field
+	Path  string // JSON path of the field, like tags[0].name
+	Type  string // string, number or array, so rules like min can be described for each type
+	Tag   string
+	Param string
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Message, e.Err)
+	}
+
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches errors of the same kind and code, so errors.Is(err, apperror.NewNotFoundError("post")) works
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+
+	return ok && t.Kind == e.Kind && t.Code == e.Code
+}
+
+func New(kind Kind, code string, message string) *Error {
+	return &Error{
+		Kind:    kind,
+		Code:    code,
+		Message: message,
+	}
+}
+
+// WithParam sets the value of a placeholder in the translations of the error
+func (e *Error) WithParam(name string, value string) *Error {
+	if e.Params == nil {
+		e.Params = make(map[string]string)
+	}
+	e.Params[name] = value
+
+	return e
+}
+
+func NewBadRequestError(code string, message string) error {
+	return New(KIND_BAD_REQUEST, code, message)
+}
+
+func NewUnauthorizedError(code string, message string) error {
+	return New(KIND_UNAUTHORIZED, code, message)
+}
+
+// NewTokenError is the unauthorized error of a missing, invalid or expired token. Its message is
+// the code too, since clients decide from it whether to refresh the token or to log in again.
+func NewTokenError(code string) error {
+	return New(KIND_UNAUTHORIZED, code, code)
+}
+
+func NewForbiddenError(code string, message string) error {
+	return New(KIND_FORBIDDEN, code, message)
+}
+
+// NewNotFoundError returns the error of a missing entity, its code is like USER_NOT_FOUND
+func NewNotFoundError(entity string) error {
+	return New(KIND_NOT_FOUND, entityCode(entity)+"_NOT_FOUND", entity+" is not found").WithParam("entity", entity)
+}
+
+// NewConflictError returns the error of an entity that already exists, its code is like USER_ALREADY_EXISTS
+func NewConflictError(entity string) error {
+	return New(KIND_CONFLICT, entityCode(entity)+"_ALREADY_EXISTS", entity+" already exists").WithParam("entity", entity)
+}
+
+// NewRateLimitedError tells the client to retry after retryAfter
+func NewRateLimitedError(code string, message string, retryAfter time.Duration) error {
+	err := New(KIND_RATE_LIMITED, code, message)
+	err.RetryAfter = retryAfter
+
+	return err
+}
+
+// NewInternalError wraps an unexpected error. Clients only see a generic message.
+func NewInternalError(err error) error {
+	appErr := New(KIND_INTERNAL, CODE_INTERNAL, "internal server error")
+	appErr.Err = err
+
+	return appErr
+}
+
+// NewValidationError converts the errors of validator.Struct into a validation error,
+// any other error is returned unchanged
+func NewValidationError(err error) error {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return err
+	}
+
+	appErr := New(KIND_VALIDATION, CODE_VALIDATION_FAILED, "request is invalid")
+	appErr.Err = err
+	for _, fieldErr := range validationErrors {
+		appErr.Fields = append(appErr.Fields, FieldError{
+			Field: fieldErr.StructField(),
+			Path:  fieldPath(fieldErr.Namespace()),
+			Type:  fieldType(fieldErr.Kind()),
+			Tag:   fieldErr.Tag(),
+			Param: fieldErr.Param(),
+		})
+	}
+
+	return appErr
+}
+
+// From returns err as an *Error. Errors of another type become internal errors.
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	if validationErr := NewValidationError(err); validationErr != err {
+		return validationErr.(*Error)
+	}
+
+	return NewInternalError(err).(*Error)
+}
+
+// fieldPath drops the struct name from namespace, so LoginUserRequest.email becomes email.
+// Namespaces use JSON names once the validator has the tag name function of config.NewValidator.
+func fieldPath(namespace string) string {
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+
+	return namespace
+}
+
+func fieldType(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return "string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return "array"
+	default:
+		return ""
+	}
+}
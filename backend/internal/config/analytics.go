@@ -0,0 +1,15 @@
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// NewViewWindow returns the time within which views of the same visitor are counted once,
+// analytics.viewWindowMinutes falling back to 30 minutes
+func NewViewWindow(viper *viper.Viper) time.Duration {
+	viper.SetDefault("analytics.viewWindowMinutes", 30)
+
+	return time.Duration(viper.GetInt("analytics.viewWindowMinutes")) * time.Minute
+}
@@ -2,12 +2,16 @@ package config
 
 import (
 	"backend/db/migrate"
-	"backend/db/seeder"
 	"backend/internal/delivery/http"
 	"backend/internal/delivery/http/middleware"
 	"backend/internal/delivery/http/route"
+	"backend/internal/mail"
+	"backend/internal/moderation"
 	"backend/internal/repository"
+	"backend/internal/scheduler"
 	"backend/internal/usecase"
+	"backend/internal/utils"
+	"context"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
@@ -17,46 +21,101 @@ import (
 )
 
 type BootstrapConfig struct {
-	App      *echo.Echo
-	DB       *gorm.DB
-	Redis    *redis.Client
-	Validate *validator.Validate
-	Config   *viper.Viper
+	App         *echo.Echo
+	DB          *gorm.DB
+	Redis       *redis.Client
+	Validate    *validator.Validate
+	Config      *viper.Viper
+	KeyRing     *utils.KeyRing
+	Mailer      mail.Mailer
+	SpamChecker moderation.SpamChecker
+	Scheduler   *scheduler.Scheduler // Gets the background jobs, which run once the caller starts it
 }
 
 func Bootstrap(config *BootstrapConfig) {
 	// setup repositories
 	userRepository := repository.NewUserRepository()
 	postRepository := repository.NewPostRepository()
+	postSlugRepository := repository.NewPostSlugRepository()
+	postRevisionRepository := repository.NewPostRevisionRepository()
+	tagRepository := repository.NewTagRepository()
+	categoryRepository := repository.NewCategoryRepository()
+	commentRepository := repository.NewCommentRepository()
+	reactionRepository := repository.NewReactionRepository()
+	reactionCounterRepository := repository.NewReactionCounterRepository()
+	bookmarkRepository := repository.NewBookmarkRepository()
+	postStatRepository := repository.NewPostStatRepository()
+	postViewCounterRepository := repository.NewPostViewCounterRepository()
+	readingListRepository := repository.NewReadingListRepository()
+	sessionRepository := repository.NewSessionRepository()
+	recoveryCodeRepository := repository.NewRecoveryCodeRepository()
+
+	// setup limiters
+	loginLimiter := NewLoginLimiter(config.Config, config.Redis)
 
 	// setup usecases
-	postUseCase := usecase.NewPostUseCase(config.DB, config.Redis, config.Validate, postRepository, userRepository)
-	userUseCase := usecase.NewUserUseCase(config.DB, config.Redis, config.Validate, userRepository, config.Config)
+	postUseCase := usecase.NewPostUseCase(config.DB, config.Redis, config.Validate, postRepository, postSlugRepository,
+		postRevisionRepository, tagRepository, categoryRepository, userRepository, reactionRepository,
+		reactionCounterRepository, bookmarkRepository)
+	userUseCase := usecase.NewUserUseCase(config.DB, config.Redis, config.Validate, userRepository, sessionRepository,
+		recoveryCodeRepository, loginLimiter, config.Config, config.KeyRing, config.Mailer)
+	sessionUseCase := usecase.NewSessionUseCase(config.Redis, config.Validate, sessionRepository)
+	tagUseCase := usecase.NewTagUseCase(config.DB, config.Validate, tagRepository)
+	categoryUseCase := usecase.NewCategoryUseCase(config.DB, config.Validate, categoryRepository)
+	reactionUseCase := usecase.NewReactionUseCase(config.DB, config.Redis, config.Validate, postRepository,
+		reactionRepository, reactionCounterRepository)
+	bookmarkUseCase := usecase.NewBookmarkUseCase(config.DB, config.Validate, postRepository, bookmarkRepository,
+		readingListRepository)
+	postStatUseCase := usecase.NewPostStatUseCase(config.DB, config.Redis, config.Validate, postRepository,
+		postStatRepository, postViewCounterRepository, NewViewWindow(config.Config))
+	commentUseCase := usecase.NewCommentUseCase(config.DB, config.Validate, commentRepository, postRepository,
+		userRepository, config.SpamChecker)
+
+	// setup background jobs
+	if config.Scheduler != nil {
+		registerJobs(config.Config, config.Scheduler, postUseCase, reactionUseCase, postStatUseCase)
+	}
 
 	// setup controller
-	postController := http.NewPostController(postUseCase)
+	postController := http.NewPostController(postUseCase, postStatUseCase)
+	bookmarkController := http.NewBookmarkController(bookmarkUseCase, postUseCase)
 	userController := http.NewUserController(userUseCase)
+	sessionController := http.NewSessionController(sessionUseCase)
+	tagController := http.NewTagController(tagUseCase)
+	categoryController := http.NewCategoryController(categoryUseCase)
+	commentController := http.NewCommentController(commentUseCase)
+	reactionController := http.NewReactionController(reactionUseCase)
+	keyController := http.NewKeyController(config.KeyRing)
 
 	// setup middleware
-	authMiddleware := middleware.AuthMiddleware(config.Config, config.Redis)
+	authMiddleware := middleware.AuthMiddleware(config.KeyRing, config.Redis, sessionRepository)
+	optionalAuthMiddleware := middleware.OptionalAuth(config.KeyRing, config.Redis, sessionRepository)
 
 	// setup route
 	routeConfig := route.RouteConfig{
-		App:            config.App,
-		PostController: postController,
-		UserController: userController,
-		AuthMiddleware: authMiddleware,
+		App:                    config.App,
+		PostController:         postController,
+		UserController:         userController,
+		SessionController:      sessionController,
+		TagController:          tagController,
+		CategoryController:     categoryController,
+		CommentController:      commentController,
+		ReactionController:     reactionController,
+		BookmarkController:     bookmarkController,
+		KeyController:          keyController,
+		AuthMiddleware:         authMiddleware,
+		OptionalAuthMiddleware: optionalAuthMiddleware,
 	}
 	routeConfig.Setup()
 
-	// migrate the database
-	var err error
-	err = migrate.Drop(config.DB)
-	err = migrate.Migrate(config.DB)
-	users, err := seeder.SeedsUser(config.DB, userRepository)
-	_, err = seeder.SeedsPost(config.DB, postRepository, &users)
-
-	if err != nil {
-		panic(err)
+	// apply pending migrations only when explicitly enabled, otherwise use cmd/migrate
+	if config.Config.GetBool("database.migrateOnStart") {
+		migrator, err := migrate.NewMigrator(config.DB)
+		if err != nil {
+			panic(err)
+		}
+		if _, err := migrator.Up(context.Background()); err != nil {
+			panic(err)
+		}
 	}
 }
@@ -2,13 +2,37 @@ package config
 
 import (
 	"backend/internal/delivery/http/exception"
+	"fmt"
+	"net"
 
 	"github.com/labstack/echo/v4"
+	"github.com/spf13/viper"
 )
 
-func NewEcho() *echo.Echo {
+func NewEcho(viper *viper.Viper) *echo.Echo {
 	e := echo.New()
 	e.HTTPErrorHandler = exception.CustomErrorHandler
+	e.IPExtractor = newIPExtractor(viper.GetStringSlice("web.trustedProxies"))
 
 	return e
 }
+
+// newIPExtractor returns how the client IP of a request is found, used by the login limits and view counts.
+// Without trustedProxies, given as CIDRs, it is the peer address; with them, it is taken from X-Forwarded-For,
+// skipping only the addresses of those proxies. Forwarding headers from anyone else are never trusted.
+func newIPExtractor(trustedProxies []string) echo.IPExtractor {
+	if len(trustedProxies) == 0 {
+		return echo.ExtractIPDirect()
+	}
+
+	options := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, proxy := range trustedProxies {
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			panic(fmt.Errorf("web.trustedProxies: %w", err))
+		}
+		options = append(options, echo.TrustIPRange(ipNet))
+	}
+
+	return echo.ExtractIPFromXFFHeader(options...)
+}
@@ -29,8 +29,8 @@ func NewDatabase(viper *viper.Viper) *gorm.DB {
 		name,
 		port)
 
-	// Open connection
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	// Open connection, translating constraint violations into gorm errors like gorm.ErrDuplicatedKey
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
 
 	if err != nil {
 		panic(err)
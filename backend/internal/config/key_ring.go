@@ -0,0 +1,34 @@
+package config
+
+import (
+	"backend/internal/utils"
+
+	"github.com/spf13/viper"
+)
+
+// NewKeyRing returns the key ring signing access tokens. With auth.accessTokenKeyDir set, tokens are signed
+// with the RSA or Ed25519 keys of that directory, otherwise with the HS256 auth.accessTokenKey.
+func NewKeyRing(viper *viper.Viper) *utils.KeyRing {
+	keyDir := viper.GetString("auth.accessTokenKeyDir")
+	if keyDir == "" {
+		return utils.NewHMACKeyRing(viper.GetString("auth.accessTokenKey"))
+	}
+
+	keyRing, err := utils.NewFileKeyRing(keyDir, viper.GetString("auth.accessTokenActiveKeyID"))
+	if err != nil {
+		panic(err)
+	}
+
+	return keyRing
+}
+
+// ReloadKeyRing reads config.json again and reloads the keys of keyRing, activating the key
+// auth.accessTokenActiveKeyID names now. A key ring without a key directory is left as it is.
+func ReloadKeyRing(keyRing *utils.KeyRing) error {
+	viper, err := readConfig()
+	if err != nil {
+		return err
+	}
+
+	return keyRing.Reload(viper.GetString("auth.accessTokenActiveKeyID"))
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"backend/internal/limiter"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+)
+
+// NewLoginLimiter returns the login limiter configured by the auth.loginLimit keys, falling back to
+// 20 attempts per minute per IP, 10 attempts per minute per email and a 5 minute lockout after
+// 5 wrong passwords within 15 minutes, doubling up to a day for repeated lockouts.
+func NewLoginLimiter(viper *viper.Viper, redisClient *redis.Client) *limiter.LoginLimiter {
+	viper.SetDefault("auth.loginLimit.ipLimit", 20)
+	viper.SetDefault("auth.loginLimit.ipWindowSeconds", 60)
+	viper.SetDefault("auth.loginLimit.emailLimit", 10)
+	viper.SetDefault("auth.loginLimit.emailWindowSeconds", 60)
+	viper.SetDefault("auth.loginLimit.lockoutThreshold", 5)
+	viper.SetDefault("auth.loginLimit.failureWindowMinutes", 15)
+	viper.SetDefault("auth.loginLimit.lockoutMinutes", 5)
+	viper.SetDefault("auth.loginLimit.maxLockoutMinutes", 24*60)
+
+	return limiter.NewLoginLimiter(
+		limiter.NewSlidingWindow(redisClient, "LOGIN_IP",
+			viper.GetInt("auth.loginLimit.ipLimit"),
+			time.Duration(viper.GetInt("auth.loginLimit.ipWindowSeconds"))*time.Second),
+		limiter.NewSlidingWindow(redisClient, "LOGIN_EMAIL",
+			viper.GetInt("auth.loginLimit.emailLimit"),
+			time.Duration(viper.GetInt("auth.loginLimit.emailWindowSeconds"))*time.Second),
+		limiter.NewLockout(redisClient, "LOGIN",
+			viper.GetInt("auth.loginLimit.lockoutThreshold"),
+			time.Duration(viper.GetInt("auth.loginLimit.failureWindowMinutes"))*time.Minute,
+			time.Duration(viper.GetInt("auth.loginLimit.lockoutMinutes"))*time.Minute,
+			time.Duration(viper.GetInt("auth.loginLimit.maxLockoutMinutes"))*time.Minute),
+	)
+}
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"backend/internal/mail"
+
+	"github.com/spf13/viper"
+)
+
+// NewMailer returns an SMTP mailer when mail.driver is "smtp", otherwise a mailer writing emails to mail.file.dir
+func NewMailer(viper *viper.Viper) mail.Mailer {
+	from := viper.GetString("mail.from")
+
+	if viper.GetString("mail.driver") == "smtp" {
+		return mail.NewSMTPMailer(
+			viper.GetString("mail.smtp.host"),
+			viper.GetInt("mail.smtp.port"),
+			viper.GetString("mail.smtp.username"),
+			viper.GetString("mail.smtp.password"),
+			from,
+		)
+	}
+
+	dir := viper.GetString("mail.file.dir")
+	if dir == "" {
+		dir = "mails"
+	}
+
+	return mail.NewFileMailer(dir, from)
+}
@@ -1,7 +1,6 @@
 package config
 
 import (
-	"context"
 	"fmt"
 
 	"github.com/redis/go-redis/v9"
@@ -21,8 +20,5 @@ func NewRedisClient(viper *viper.Viper) *redis.Client {
 		DB:       db,
 		Password: password,
 	})
-	// Delete all redis value
-	client.FlushDB(context.Background())
-
 	return client
 }
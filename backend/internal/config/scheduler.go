@@ -0,0 +1,48 @@
+package config
+
+import (
+	"backend/internal/constant"
+	"backend/internal/scheduler"
+	"backend/internal/usecase"
+	"context"
+	"log"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func NewScheduler() *scheduler.Scheduler {
+	return scheduler.NewScheduler(log.Default())
+}
+
+// registerJobs adds the background jobs to jobs. Every job runs at the interval of its
+// scheduler.<job>Seconds key, publishing scheduled posts every 30 seconds and purging
+// posts that have been in the trash for post.trashRetentionDays (30) every hour, and reconciling
+// the reaction counts and flushing the post views kept in Redis every minute by default.
+func registerJobs(viper *viper.Viper, jobs *scheduler.Scheduler, postUseCase *usecase.PostUseCase,
+	reactionUseCase *usecase.ReactionUseCase, postStatUseCase *usecase.PostStatUseCase) {
+	viper.SetDefault("scheduler.publishScheduledPostsSeconds", 30)
+	viper.SetDefault("scheduler.purgeTrashedPostsSeconds", 60*60)
+	viper.SetDefault("scheduler.reconcilePostReactionsSeconds", 60)
+	viper.SetDefault("scheduler.flushPostStatsSeconds", 60)
+	viper.SetDefault("post.trashRetentionDays", 30)
+
+	jobs.Every(constant.JOB_PUBLISH_SCHEDULED_POSTS,
+		time.Duration(viper.GetInt("scheduler.publishScheduledPostsSeconds"))*time.Second,
+		postUseCase.PublishScheduled)
+
+	jobs.Every(constant.JOB_PURGE_TRASHED_POSTS,
+		time.Duration(viper.GetInt("scheduler.purgeTrashedPostsSeconds"))*time.Second,
+		func(ctx context.Context) error {
+			retention := time.Duration(viper.GetInt("post.trashRetentionDays")) * 24 * time.Hour
+			return postUseCase.PurgeTrash(ctx, retention)
+		})
+
+	jobs.Every(constant.JOB_RECONCILE_POST_REACTIONS,
+		time.Duration(viper.GetInt("scheduler.reconcilePostReactionsSeconds"))*time.Second,
+		reactionUseCase.Reconcile)
+
+	jobs.Every(constant.JOB_FLUSH_POST_STATS,
+		time.Duration(viper.GetInt("scheduler.flushPostStatsSeconds"))*time.Second,
+		postStatUseCase.Flush)
+}
@@ -0,0 +1,27 @@
+package config
+
+import (
+	"backend/internal/moderation"
+
+	"github.com/spf13/viper"
+)
+
+// NewSpamChecker returns an Akismet checker when spam.driver is "akismet", otherwise the built in heuristics
+func NewSpamChecker(viper *viper.Viper) moderation.SpamChecker {
+	if viper.GetString("spam.driver") == "akismet" {
+		viper.SetDefault("spam.akismet.endpoint", "https://rest.akismet.com")
+
+		return moderation.NewAkismetChecker(
+			viper.GetString("spam.akismet.endpoint"),
+			viper.GetString("spam.akismet.apiKey"),
+			viper.GetString("spam.akismet.blog"),
+		)
+	}
+
+	viper.SetDefault("spam.heuristic.maxLinks", 2)
+
+	return moderation.NewHeuristicChecker(
+		viper.GetInt("spam.heuristic.maxLinks"),
+		viper.GetStringSlice("spam.heuristic.blocklist"),
+	)
+}
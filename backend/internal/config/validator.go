@@ -0,0 +1,25 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// NewValidator returns a validator naming fields by their JSON name, so validation
+// errors point at the request fields clients actually send
+func NewValidator() *validator.Validate {
+	validate := validator.New()
+
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "" || name == "-" {
+			return field.Name
+		}
+
+		return name
+	})
+
+	return validate
+}
@@ -8,6 +8,16 @@ import (
 
 // NewViper
 func NewViper() *viper.Viper {
+	config, err := readConfig()
+	if err != nil {
+		panic(fmt.Errorf("Fatal error config file: %w \n", err))
+	}
+
+	return config
+}
+
+// readConfig reads config.json from the working directory or its parent
+func readConfig() (*viper.Viper, error) {
 	config := viper.New()
 
 	config.SetConfigName("config")
@@ -15,10 +25,9 @@ func NewViper() *viper.Viper {
 	config.AddConfigPath("./")    // Define config.json path to working directory
 	config.AddConfigPath("./../") // Define config.json path to working directory
 
-	err := config.ReadInConfig()
-	if err != nil {
-		panic(fmt.Errorf("Fatal error config file: %w \n", err))
+	if err := config.ReadInConfig(); err != nil {
+		return nil, err
 	}
 
-	return config
+	return config, nil
 }
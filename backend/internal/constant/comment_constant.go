@@ -0,0 +1,7 @@
+package constant
+
+const (
+	COMMENT_STATUS_PENDING  = "pending" // Held in the moderation queue, only visible to moderators
+	COMMENT_STATUS_APPROVED = "approved"
+	COMMENT_STATUS_REJECTED = "rejected"
+)
@@ -0,0 +1,31 @@
+package constant
+
+const (
+	POST_STATUS_DRAFT     = "draft"
+	POST_STATUS_SCHEDULED = "scheduled" // Published by the scheduler once published_at has passed
+	POST_STATUS_PUBLISHED = "published"
+	POST_STATUS_ARCHIVED  = "archived" // Hidden from guests, but kept for its author
+)
+
+var POST_STATUSES = []string{POST_STATUS_DRAFT, POST_STATUS_SCHEDULED, POST_STATUS_PUBLISHED, POST_STATUS_ARCHIVED}
+
+const (
+	TAG_MATCH_ANY = "any"
+	TAG_MATCH_ALL = "all"
+)
+
+// Keys of the Postgres advisory locks taken by background jobs, so only one replica runs a job at a time
+const (
+	LOCK_PUBLISH_SCHEDULED_POSTS int64 = 1001
+	LOCK_POST_SLUG               int64 = 1002 // Taken with the hash of the slug as second key
+	LOCK_PURGE_TRASHED_POSTS     int64 = 1003
+	LOCK_CATEGORY_SLUG           int64 = 1004 // Taken with the hash of the slug as second key
+	LOCK_CATEGORY_TREE           int64 = 1005 // Held while a category is created, moved or deleted
+)
+
+const (
+	JOB_PUBLISH_SCHEDULED_POSTS  = "publish-scheduled-posts"
+	JOB_PURGE_TRASHED_POSTS      = "purge-trashed-posts"
+	JOB_RECONCILE_POST_REACTIONS = "reconcile-post-reactions"
+	JOB_FLUSH_POST_STATS         = "flush-post-stats"
+)
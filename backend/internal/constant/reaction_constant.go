@@ -0,0 +1,13 @@
+package constant
+
+const (
+	REACTION_LIKE  = "like"
+	REACTION_HEART = "heart"
+	REACTION_LAUGH = "laugh"
+	REACTION_WOW   = "wow"
+	REACTION_SAD   = "sad"
+	REACTION_CLAP  = "clap"
+)
+
+// REACTION_KINDS are the reactions readers can leave on a post, each at most once
+var REACTION_KINDS = []string{REACTION_LIKE, REACTION_HEART, REACTION_LAUGH, REACTION_WOW, REACTION_SAD, REACTION_CLAP}
@@ -0,0 +1,29 @@
+package constant
+
+const (
+	ROLE_READER = "reader"
+	ROLE_AUTHOR = "author"
+	ROLE_EDITOR = "editor"
+	ROLE_ADMIN  = "admin"
+)
+
+const (
+	PERMISSION_POST_CREATE   = "post:create"
+	PERMISSION_POST_EDIT_OWN = "post:edit:own" // Update or delete posts written by the current user
+	PERMISSION_POST_EDIT_ANY = "post:edit:any" // Update or delete posts written by anyone
+	PERMISSION_USER_MANAGE   = "user:manage"   // List users and assign roles
+
+	PERMISSION_CATEGORY_MANAGE  = "category:manage"  // Create, rename, move and delete categories
+	PERMISSION_COMMENT_MODERATE = "comment:moderate" // Delete any comment, work the moderation queue and trust commenters
+)
+
+var ROLES = []string{ROLE_READER, ROLE_AUTHOR, ROLE_EDITOR, ROLE_ADMIN}
+
+var ROLE_PERMISSIONS = map[string][]string{
+	ROLE_READER: {},
+	ROLE_AUTHOR: {PERMISSION_POST_CREATE, PERMISSION_POST_EDIT_OWN},
+	ROLE_EDITOR: {PERMISSION_POST_CREATE, PERMISSION_POST_EDIT_OWN, PERMISSION_POST_EDIT_ANY, PERMISSION_CATEGORY_MANAGE,
+		PERMISSION_COMMENT_MODERATE},
+	ROLE_ADMIN: {PERMISSION_POST_CREATE, PERMISSION_POST_EDIT_OWN, PERMISSION_POST_EDIT_ANY, PERMISSION_CATEGORY_MANAGE,
+		PERMISSION_COMMENT_MODERATE, PERMISSION_USER_MANAGE},
+}
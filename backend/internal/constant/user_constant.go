@@ -2,3 +2,6 @@ package constant
 
 const REFRESH_TOKEN_COOKIE_NAME = "RefreshToken"
 const USER_AUTH_DATA_CONTEXT_NAME = "userAuthData"
+
+const RECOVERY_CODE_COUNT = 10
+const MFA_MAX_ATTEMPTS = 5 // Wrong codes allowed for one challenge before the password must be entered again
@@ -0,0 +1,268 @@
+package http
+
+import (
+	"backend/internal/constant"
+	"backend/internal/delivery/http/middleware"
+	"backend/internal/model"
+	"backend/internal/usecase"
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type BookmarkController struct {
+	BookmarkUseCase *usecase.BookmarkUseCase
+	PostUseCase     *usecase.PostUseCase
+}
+
+func NewBookmarkController(bookmarkUseCase *usecase.BookmarkUseCase, postUseCase *usecase.PostUseCase) *BookmarkController {
+	return &BookmarkController{
+		BookmarkUseCase: bookmarkUseCase,
+		PostUseCase:     postUseCase,
+	}
+}
+
+// GetAll lists the published posts the current user bookmarked, the last bookmarked first
+func (ct *BookmarkController) GetAll(c echo.Context) error {
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	pageSize, _ := strconv.Atoi(c.QueryParam("pageSize"))
+
+	currentUser, err := middleware.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	request := model.PostListRequest{
+		Page:         page,
+		PageSize:     pageSize,
+		Status:       constant.POST_STATUS_PUBLISHED,
+		BookmarkedBy: currentUser.ID,
+		ViewerID:     currentUser.ID,
+	}
+	posts, err := ct.PostUseCase.List(c.Request().Context(), &request)
+	if err != nil {
+		return err
+	}
+
+	response := model.DataResponse[[]model.PostResponse]{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   posts,
+	}
+	return c.JSON(response.Code, response)
+}
+
+func (ct *BookmarkController) Save(c echo.Context) error {
+	return ct.bookmark(c, ct.BookmarkUseCase.Save)
+}
+
+func (ct *BookmarkController) Unsave(c echo.Context) error {
+	return ct.bookmark(c, ct.BookmarkUseCase.Unsave)
+}
+
+func (ct *BookmarkController) bookmark(c echo.Context, action func(context.Context, *model.BookmarkRequest) error) error {
+	postID, _ := strconv.Atoi(c.Param("postId"))
+
+	currentUser, err := middleware.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	request := model.BookmarkRequest{
+		PostID: uint64(postID),
+		UserID: currentUser.ID,
+	}
+	if err := action(c.Request().Context(), &request); err != nil {
+		return err
+	}
+
+	response := model.DataResponse[any]{
+		Code:   http.StatusOK,
+		Status: "OK",
+	}
+	return c.JSON(response.Code, response)
+}
+
+func (ct *BookmarkController) GetReadingLists(c echo.Context) error {
+	currentUser, err := middleware.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	readingLists, err := ct.BookmarkUseCase.ListReadingLists(c.Request().Context(), currentUser.ID)
+	if err != nil {
+		return err
+	}
+
+	response := model.DataResponse[[]model.ReadingListResponse]{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   readingLists,
+	}
+	return c.JSON(response.Code, response)
+}
+
+func (ct *BookmarkController) GetReadingList(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	currentUser, err := middleware.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	request := model.ReadingListGetRequest{
+		ID:     uint64(id),
+		UserID: currentUser.ID,
+	}
+	readingList, err := ct.BookmarkUseCase.GetReadingList(c.Request().Context(), &request)
+	if err != nil {
+		return err
+	}
+
+	return ct.readingListResponse(c, readingList)
+}
+
+func (ct *BookmarkController) CreateReadingList(c echo.Context) error {
+	currentUser, err := middleware.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	request := new(model.ReadingListCreateRequest)
+	if err := c.Bind(request); err != nil {
+		return err
+	}
+	request.UserID = currentUser.ID
+
+	readingList, err := ct.BookmarkUseCase.CreateReadingList(c.Request().Context(), request)
+	if err != nil {
+		return err
+	}
+
+	return ct.readingListResponse(c, readingList)
+}
+
+func (ct *BookmarkController) UpdateReadingList(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	currentUser, err := middleware.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	request := new(model.ReadingListUpdateRequest)
+	if err := c.Bind(request); err != nil {
+		return err
+	}
+	request.ID = uint64(id)
+	request.UserID = currentUser.ID
+
+	readingList, err := ct.BookmarkUseCase.UpdateReadingList(c.Request().Context(), request)
+	if err != nil {
+		return err
+	}
+
+	return ct.readingListResponse(c, readingList)
+}
+
+func (ct *BookmarkController) DeleteReadingList(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	currentUser, err := middleware.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	request := model.ReadingListGetRequest{
+		ID:     uint64(id),
+		UserID: currentUser.ID,
+	}
+	if err := ct.BookmarkUseCase.DeleteReadingList(c.Request().Context(), &request); err != nil {
+		return err
+	}
+
+	response := model.DataResponse[any]{
+		Code:   http.StatusOK,
+		Status: "OK",
+	}
+	return c.JSON(response.Code, response)
+}
+
+func (ct *BookmarkController) AddToReadingList(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	currentUser, err := middleware.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	request := new(model.ReadingListItemAddRequest)
+	if err := c.Bind(request); err != nil {
+		return err
+	}
+	request.ReadingListID = uint64(id)
+	request.UserID = currentUser.ID
+
+	readingList, err := ct.BookmarkUseCase.AddToReadingList(c.Request().Context(), request)
+	if err != nil {
+		return err
+	}
+
+	return ct.readingListResponse(c, readingList)
+}
+
+func (ct *BookmarkController) RemoveFromReadingList(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	postID, _ := strconv.Atoi(c.Param("postId"))
+
+	currentUser, err := middleware.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	request := model.ReadingListItemRemoveRequest{
+		ReadingListID: uint64(id),
+		PostID:        uint64(postID),
+		UserID:        currentUser.ID,
+	}
+	readingList, err := ct.BookmarkUseCase.RemoveFromReadingList(c.Request().Context(), &request)
+	if err != nil {
+		return err
+	}
+
+	return ct.readingListResponse(c, readingList)
+}
+
+func (ct *BookmarkController) ReorderReadingList(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	currentUser, err := middleware.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	request := new(model.ReadingListReorderRequest)
+	if err := c.Bind(request); err != nil {
+		return err
+	}
+	request.ReadingListID = uint64(id)
+	request.UserID = currentUser.ID
+
+	readingList, err := ct.BookmarkUseCase.ReorderReadingList(c.Request().Context(), request)
+	if err != nil {
+		return err
+	}
+
+	return ct.readingListResponse(c, readingList)
+}
+
+func (ct *BookmarkController) readingListResponse(c echo.Context, readingList *model.ReadingListResponse) error {
+	response := model.DataResponse[*model.ReadingListResponse]{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   readingList,
+	}
+	return c.JSON(response.Code, response)
+}
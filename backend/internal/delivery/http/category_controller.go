@@ -0,0 +1,111 @@
+package http
+
+import (
+	"backend/internal/model"
+	"backend/internal/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type CategoryController struct {
+	CategoryUseCase *usecase.CategoryUseCase
+}
+
+func NewCategoryController(categoryUseCase *usecase.CategoryUseCase) *CategoryController {
+	return &CategoryController{
+		CategoryUseCase: categoryUseCase,
+	}
+}
+
+// GetTree lists the top level categories with their subcategories nested under them
+func (ct *CategoryController) GetTree(c echo.Context) error {
+	categories, err := ct.CategoryUseCase.Tree(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	response := model.DataResponse[[]*model.CategoryResponse]{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   categories,
+	}
+	return c.JSON(response.Code, response)
+}
+
+// GetBySlug returns a category for its category page, the posts of the page come from the category filter of the post list
+func (ct *CategoryController) GetBySlug(c echo.Context) error {
+	request := model.CategoryGetBySlugRequest{
+		Slug: c.Param("slug"),
+	}
+	category, err := ct.CategoryUseCase.GetBySlug(c.Request().Context(), &request)
+	if err != nil {
+		return err
+	}
+
+	response := model.DataResponse[*model.CategoryResponse]{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   category,
+	}
+	return c.JSON(response.Code, response)
+}
+
+func (ct *CategoryController) Create(c echo.Context) error {
+	request := new(model.CategoryCreateRequest)
+	if err := c.Bind(request); err != nil {
+		return err
+	}
+
+	category, err := ct.CategoryUseCase.Create(c.Request().Context(), request)
+	if err != nil {
+		return err
+	}
+
+	response := model.DataResponse[*model.CategoryResponse]{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   category,
+	}
+	return c.JSON(response.Code, response)
+}
+
+func (ct *CategoryController) Update(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	request := new(model.CategoryUpdateRequest)
+	if err := c.Bind(request); err != nil {
+		return err
+	}
+	request.ID = uint64(id)
+
+	category, err := ct.CategoryUseCase.Update(c.Request().Context(), request)
+	if err != nil {
+		return err
+	}
+
+	response := model.DataResponse[*model.CategoryResponse]{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   category,
+	}
+	return c.JSON(response.Code, response)
+}
+
+func (ct *CategoryController) Delete(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	request := model.CategoryDeleteRequest{
+		ID: uint64(id),
+	}
+	if err := ct.CategoryUseCase.Delete(c.Request().Context(), &request); err != nil {
+		return err
+	}
+
+	response := model.DataResponse[any]{
+		Code:   http.StatusOK,
+		Status: "OK",
+	}
+	return c.JSON(response.Code, response)
+}
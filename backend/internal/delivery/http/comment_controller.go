@@ -0,0 +1,181 @@
+package http
+
+import (
+	"backend/internal/constant"
+	"backend/internal/delivery/http/middleware"
+	"backend/internal/model"
+	"backend/internal/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type CommentController struct {
+	CommentUseCase *usecase.CommentUseCase
+}
+
+func NewCommentController(commentUseCase *usecase.CommentUseCase) *CommentController {
+	return &CommentController{
+		CommentUseCase: commentUseCase,
+	}
+}
+
+// GetAll lists the comment threads of a post, page and pageSize page through the top level comments
+func (ct *CommentController) GetAll(c echo.Context) error {
+	postID, _ := strconv.Atoi(c.Param("id"))
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	pageSize, _ := strconv.Atoi(c.QueryParam("pageSize"))
+
+	request := model.CommentListRequest{
+		PostID:   uint64(postID),
+		Page:     page,
+		PageSize: pageSize,
+	}
+	comments, err := ct.CommentUseCase.List(c.Request().Context(), &request)
+	if err != nil {
+		return err
+	}
+
+	response := model.DataResponse[[]*model.CommentResponse]{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   comments,
+	}
+	return c.JSON(response.Code, response)
+}
+
+func (ct *CommentController) Create(c echo.Context) error {
+	postID, _ := strconv.Atoi(c.Param("id"))
+
+	currentUser, err := middleware.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	request := new(model.CommentCreateRequest)
+	if err := c.Bind(request); err != nil {
+		return err
+	}
+	request.PostID = uint64(postID)
+	request.UserID = currentUser.ID
+	request.IPAddress = c.RealIP()
+	request.UserAgent = c.Request().UserAgent()
+
+	comment, err := ct.CommentUseCase.Create(c.Request().Context(), request)
+	if err != nil {
+		return err
+	}
+
+	response := model.DataResponse[*model.CommentResponse]{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   comment,
+	}
+	return c.JSON(response.Code, response)
+}
+
+func (ct *CommentController) Update(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	currentUser, err := middleware.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	request := new(model.CommentUpdateRequest)
+	if err := c.Bind(request); err != nil {
+		return err
+	}
+	request.ID = uint64(id)
+	request.UserID = currentUser.ID
+	request.IPAddress = c.RealIP()
+	request.UserAgent = c.Request().UserAgent()
+
+	comment, err := ct.CommentUseCase.Update(c.Request().Context(), request)
+	if err != nil {
+		return err
+	}
+
+	response := model.DataResponse[*model.CommentResponse]{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   comment,
+	}
+	return c.JSON(response.Code, response)
+}
+
+func (ct *CommentController) Delete(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	currentUser, err := middleware.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	request := model.CommentDeleteRequest{
+		ID:       uint64(id),
+		UserID:   currentUser.ID,
+		UserRole: currentUser.Role,
+	}
+	if err := ct.CommentUseCase.Delete(c.Request().Context(), &request); err != nil {
+		return err
+	}
+
+	response := model.DataResponse[any]{
+		Code:   http.StatusOK,
+		Status: "OK",
+	}
+	return c.JSON(response.Code, response)
+}
+
+// GetQueue lists the comments held for moderation, or the comments in the status query parameter
+func (ct *CommentController) GetQueue(c echo.Context) error {
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	pageSize, _ := strconv.Atoi(c.QueryParam("pageSize"))
+
+	request := model.CommentQueueRequest{
+		Status:   c.QueryParam("status"),
+		Page:     page,
+		PageSize: pageSize,
+	}
+	comments, err := ct.CommentUseCase.Queue(c.Request().Context(), &request)
+	if err != nil {
+		return err
+	}
+
+	response := model.DataResponse[[]model.CommentQueueResponse]{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   comments,
+	}
+	return c.JSON(response.Code, response)
+}
+
+func (ct *CommentController) Approve(c echo.Context) error {
+	return ct.moderate(c, constant.COMMENT_STATUS_APPROVED)
+}
+
+func (ct *CommentController) Reject(c echo.Context) error {
+	return ct.moderate(c, constant.COMMENT_STATUS_REJECTED)
+}
+
+func (ct *CommentController) moderate(c echo.Context, status string) error {
+	request := new(model.CommentModerateRequest)
+	if err := c.Bind(request); err != nil {
+		return err
+	}
+	request.Status = status
+
+	result, err := ct.CommentUseCase.Moderate(c.Request().Context(), request)
+	if err != nil {
+		return err
+	}
+
+	response := model.DataResponse[*model.CommentModerateResponse]{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   result,
+	}
+	return c.JSON(response.Code, response)
+}
@@ -1,51 +1,186 @@
 package exception
 
 import (
-	"backend/internal/model"
+	"backend/internal/apperror"
+	"backend/internal/delivery/http/middleware"
+	"backend/internal/i18n"
 	"errors"
+	"fmt"
+	"log"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 
-	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
 )
 
+// kindStatuses maps the kind of a usecase error to its response status
+var kindStatuses = map[apperror.Kind]int{
+	apperror.KIND_INTERNAL:     http.StatusInternalServerError,
+	apperror.KIND_BAD_REQUEST:  http.StatusBadRequest,
+	apperror.KIND_VALIDATION:   http.StatusBadRequest,
+	apperror.KIND_UNAUTHORIZED: http.StatusUnauthorized,
+	apperror.KIND_FORBIDDEN:    http.StatusForbidden,
+	apperror.KIND_NOT_FOUND:    http.StatusNotFound,
+	apperror.KIND_CONFLICT:     http.StatusConflict,
+	apperror.KIND_RATE_LIMITED: http.StatusTooManyRequests,
+}
+
+// kindFallbackCodes are translated when the code of an error has no translation of its own,
+// so every entity doesn't need its own NOT_FOUND message
+var kindFallbackCodes = map[apperror.Kind]string{
+	apperror.KIND_NOT_FOUND: apperror.CODE_ENTITY_NOT_FOUND,
+	apperror.KIND_CONFLICT:  apperror.CODE_ENTITY_ALREADY_EXISTS,
+}
+
+// ErrorDetail is what a response says about an error, whatever its format
+type ErrorDetail struct {
+	Status       int
+	Code         string
+	FallbackCode string
+	Message      string
+	Params       map[string]string
+	Fields       []FieldDetail
+	RetryAfter   int // Seconds, zero when the client can retry right away
+}
+
+type FieldDetail struct {
+	apperror.FieldError
+	Message string
+}
+
+// CustomErrorHandler responds with an RFC 7807 problem when the client accepts application/problem+json,
+// otherwise with a MessagesResponse. Messages are in the locale of the user or of the Accept-Language header.
 func CustomErrorHandler(err error, c echo.Context) {
-	var response model.MessagesResponse
-
-	if errors.Is(err, echo.ErrNotFound) {
-		response = GetNotFoundErrorResponse(err)
-	} else if errors.Is(err, echo.ErrBadRequest) {
-		response = GetBadRequestErrorResponse(err)
-	} else if errConv, ok := err.(validator.ValidationErrors); ok {
-		response = GetValidationErrorResponse(errConv)
-	} else if errors.Is(err, echo.ErrUnauthorized) {
-		response = GetUnauthorizedErrorResponse(err)
-	} else if errors.Is(err, echo.ErrForbidden) {
-		response = GetForbiddenErrorResponse(err)
-	} else if errors.Is(err, echo.ErrConflict) {
-		response = GetConflictErrorResponse(err)
-	} else if errors.Is(err, echo.ErrInternalServerError) {
-		response = GetInternalServerError(err)
-	} else {
-		if he, ok := err.(*echo.HTTPError); ok {
-			switch {
-			case he.Code == http.StatusBadRequest:
-				response = GetBadRequestErrorResponse(he)
-			case he.Code == http.StatusUnauthorized:
-				response = GetUnauthorizedErrorResponse(he)
-			case he.Code == http.StatusForbidden:
-				response = GetForbiddenErrorResponse(he)
-			case he.Code == http.StatusBadRequest:
-				response = GetBadRequestErrorResponse(he)
-			case he.Code == http.StatusNotFound:
-				response = GetNotFoundErrorResponse(he)
-			case he.Code == http.StatusInternalServerError:
-				response = GetInternalServerError(he)
-			}
-		} else {
-			response = GetInternalServerError(err)
+	if c.Response().Committed {
+		return
+	}
+
+	detail := GetErrorDetail(err)
+	if detail.Status == http.StatusInternalServerError {
+		log.Println("internal server error:", err)
+	}
+
+	locale := GetRequestLocale(c)
+	LocalizeErrorDetail(&detail, locale)
+	c.Response().Header().Set("Content-Language", locale)
+
+	if detail.RetryAfter > 0 {
+		c.Response().Header().Set(echo.HeaderRetryAfter, strconv.Itoa(detail.RetryAfter))
+	}
+
+	if AcceptsProblem(c.Request()) {
+		c.Response().Header().Set(echo.HeaderContentType, MIMEApplicationProblemJSON)
+		c.JSON(detail.Status, GetProblemResponse(detail, c.Request().URL.Path))
+		return
+	}
+
+	c.JSON(detail.Status, GetMessagesResponse(detail))
+}
+
+func GetErrorDetail(err error) ErrorDetail {
+	// Errors of echo itself, like unknown routes or malformed request bodies, keep their status
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		code := strings.ReplaceAll(strings.ToUpper(http.StatusText(httpErr.Code)), " ", "_")
+		if httpErr.Code == http.StatusBadRequest {
+			code = apperror.CODE_BAD_REQUEST
+		}
+
+		return ErrorDetail{
+			Status:  httpErr.Code,
+			Code:    code,
+			Message: fmt.Sprint(httpErr.Message),
 		}
 	}
 
-	c.JSON(response.Code, response)
+	appErr := apperror.From(err)
+
+	status, ok := kindStatuses[appErr.Kind]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+
+	detail := ErrorDetail{
+		Status:       status,
+		Code:         appErr.Code,
+		FallbackCode: kindFallbackCodes[appErr.Kind],
+		Message:      appErr.Message,
+		Params:       make(map[string]string, len(appErr.Params)+1),
+		RetryAfter:   int(math.Ceil(appErr.RetryAfter.Seconds())),
+	}
+	for name, value := range appErr.Params {
+		detail.Params[name] = value
+	}
+	if detail.RetryAfter > 0 {
+		detail.Params["seconds"] = strconv.Itoa(detail.RetryAfter)
+	}
+	for _, field := range appErr.Fields {
+		detail.Fields = append(detail.Fields, FieldDetail{FieldError: field})
+	}
+
+	return detail
+}
+
+// GetRequestLocale returns the locale the current user chose, otherwise the best match of the Accept-Language header
+func GetRequestLocale(c echo.Context) string {
+	var tags []string
+	if currentUser := middleware.OptionalCurrentUser(c); currentUser != nil && currentUser.Locale != "" {
+		tags = append(tags, currentUser.Locale)
+	}
+	tags = append(tags, i18n.ParseAcceptLanguage(c.Request().Header.Get("Accept-Language"))...)
+
+	return i18n.Default.Match(tags...)
+}
+
+// LocalizeErrorDetail translates the messages of detail. Messages without translation are left as they are.
+func LocalizeErrorDetail(detail *ErrorDetail, locale string) {
+	// Parameters like the entity of a NOT_FOUND error are translated too
+	params := make(map[string]string, len(detail.Params))
+	for name, value := range detail.Params {
+		if translated, ok := i18n.Default.Translate(locale, name+"."+value, nil); ok {
+			value = translated
+		}
+		params[name] = value
+	}
+
+	if message, ok := i18n.Default.Translate(locale, "error."+detail.Code, params); ok {
+		detail.Message = message
+	} else if detail.FallbackCode != "" {
+		if message, ok := i18n.Default.Translate(locale, "error."+detail.FallbackCode, params); ok {
+			detail.Message = message
+		}
+	}
+
+	for i := range detail.Fields {
+		detail.Fields[i].Message = GetValidationMessage(detail.Fields[i].FieldError, locale)
+	}
+}
+
+// GetValidationMessage describes a field failing a validation rule, trying the translation for the type
+// of the field first, like validation.min.string
+func GetValidationMessage(field apperror.FieldError, locale string) string {
+	params := map[string]string{
+		"field": field.Path,
+		"param": field.Param,
+	}
+
+	keys := []string{"validation." + field.Tag, "validation.default"}
+	if field.Type != "" {
+		keys = append([]string{"validation." + field.Tag + "." + field.Type}, keys...)
+	}
+
+	for _, key := range keys {
+		if message, ok := i18n.Default.Translate(locale, key, params); ok {
+			return message
+		}
+	}
+
+	return field.Path + " is invalid"
+}
+
+// statusName returns the status of the response body, like NOT FOUND
+func statusName(status int) string {
+	return strings.ToUpper(http.StatusText(status))
 }
@@ -0,0 +1,22 @@
+package exception
+
+import "backend/internal/model"
+
+// GetMessagesResponse returns the response the React frontend understands
+func GetMessagesResponse(detail ErrorDetail) model.MessagesResponse {
+	response := model.MessagesResponse{
+		Code:      detail.Status,
+		Status:    statusName(detail.Status),
+		ErrorCode: detail.Code,
+		Messages:  []string{detail.Message},
+	}
+
+	if len(detail.Fields) > 0 {
+		response.Messages = make([]string, 0, len(detail.Fields))
+		for _, field := range detail.Fields {
+			response.Messages = append(response.Messages, field.Message)
+		}
+	}
+
+	return response
+}
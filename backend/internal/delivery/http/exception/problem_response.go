@@ -0,0 +1,57 @@
+package exception
+
+import (
+	"backend/internal/model"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const MIMEApplicationProblemJSON = "application/problem+json"
+
+// PROBLEM_TYPE_BASE prefixes the code of an error to make its problem type. The type only
+// has to identify the problem, so it is a relative reference that isn't served.
+const PROBLEM_TYPE_BASE = "/problems/"
+
+// AcceptsProblem tells if the Accept header of request lists application/problem+json,
+// clients that don't ask for it keep getting a MessagesResponse
+func AcceptsProblem(request *http.Request) bool {
+	for _, accepted := range strings.Split(request.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil || mediaType != MIMEApplicationProblemJSON {
+			continue
+		}
+
+		if q, err := strconv.ParseFloat(params["q"], 64); err == nil && q == 0 {
+			return false
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// GetProblemResponse returns the RFC 7807 problem of an error that happened on the request to instance
+func GetProblemResponse(detail ErrorDetail, instance string) model.ProblemResponse {
+	response := model.ProblemResponse{
+		Type:     PROBLEM_TYPE_BASE + strings.ToLower(strings.ReplaceAll(detail.Code, "_", "-")),
+		Title:    http.StatusText(detail.Status),
+		Status:   detail.Status,
+		Detail:   detail.Message,
+		Instance: instance,
+		Code:     detail.Code,
+	}
+
+	for _, field := range detail.Fields {
+		response.Errors = append(response.Errors, model.ProblemFieldError{
+			Field:  field.Path,
+			Rule:   field.Tag,
+			Param:  field.Param,
+			Detail: field.Message,
+		})
+	}
+
+	return response
+}
@@ -0,0 +1,24 @@
+package http
+
+import (
+	"backend/internal/utils"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type KeyController struct {
+	KeyRing *utils.KeyRing
+}
+
+func NewKeyController(keyRing *utils.KeyRing) *KeyController {
+	return &KeyController{KeyRing: keyRing}
+}
+
+// JWKS publishes the public keys verifying access tokens. The response is a plain JWK set,
+// not wrapped in model.DataResponse, so standard JWT libraries can consume it.
+func (ct *KeyController) JWKS(c echo.Context) error {
+	c.Response().Header().Set(echo.HeaderCacheControl, "public, max-age=300")
+
+	return c.JSON(http.StatusOK, ct.KeyRing.JWKS())
+}
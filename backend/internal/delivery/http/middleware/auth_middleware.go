@@ -1,53 +1,118 @@
 package middleware
 
 import (
-	"backend/internal/delivery/http/exception"
+	"backend/internal/apperror"
+	"backend/internal/constant"
+	"backend/internal/entity"
 	"backend/internal/model"
+	"backend/internal/repository"
 	"backend/internal/utils"
+	"errors"
 	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/redis/go-redis/v9"
-	"github.com/spf13/viper"
 )
 
-func AuthMiddleware(viperConfig *viper.Viper, redisClient *redis.Client) echo.MiddlewareFunc {
+// lastSeenResolution limits how often a session's LastSeenAt is written to redis
+const lastSeenResolution = time.Minute
+
+func AuthMiddleware(keyRing *utils.KeyRing, redisClient *redis.Client,
+	sessionRepository *repository.SessionRepository) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			// Get authorization header
-			authHeader := c.Request().Header.Get("Authorization")
-
-			// Get token from Bearer
-			if !strings.Contains(authHeader, "Bearer ") {
-				return exception.NewUnauthorizedError(exception.EmptyTokenMsg)
-			}
-			accessToken := strings.Replace(authHeader, "Bearer ", "", -1)
-
-			// Parse access token data and check expiration time
-			accessTokenData, err := utils.ParseAccessToken(viperConfig, accessToken)
+			currentUser, err := authenticate(c, keyRing, redisClient, sessionRepository)
 			if err != nil {
 				return err
 			}
 
-			// Get token from redis
-			accessTokenRedis, err := redisClient.Get(c.Request().Context(), utils.GenerateAccessTokenRedisKey(accessTokenData.UserID)).Result()
-			if err != nil {
-				return exception.NewUnauthorizedError(exception.InvalidTokenMsg)
-			}
+			// If token are valid, set user data on context
+			c.Set(constant.USER_AUTH_DATA_CONTEXT_NAME, currentUser)
 
-			// Verify if the token are same
-			if accessToken != accessTokenRedis {
-				return exception.NewUnauthorizedError(exception.InvalidTokenMsg)
-			}
+			return next(c)
+		}
+	}
+}
 
-			// If token are valid, set user data on context
-			c.Set("userAuthData", &model.CurrentUser{
-				ID:    accessTokenData.UserID,
-				Email: accessTokenData.UserEmail,
-				Name:  accessTokenData.UserName,
-			})
+// OptionalAuth is AuthMiddleware for routes guests can use too, so they can be personalized for the current user.
+// Requests without a valid token go through as guests, except for an expired token: its EXPIRED_TOKEN error
+// lets the frontend refresh the token instead of silently getting the guest response.
+func OptionalAuth(keyRing *utils.KeyRing, redisClient *redis.Client,
+	sessionRepository *repository.SessionRepository) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			currentUser, err := authenticate(c, keyRing, redisClient, sessionRepository)
+			if errors.Is(err, apperror.NewTokenError(apperror.CODE_EXPIRED_TOKEN)) {
+				return err
+			}
+			if err == nil {
+				c.Set(constant.USER_AUTH_DATA_CONTEXT_NAME, currentUser)
+			}
 
 			return next(c)
 		}
 	}
 }
+
+// CurrentUser returns the user set by AuthMiddleware or OptionalAuth, or an unauthorized error for guests
+func CurrentUser(c echo.Context) (*model.CurrentUser, error) {
+	currentUser := OptionalCurrentUser(c)
+	if currentUser == nil {
+		return nil, apperror.NewUnauthorizedError(apperror.CODE_UNAUTHORIZED, "authentication is required")
+	}
+
+	return currentUser, nil
+}
+
+// OptionalCurrentUser returns the user set by AuthMiddleware or OptionalAuth, or nil for guests
+func OptionalCurrentUser(c echo.Context) *model.CurrentUser {
+	currentUser, _ := c.Get(constant.USER_AUTH_DATA_CONTEXT_NAME).(*model.CurrentUser)
+	return currentUser
+}
+
+// authenticate returns the user of the bearer token of the request, if the token and its session are valid
+func authenticate(c echo.Context, keyRing *utils.KeyRing, redisClient *redis.Client,
+	sessionRepository *repository.SessionRepository) (*model.CurrentUser, error) {
+	// Get authorization header
+	authHeader := c.Request().Header.Get("Authorization")
+
+	// Get token from Bearer
+	if !strings.Contains(authHeader, "Bearer ") {
+		return nil, apperror.NewTokenError(apperror.CODE_EMPTY_TOKEN)
+	}
+	accessToken := strings.Replace(authHeader, "Bearer ", "", -1)
+
+	// Parse access token data and check expiration time
+	accessTokenData, err := utils.ParseAccessToken(keyRing, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the session of the token from redis, it is gone once the session is revoked
+	ctx := c.Request().Context()
+	session := new(entity.Session)
+	if err := sessionRepository.FindByID(ctx, redisClient, session, accessTokenData.SessionID); err != nil {
+		return nil, apperror.NewTokenError(apperror.CODE_INVALID_TOKEN)
+	}
+
+	// Verify if the session belongs to the user of the token
+	if session.UserID != accessTokenData.UserID {
+		return nil, apperror.NewTokenError(apperror.CODE_INVALID_TOKEN)
+	}
+
+	if time.Since(session.LastSeenAt) > lastSeenResolution {
+		if err := sessionRepository.Touch(ctx, redisClient, session); err != nil {
+			return nil, err
+		}
+	}
+
+	return &model.CurrentUser{
+		ID:        accessTokenData.UserID,
+		Email:     accessTokenData.UserEmail,
+		Name:      accessTokenData.UserName,
+		Role:      accessTokenData.UserRole,
+		Locale:    accessTokenData.UserLocale,
+		SessionID: accessTokenData.SessionID,
+	}, nil
+}
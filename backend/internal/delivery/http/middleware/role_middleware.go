@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"backend/internal/apperror"
+	"backend/internal/utils"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RequireRole only lets the request through if the current user has one of roles.
+// It must run after AuthMiddleware.
+func RequireRole(roles ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			currentUser := OptionalCurrentUser(c)
+			if currentUser == nil {
+				return apperror.NewTokenError(apperror.CODE_EMPTY_TOKEN)
+			}
+
+			for _, role := range roles {
+				if currentUser.Role == role {
+					return next(c)
+				}
+			}
+
+			return apperror.New(apperror.KIND_FORBIDDEN, apperror.CODE_ROLE_NOT_ALLOWED,
+				"role "+currentUser.Role+" is not allowed").WithParam("role", currentUser.Role)
+		}
+	}
+}
+
+// RequirePermission only lets the request through if the role of the current user
+// is granted every permission. It must run after AuthMiddleware.
+func RequirePermission(permissions ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			currentUser := OptionalCurrentUser(c)
+			if currentUser == nil {
+				return apperror.NewTokenError(apperror.CODE_EMPTY_TOKEN)
+			}
+
+			for _, permission := range permissions {
+				if !utils.RoleHasPermission(currentUser.Role, permission) {
+					return apperror.New(apperror.KIND_FORBIDDEN, apperror.CODE_PERMISSION_DENIED,
+						"missing permission "+permission).WithParam("permission", permission)
+				}
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -2,22 +2,26 @@ package http
 
 import (
 	"backend/internal/constant"
-	"backend/internal/delivery/http/exception"
+	"backend/internal/delivery/http/middleware"
 	"backend/internal/model"
 	"backend/internal/usecase"
+	"log"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 )
 
 type PostController struct {
-	PostUseCase *usecase.PostUseCase
+	PostUseCase     *usecase.PostUseCase
+	PostStatUseCase *usecase.PostStatUseCase
 }
 
-func NewPostController(postUseCase *usecase.PostUseCase) *PostController {
+func NewPostController(postUseCase *usecase.PostUseCase, postStatUseCase *usecase.PostStatUseCase) *PostController {
 	return &PostController{
-		PostUseCase: postUseCase,
+		PostUseCase:     postUseCase,
+		PostStatUseCase: postStatUseCase,
 	}
 }
 
@@ -27,11 +31,25 @@ func (ct *PostController) GetAll(c echo.Context) error {
 	userID := c.QueryParam("authorID")
 	titleQuery := c.QueryParam("title")
 
+	// tag filters by one tag, tags by a comma separated list matching any of them, or all of them with match=all
+	var tags []string
+	if tag := c.QueryParam("tag"); len(tag) > 0 {
+		tags = append(tags, tag)
+	}
+	if tagList := c.QueryParam("tags"); len(tagList) > 0 {
+		tags = append(tags, strings.Split(tagList, ",")...)
+	}
+
 	request := model.PostListRequest{
 		Page:       page,
 		PageSize:   pageSize,
 		UserID:     userID,
 		TitleQuery: titleQuery,
+		Status:     constant.POST_STATUS_PUBLISHED,
+		Tags:       tags,
+		TagMatch:   c.QueryParam("match"),
+		Category:   c.QueryParam("category"),
+		ViewerID:   viewerID(c),
 	}
 	posts, err := ct.PostUseCase.List(c.Request().Context(), &request)
 	if err != nil {
@@ -50,12 +68,14 @@ func (ct *PostController) GetByID(c echo.Context) error {
 	id, _ := strconv.Atoi(c.Param("id"))
 
 	request := model.PostGetByIDRequest{
-		ID: uint64(id),
+		ID:       uint64(id),
+		ViewerID: viewerID(c),
 	}
 	post, err := ct.PostUseCase.GetByID(c.Request().Context(), &request)
 	if err != nil {
 		return err
 	}
+	ct.recordView(c, post.ID, request.ViewerID)
 
 	response := model.DataResponse[*model.PostResponse]{
 		Code:   http.StatusOK,
@@ -65,19 +85,100 @@ func (ct *PostController) GetByID(c echo.Context) error {
 	return c.JSON(response.Code, response)
 }
 
-func (ct *PostController) Create(c echo.Context) error {
-	// Get current user ID from context
-	authDataInterface := c.Get(constant.USER_AUTH_DATA_CONTEXT_NAME)
+// GetBySlug answers the previous slugs of a renamed post with a permanent redirect to its current slug
+func (ct *PostController) GetBySlug(c echo.Context) error {
+	request := model.PostGetBySlugRequest{
+		Slug:     c.Param("slug"),
+		ViewerID: viewerID(c),
+	}
+	post, err := ct.PostUseCase.GetBySlug(c.Request().Context(), &request)
+	if err != nil {
+		return err
+	}
+
+	if post.Slug != request.Slug {
+		location := strings.Replace(c.Path(), ":slug", post.Slug, 1)
+		if query := c.QueryString(); len(query) > 0 {
+			location += "?" + query
+		}
+		return c.Redirect(http.StatusMovedPermanently, location)
+	}
+	ct.recordView(c, post.ID, request.ViewerID)
+
+	response := model.DataResponse[*model.PostResponse]{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   post,
+	}
+	return c.JSON(response.Code, response)
+}
+
+// GetAllOwn lists the posts of the current user in every status, or only in the status query parameter
+func (ct *PostController) GetAllOwn(c echo.Context) error {
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	pageSize, _ := strconv.Atoi(c.QueryParam("pageSize"))
+
+	currentUser, err := middleware.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	request := model.PostListRequest{
+		Page:       page,
+		PageSize:   pageSize,
+		UserID:     currentUser.ID,
+		TitleQuery: c.QueryParam("title"),
+		Status:     c.QueryParam("status"),
+	}
+	posts, err := ct.PostUseCase.List(c.Request().Context(), &request)
+	if err != nil {
+		return err
+	}
+
+	response := model.DataResponse[[]model.PostResponse]{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   posts,
+	}
+	return c.JSON(response.Code, response)
+}
+
+// GetEditableByID gets a post in any status, so authors can read their drafts before publishing them
+func (ct *PostController) GetEditableByID(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	currentUser, err := middleware.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	request := model.PostGetEditableRequest{
+		ID:       uint64(id),
+		UserID:   currentUser.ID,
+		UserRole: currentUser.Role,
+	}
+	post, err := ct.PostUseCase.GetEditable(c.Request().Context(), &request)
+	if err != nil {
+		return err
+	}
 
-	// Check if data is on CurrentUser tyoe
-	currentUser, ok := authDataInterface.(*model.CurrentUser)
-	if !ok {
-		return echo.ErrUnauthorized
+	response := model.DataResponse[*model.PostResponse]{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   post,
+	}
+	return c.JSON(response.Code, response)
+}
+
+func (ct *PostController) Create(c echo.Context) error {
+	currentUser, err := middleware.CurrentUser(c)
+	if err != nil {
+		return err
 	}
 
 	request := new(model.PostCreateRequest)
 	if err := c.Bind(request); err != nil {
-		return exception.NewBadRequestError(err.Error())
+		return err
 	}
 
 	request.AuthorID = currentUser.ID
@@ -99,22 +200,19 @@ func (ct *PostController) Update(c echo.Context) error {
 	// Get id from parameter
 	id, _ := strconv.Atoi(c.Param("id"))
 
-	// Get current user ID from context
-	authDataInterface := c.Get(constant.USER_AUTH_DATA_CONTEXT_NAME)
-
-	// Check if data is on CurrentUser type
-	currentUser, ok := authDataInterface.(*model.CurrentUser)
-	if !ok {
-		return echo.ErrUnauthorized
+	currentUser, err := middleware.CurrentUser(c)
+	if err != nil {
+		return err
 	}
 
 	request := new(model.PostUpdateRequest)
 	if err := c.Bind(request); err != nil {
-		return exception.NewBadRequestError(err.Error())
+		return err
 	}
 
 	request.ID = uint64(id)
 	request.AuthorID = currentUser.ID
+	request.UserRole = currentUser.Role
 
 	post, err := ct.PostUseCase.Update(c.Request().Context(), request)
 	if err != nil {
@@ -132,19 +230,16 @@ func (ct *PostController) Update(c echo.Context) error {
 func (ct *PostController) Delete(c echo.Context) error {
 	id, _ := strconv.Atoi(c.Param("id"))
 
-	// Get current user ID from context
-	authDataInterface := c.Get(constant.USER_AUTH_DATA_CONTEXT_NAME)
-
-	// Check if data is on CurrentUser type
-	currentUser, ok := authDataInterface.(*model.CurrentUser)
-	if !ok {
-		return echo.ErrUnauthorized
+	currentUser, err := middleware.CurrentUser(c)
+	if err != nil {
+		return err
 	}
 
 	// Delete post with service
 	request := model.PostDeleteRequest{
-		ID:     uint64(id),
-		UserID: currentUser.ID,
+		ID:       uint64(id),
+		UserID:   currentUser.ID,
+		UserRole: currentUser.Role,
 	}
 	if err := ct.PostUseCase.Delete(c.Request().Context(), &request); err != nil {
 		return err
@@ -156,3 +251,222 @@ func (ct *PostController) Delete(c echo.Context) error {
 	}
 	return c.JSON(response.Code, response)
 }
+
+// GetTrash lists the posts the current user moved to the trash, from the last deleted
+func (ct *PostController) GetTrash(c echo.Context) error {
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	pageSize, _ := strconv.Atoi(c.QueryParam("pageSize"))
+
+	currentUser, err := middleware.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	request := model.PostListRequest{
+		Page:     page,
+		PageSize: pageSize,
+		UserID:   currentUser.ID,
+		Trashed:  true,
+	}
+	posts, err := ct.PostUseCase.List(c.Request().Context(), &request)
+	if err != nil {
+		return err
+	}
+
+	response := model.DataResponse[[]model.PostResponse]{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   posts,
+	}
+	return c.JSON(response.Code, response)
+}
+
+func (ct *PostController) Restore(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	currentUser, err := middleware.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	request := model.PostRestoreRequest{
+		ID:       uint64(id),
+		UserID:   currentUser.ID,
+		UserRole: currentUser.Role,
+	}
+	post, err := ct.PostUseCase.Restore(c.Request().Context(), &request)
+	if err != nil {
+		return err
+	}
+
+	response := model.DataResponse[*model.PostResponse]{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   post,
+	}
+	return c.JSON(response.Code, response)
+}
+
+func (ct *PostController) GetRevisions(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	currentUser, err := middleware.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	request := model.PostRevisionListRequest{
+		PostID:   uint64(id),
+		UserID:   currentUser.ID,
+		UserRole: currentUser.Role,
+	}
+	revisions, err := ct.PostUseCase.ListRevisions(c.Request().Context(), &request)
+	if err != nil {
+		return err
+	}
+
+	response := model.DataResponse[[]model.PostRevisionResponse]{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   revisions,
+	}
+	return c.JSON(response.Code, response)
+}
+
+func (ct *PostController) GetRevision(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	number, _ := strconv.Atoi(c.Param("number"))
+
+	currentUser, err := middleware.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	request := model.PostRevisionGetRequest{
+		PostID:   uint64(id),
+		Number:   number,
+		UserID:   currentUser.ID,
+		UserRole: currentUser.Role,
+	}
+	revision, err := ct.PostUseCase.GetRevision(c.Request().Context(), &request)
+	if err != nil {
+		return err
+	}
+
+	response := model.DataResponse[*model.PostRevisionResponse]{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   revision,
+	}
+	return c.JSON(response.Code, response)
+}
+
+// DiffRevisions compares the revisions in the from and to query parameters, by line unless mode=word
+func (ct *PostController) DiffRevisions(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	from, _ := strconv.Atoi(c.QueryParam("from"))
+	to, _ := strconv.Atoi(c.QueryParam("to"))
+
+	currentUser, err := middleware.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	request := model.PostRevisionDiffRequest{
+		PostID:   uint64(id),
+		From:     from,
+		To:       to,
+		Mode:     c.QueryParam("mode"),
+		UserID:   currentUser.ID,
+		UserRole: currentUser.Role,
+	}
+	diff, err := ct.PostUseCase.DiffRevisions(c.Request().Context(), &request)
+	if err != nil {
+		return err
+	}
+
+	response := model.DataResponse[*model.PostRevisionDiffResponse]{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   diff,
+	}
+	return c.JSON(response.Code, response)
+}
+
+func (ct *PostController) RestoreRevision(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	number, _ := strconv.Atoi(c.Param("number"))
+
+	currentUser, err := middleware.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	request := model.PostRevisionGetRequest{
+		PostID:   uint64(id),
+		Number:   number,
+		UserID:   currentUser.ID,
+		UserRole: currentUser.Role,
+	}
+	post, err := ct.PostUseCase.RestoreRevision(c.Request().Context(), &request)
+	if err != nil {
+		return err
+	}
+
+	response := model.DataResponse[*model.PostResponse]{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   post,
+	}
+	return c.JSON(response.Code, response)
+}
+
+// GetStats gets the daily views of a post between the from and to query parameters, formatted like 2006-01-02
+func (ct *PostController) GetStats(c echo.Context) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	currentUser, err := middleware.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	request := model.PostStatsRequest{
+		PostID:   uint64(id),
+		From:     c.QueryParam("from"),
+		To:       c.QueryParam("to"),
+		UserID:   currentUser.ID,
+		UserRole: currentUser.Role,
+	}
+	stats, err := ct.PostStatUseCase.Get(c.Request().Context(), &request)
+	if err != nil {
+		return err
+	}
+
+	response := model.DataResponse[*model.PostStatsResponse]{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   stats,
+	}
+	return c.JSON(response.Code, response)
+}
+
+// recordView counts a view of a post that was read. Failing to count it doesn't fail the request.
+func (ct *PostController) recordView(c echo.Context, postID uint64, viewerID string) {
+	request := model.PostViewRequest{
+		PostID:    postID,
+		UserID:    viewerID,
+		IPAddress: c.RealIP(),
+		UserAgent: c.Request().UserAgent(),
+	}
+	if err := ct.PostStatUseCase.RecordView(c.Request().Context(), &request); err != nil {
+		log.Println("recording post view:", err)
+	}
+}
+
+// viewerID is the ID of the current user on routes behind OptionalAuth, empty for guests
+func viewerID(c echo.Context) string {
+	if currentUser := middleware.OptionalCurrentUser(c); currentUser != nil {
+		return currentUser.ID
+	}
+	return ""
+}
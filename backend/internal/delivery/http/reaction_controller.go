@@ -0,0 +1,48 @@
+package http
+
+import (
+	"backend/internal/delivery/http/middleware"
+	"backend/internal/model"
+	"backend/internal/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ReactionController struct {
+	ReactionUseCase *usecase.ReactionUseCase
+}
+
+func NewReactionController(reactionUseCase *usecase.ReactionUseCase) *ReactionController {
+	return &ReactionController{
+		ReactionUseCase: reactionUseCase,
+	}
+}
+
+// Toggle adds the reaction kind of the current user to a post, or removes it when it is there already
+func (ct *ReactionController) Toggle(c echo.Context) error {
+	postID, _ := strconv.Atoi(c.Param("id"))
+
+	currentUser, err := middleware.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	request := model.ReactionToggleRequest{
+		PostID: uint64(postID),
+		Kind:   c.Param("kind"),
+		UserID: currentUser.ID,
+	}
+	result, err := ct.ReactionUseCase.Toggle(c.Request().Context(), &request)
+	if err != nil {
+		return err
+	}
+
+	response := model.DataResponse[*model.ReactionToggleResponse]{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   result,
+	}
+	return c.JSON(response.Code, response)
+}
@@ -1,7 +1,9 @@
 package route
 
 import (
+	"backend/internal/constant"
 	"backend/internal/delivery/http"
+	authMiddleware "backend/internal/delivery/http/middleware"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -10,15 +12,29 @@ import (
 var parentRoute = "/api"
 
 type RouteConfig struct {
-	App            *echo.Echo
-	PostController *http.PostController
-	UserController *http.UserController
-	AuthMiddleware echo.MiddlewareFunc
+	App                *echo.Echo
+	PostController     *http.PostController
+	UserController     *http.UserController
+	SessionController  *http.SessionController
+	TagController      *http.TagController
+	CategoryController *http.CategoryController
+	CommentController  *http.CommentController
+	ReactionController *http.ReactionController
+	BookmarkController *http.BookmarkController
+	KeyController      *http.KeyController
+	AuthMiddleware     echo.MiddlewareFunc
+
+	// OptionalAuthMiddleware authenticates requests with a valid token and lets the others through as guests
+	OptionalAuthMiddleware echo.MiddlewareFunc
 }
 
 func (r *RouteConfig) Setup() {
 	r.SetupCommon()
+	r.SetupWellKnownRoute()
 	r.SetupGuestRoute()
+	r.SetupTagRoute()
+	r.SetupCategoryRoute()
+	r.SetupCommentRoute()
 	r.SetupAuthRoute()
 	r.SetupUserRoute()
 	r.SetupAdminRoute()
@@ -33,12 +49,44 @@ func (r *RouteConfig) SetupCommon() {
 	}))
 }
 
+func (r *RouteConfig) SetupWellKnownRoute() {
+	g := r.App.Group("/.well-known")
+	g.GET("/jwks.json", r.KeyController.JWKS)
+}
+
 func (r *RouteConfig) SetupGuestRoute() {
 	routeGroup := "/posts"
 
 	g := r.App.Group(parentRoute + routeGroup)
-	g.GET("", r.PostController.GetAll)
-	g.GET("/:id", r.PostController.GetByID)
+	g.GET("", r.PostController.GetAll, r.OptionalAuthMiddleware)
+	g.GET("/:id", r.PostController.GetByID, r.OptionalAuthMiddleware)
+	g.GET("/by-slug/:slug", r.PostController.GetBySlug, r.OptionalAuthMiddleware)
+	g.POST("/:id/reactions/:kind", r.ReactionController.Toggle, r.AuthMiddleware)
+}
+
+func (r *RouteConfig) SetupTagRoute() {
+	routeGroup := "/tags"
+
+	g := r.App.Group(parentRoute + routeGroup)
+	g.GET("", r.TagController.GetAll)
+	g.GET("/:slug", r.TagController.GetBySlug)
+}
+
+func (r *RouteConfig) SetupCategoryRoute() {
+	routeGroup := "/categories"
+
+	g := r.App.Group(parentRoute + routeGroup)
+	g.GET("", r.CategoryController.GetTree)
+	g.GET("/:slug", r.CategoryController.GetBySlug)
+}
+
+func (r *RouteConfig) SetupCommentRoute() {
+	g := r.App.Group(parentRoute)
+
+	g.GET("/posts/:id/comments", r.CommentController.GetAll)
+	g.POST("/posts/:id/comments", r.CommentController.Create, r.AuthMiddleware)
+	g.PUT("/comments/:id", r.CommentController.Update, r.AuthMiddleware)
+	g.DELETE("/comments/:id", r.CommentController.Delete, r.AuthMiddleware)
 }
 
 func (r *RouteConfig) SetupAuthRoute() {
@@ -48,8 +96,13 @@ func (r *RouteConfig) SetupAuthRoute() {
 
 	g.POST("/register", r.UserController.Register)
 	g.POST("/login", r.UserController.Login)
+	g.POST("/login/mfa", r.UserController.LoginMFA)
 	g.POST("/logout", r.UserController.Logout, r.AuthMiddleware)
 	g.POST("/refresh", r.UserController.Refresh)
+	g.POST("/verify", r.UserController.Verify)
+	g.POST("/verify/resend", r.UserController.ResendVerification)
+	g.POST("/password/forgot", r.UserController.ForgotPassword)
+	g.POST("/password/reset", r.UserController.ResetPassword)
 }
 
 func (r *RouteConfig) SetupUserRoute() {
@@ -58,6 +111,30 @@ func (r *RouteConfig) SetupUserRoute() {
 	g := r.App.Group(parentRoute + routeGroup)
 
 	g.GET("/current", r.UserController.Current, r.AuthMiddleware)
+	g.PUT("/password", r.UserController.ChangePassword, r.AuthMiddleware)
+	g.PUT("/locale", r.UserController.UpdateLocale, r.AuthMiddleware)
+
+	g.POST("/totp", r.UserController.EnrollTOTP, r.AuthMiddleware)
+	g.POST("/totp/confirm", r.UserController.ConfirmTOTP, r.AuthMiddleware)
+	g.DELETE("/totp", r.UserController.DisableTOTP, r.AuthMiddleware)
+	g.POST("/totp/recovery-codes", r.UserController.RegenerateRecoveryCodes, r.AuthMiddleware)
+
+	g.GET("/sessions", r.SessionController.GetAll, r.AuthMiddleware)
+	g.DELETE("/sessions", r.SessionController.RevokeAll, r.AuthMiddleware)
+	g.DELETE("/sessions/:id", r.SessionController.Revoke, r.AuthMiddleware)
+
+	g.GET("/bookmarks", r.BookmarkController.GetAll, r.AuthMiddleware)
+	g.PUT("/bookmarks/:postId", r.BookmarkController.Save, r.AuthMiddleware)
+	g.DELETE("/bookmarks/:postId", r.BookmarkController.Unsave, r.AuthMiddleware)
+
+	g.GET("/reading-lists", r.BookmarkController.GetReadingLists, r.AuthMiddleware)
+	g.POST("/reading-lists", r.BookmarkController.CreateReadingList, r.AuthMiddleware)
+	g.GET("/reading-lists/:id", r.BookmarkController.GetReadingList, r.AuthMiddleware)
+	g.PUT("/reading-lists/:id", r.BookmarkController.UpdateReadingList, r.AuthMiddleware)
+	g.DELETE("/reading-lists/:id", r.BookmarkController.DeleteReadingList, r.AuthMiddleware)
+	g.POST("/reading-lists/:id/posts", r.BookmarkController.AddToReadingList, r.AuthMiddleware)
+	g.DELETE("/reading-lists/:id/posts/:postId", r.BookmarkController.RemoveFromReadingList, r.AuthMiddleware)
+	g.PUT("/reading-lists/:id/order", r.BookmarkController.ReorderReadingList, r.AuthMiddleware)
 }
 
 func (r *RouteConfig) SetupAdminRoute() {
@@ -66,7 +143,41 @@ func (r *RouteConfig) SetupAdminRoute() {
 	g := r.App.Group(parentRoute + routeGroup)
 	g.Use(r.AuthMiddleware)
 
-	g.POST("/posts", r.PostController.Create)
-	g.PUT("/posts/:id", r.PostController.Update)
-	g.DELETE("/posts/:id", r.PostController.Delete)
+	// Authors can only edit their own posts, PostUseCase lets editors and admins edit every post
+	canCreatePost := authMiddleware.RequirePermission(constant.PERMISSION_POST_CREATE)
+	canEditPost := authMiddleware.RequirePermission(constant.PERMISSION_POST_EDIT_OWN)
+
+	g.GET("/posts", r.PostController.GetAllOwn, canCreatePost)
+	g.GET("/posts/:id", r.PostController.GetEditableByID, canEditPost)
+	g.POST("/posts", r.PostController.Create, canCreatePost)
+	g.PUT("/posts/:id", r.PostController.Update, canEditPost)
+	g.DELETE("/posts/:id", r.PostController.Delete, canEditPost)
+
+	g.GET("/posts/trash", r.PostController.GetTrash, canCreatePost)
+	g.POST("/posts/:id/restore", r.PostController.Restore, canEditPost)
+	g.GET("/posts/:id/stats", r.PostController.GetStats, canEditPost)
+
+	g.GET("/posts/:id/revisions", r.PostController.GetRevisions, canEditPost)
+	g.GET("/posts/:id/revisions/diff", r.PostController.DiffRevisions, canEditPost)
+	g.GET("/posts/:id/revisions/:number", r.PostController.GetRevision, canEditPost)
+	g.POST("/posts/:id/revisions/:number/restore", r.PostController.RestoreRevision, canEditPost)
+
+	canManageCategory := authMiddleware.RequirePermission(constant.PERMISSION_CATEGORY_MANAGE)
+
+	g.POST("/categories", r.CategoryController.Create, canManageCategory)
+	g.PUT("/categories/:id", r.CategoryController.Update, canManageCategory)
+	g.DELETE("/categories/:id", r.CategoryController.Delete, canManageCategory)
+
+	canModerateComment := authMiddleware.RequirePermission(constant.PERMISSION_COMMENT_MODERATE)
+
+	g.GET("/comments", r.CommentController.GetQueue, canModerateComment)
+	g.POST("/comments/approve", r.CommentController.Approve, canModerateComment)
+	g.POST("/comments/reject", r.CommentController.Reject, canModerateComment)
+	g.PUT("/users/:id/trusted-commenter", r.UserController.UpdateTrustedCommenter, canModerateComment)
+
+	canManageUser := authMiddleware.RequirePermission(constant.PERMISSION_USER_MANAGE)
+
+	g.GET("/users", r.UserController.List, canManageUser)
+	g.PUT("/users/:id/role", r.UserController.UpdateRole, canManageUser)
+	g.POST("/users/:id/unlock", r.UserController.Unlock, canManageUser)
 }
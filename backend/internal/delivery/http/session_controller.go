@@ -0,0 +1,83 @@
+package http
+
+import (
+	"backend/internal/constant"
+	"backend/internal/delivery/http/middleware"
+	"backend/internal/model"
+	"backend/internal/usecase"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type SessionController struct {
+	SessionUseCase *usecase.SessionUseCase
+}
+
+func NewSessionController(sessionUseCase *usecase.SessionUseCase) *SessionController {
+	return &SessionController{SessionUseCase: sessionUseCase}
+}
+
+func (ct *SessionController) GetAll(c echo.Context) error {
+	currentUser, err := middleware.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	sessions, err := ct.SessionUseCase.List(c.Request().Context(), currentUser)
+	if err != nil {
+		return err
+	}
+
+	response := model.DataResponse[[]model.SessionResponse]{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   sessions,
+	}
+	return c.JSON(response.Code, response)
+}
+
+func (ct *SessionController) Revoke(c echo.Context) error {
+	currentUser, err := middleware.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	request := model.SessionRevokeRequest{
+		ID:     c.Param("id"),
+		UserID: currentUser.ID,
+	}
+	if err := ct.SessionUseCase.Revoke(c.Request().Context(), &request); err != nil {
+		return err
+	}
+
+	response := model.DataResponse[any]{
+		Code:   http.StatusOK,
+		Status: "OK",
+	}
+	return c.JSON(response.Code, response)
+}
+
+func (ct *SessionController) RevokeAll(c echo.Context) error {
+	currentUser, err := middleware.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	if err := ct.SessionUseCase.RevokeAll(c.Request().Context(), currentUser); err != nil {
+		return err
+	}
+
+	// The current session is revoked too, so delete the refresh token cookie
+	c.SetCookie(&http.Cookie{
+		Name:     constant.REFRESH_TOKEN_COOKIE_NAME,
+		MaxAge:   -1,
+		HttpOnly: true,
+	})
+
+	response := model.DataResponse[any]{
+		Code:   http.StatusOK,
+		Status: "OK",
+	}
+	return c.JSON(response.Code, response)
+}
@@ -0,0 +1,50 @@
+package http
+
+import (
+	"backend/internal/model"
+	"backend/internal/usecase"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type TagController struct {
+	TagUseCase *usecase.TagUseCase
+}
+
+func NewTagController(tagUseCase *usecase.TagUseCase) *TagController {
+	return &TagController{
+		TagUseCase: tagUseCase,
+	}
+}
+
+func (ct *TagController) GetAll(c echo.Context) error {
+	tags, err := ct.TagUseCase.List(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	response := model.DataResponse[[]model.TagResponse]{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   tags,
+	}
+	return c.JSON(response.Code, response)
+}
+
+func (ct *TagController) GetBySlug(c echo.Context) error {
+	request := model.TagGetBySlugRequest{
+		Slug: c.Param("slug"),
+	}
+	tag, err := ct.TagUseCase.GetBySlug(c.Request().Context(), &request)
+	if err != nil {
+		return err
+	}
+
+	response := model.DataResponse[*model.TagResponse]{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   tag,
+	}
+	return c.JSON(response.Code, response)
+}
@@ -1,12 +1,13 @@
 package http
 
 import (
+	"backend/internal/apperror"
 	"backend/internal/constant"
-	"backend/internal/delivery/http/exception"
+	"backend/internal/delivery/http/middleware"
 	"backend/internal/model"
 	"backend/internal/usecase"
-	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/labstack/echo/v4"
 )
@@ -44,20 +45,42 @@ func (ct *UserController) Login(c echo.Context) error {
 		return err
 	}
 
+	request.UserAgent = c.Request().UserAgent()
+	request.IPAddress = c.RealIP()
+
 	loginResponse, err := ct.UserUseCase.Login(c.Request().Context(), request)
 	if err != nil {
 		return err
 	}
 
-	fmt.Println("REFRESH_EXP_AT", loginResponse.RefreshExpAt)
+	// With two-factor authentication there is no refresh token until LoginMFA
+	if !loginResponse.MFARequired {
+		setRefreshTokenCookie(c, loginResponse)
+	}
 
-	// Send refresh token to cookie
-	cookie := new(http.Cookie)
-	cookie.Name = constant.REFRESH_TOKEN_COOKIE_NAME
-	cookie.Value = loginResponse.RefreshToken
-	cookie.Expires = loginResponse.RefreshExpAt
-	cookie.HttpOnly = true
-	c.SetCookie(cookie)
+	response := model.DataResponse[*model.TokenData]{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   loginResponse,
+	}
+	return c.JSON(response.Code, response)
+}
+
+func (ct *UserController) LoginMFA(c echo.Context) error {
+	request := new(model.LoginMFARequest)
+	if err := c.Bind(request); err != nil {
+		return err
+	}
+
+	request.UserAgent = c.Request().UserAgent()
+	request.IPAddress = c.RealIP()
+
+	loginResponse, err := ct.UserUseCase.LoginMFA(c.Request().Context(), request)
+	if err != nil {
+		return err
+	}
+
+	setRefreshTokenCookie(c, loginResponse)
 
 	response := model.DataResponse[*model.TokenData]{
 		Code:   http.StatusOK,
@@ -67,14 +90,20 @@ func (ct *UserController) Login(c echo.Context) error {
 	return c.JSON(response.Code, response)
 }
 
-func (ct *UserController) Current(c echo.Context) error {
-	// Get current user ID and email from context
-	authDataInterface := c.Get(constant.USER_AUTH_DATA_CONTEXT_NAME)
+// setRefreshTokenCookie sends the refresh token of a new session as an HTTPOnly cookie
+func setRefreshTokenCookie(c echo.Context, tokenData *model.TokenData) {
+	cookie := new(http.Cookie)
+	cookie.Name = constant.REFRESH_TOKEN_COOKIE_NAME
+	cookie.Value = tokenData.RefreshToken
+	cookie.Expires = tokenData.RefreshExpAt
+	cookie.HttpOnly = true
+	c.SetCookie(cookie)
+}
 
-	// Check if data is on CurrentUser type
-	currentUser, ok := authDataInterface.(*model.CurrentUser)
-	if !ok {
-		return exception.NewUnauthorizedError("")
+func (ct *UserController) Current(c echo.Context) error {
+	currentUser, err := middleware.CurrentUser(c)
+	if err != nil {
+		return err
 	}
 
 	// Get full user data from service
@@ -92,22 +121,34 @@ func (ct *UserController) Refresh(c echo.Context) error {
 	// Get refresh token from HTTPOnly cookie
 	cookie, err := c.Cookie(constant.REFRESH_TOKEN_COOKIE_NAME)
 	if err != nil {
-		return exception.NewUnauthorizedError(err.Error())
+		return apperror.NewTokenError(apperror.CODE_EMPTY_TOKEN)
 	}
 
 	// Check if token empty
 	if cookie.Value == "" {
-		return exception.NewUnauthorizedError(exception.EmptyTokenMsg)
+		return apperror.NewTokenError(apperror.CODE_EMPTY_TOKEN)
 	}
 
 	tokenData := new(model.TokenData)
 	tokenData.RefreshToken = cookie.Value
 
-	// Get new access token by passing current refresh token
+	// Get new access and refresh token by passing current refresh token
 	if err := ct.UserUseCase.Refresh(c.Request().Context(), tokenData); err != nil {
+		// The refresh token is invalid or has been revoked, so delete the cookie
+		cookie.MaxAge = -1
+		cookie.Value = ""
+		c.SetCookie(cookie)
 		return err
 	}
 
+	// Replace the refresh token cookie with the rotated one
+	newCookie := new(http.Cookie)
+	newCookie.Name = constant.REFRESH_TOKEN_COOKIE_NAME
+	newCookie.Value = tokenData.RefreshToken
+	newCookie.Expires = tokenData.RefreshExpAt
+	newCookie.HttpOnly = true
+	c.SetCookie(newCookie)
+
 	response := model.DataResponse[*model.TokenData]{
 		Code:   http.StatusOK,
 		Status: "OK",
@@ -120,7 +161,7 @@ func (ct *UserController) Logout(c echo.Context) error {
 	// Get refresh token from HTTPOnly cookie
 	cookie, err := c.Cookie(constant.REFRESH_TOKEN_COOKIE_NAME)
 	if err != nil {
-		return exception.NewUnauthorizedError(err.Error())
+		return apperror.NewTokenError(apperror.CODE_EMPTY_TOKEN)
 	}
 
 	// Delete cookie by setting maxAge to negative value
@@ -129,12 +170,9 @@ func (ct *UserController) Logout(c echo.Context) error {
 	// Send the cookie
 	c.SetCookie(cookie)
 
-	// Get auth data from context
-	authDataInterface := c.Get(constant.USER_AUTH_DATA_CONTEXT_NAME)
-
-	currentUser, ok := authDataInterface.(*model.CurrentUser)
-	if !ok {
-		return exception.NewUnauthorizedError("")
+	currentUser, err := middleware.CurrentUser(c)
+	if err != nil {
+		return err
 	}
 
 	// Passing auth data to service. Service will remove both of access token and refresh token
@@ -145,3 +183,303 @@ func (ct *UserController) Logout(c echo.Context) error {
 
 	return nil
 }
+
+func (ct *UserController) List(c echo.Context) error {
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	pageSize, _ := strconv.Atoi(c.QueryParam("pageSize"))
+
+	request := model.UserListRequest{
+		Page:     page,
+		PageSize: pageSize,
+		Role:     c.QueryParam("role"),
+	}
+	users, err := ct.UserUseCase.List(c.Request().Context(), &request)
+	if err != nil {
+		return err
+	}
+
+	response := model.DataResponse[[]model.UserResponse]{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   users,
+	}
+	return c.JSON(response.Code, response)
+}
+
+func (ct *UserController) UpdateRole(c echo.Context) error {
+	currentUser, err := middleware.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	request := new(model.UpdateUserRoleRequest)
+	if err := c.Bind(request); err != nil {
+		return err
+	}
+
+	request.UserID = c.Param("id")
+	request.ChangedBy = currentUser.ID
+
+	user, err := ct.UserUseCase.UpdateRole(c.Request().Context(), request)
+	if err != nil {
+		return err
+	}
+
+	response := model.DataResponse[*model.UserResponse]{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   user,
+	}
+	return c.JSON(response.Code, response)
+}
+
+func (ct *UserController) UpdateTrustedCommenter(c echo.Context) error {
+	request := new(model.UpdateTrustedCommenterRequest)
+	if err := c.Bind(request); err != nil {
+		return err
+	}
+
+	request.UserID = c.Param("id")
+
+	user, err := ct.UserUseCase.UpdateTrustedCommenter(c.Request().Context(), request)
+	if err != nil {
+		return err
+	}
+
+	response := model.DataResponse[*model.UserResponse]{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   user,
+	}
+	return c.JSON(response.Code, response)
+}
+
+func (ct *UserController) Unlock(c echo.Context) error {
+	request := &model.UnlockUserRequest{
+		UserID: c.Param("id"),
+	}
+
+	user, err := ct.UserUseCase.Unlock(c.Request().Context(), request)
+	if err != nil {
+		return err
+	}
+
+	response := model.DataResponse[*model.UserResponse]{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   user,
+	}
+	return c.JSON(response.Code, response)
+}
+
+func (ct *UserController) Verify(c echo.Context) error {
+	request := new(model.VerifyEmailRequest)
+	if err := c.Bind(request); err != nil {
+		return err
+	}
+
+	if err := ct.UserUseCase.Verify(c.Request().Context(), request); err != nil {
+		return err
+	}
+
+	response := model.DataResponse[any]{
+		Code:   http.StatusOK,
+		Status: "OK",
+	}
+	return c.JSON(response.Code, response)
+}
+
+func (ct *UserController) ResendVerification(c echo.Context) error {
+	request := new(model.ResendVerificationRequest)
+	if err := c.Bind(request); err != nil {
+		return err
+	}
+
+	if err := ct.UserUseCase.ResendVerification(c.Request().Context(), request); err != nil {
+		return err
+	}
+
+	response := model.DataResponse[any]{
+		Code:   http.StatusOK,
+		Status: "OK",
+	}
+	return c.JSON(response.Code, response)
+}
+
+func (ct *UserController) ChangePassword(c echo.Context) error {
+	currentUser, err := middleware.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	request := new(model.ChangePasswordRequest)
+	if err := c.Bind(request); err != nil {
+		return err
+	}
+
+	request.UserID = currentUser.ID
+	request.SessionID = currentUser.SessionID
+
+	if err := ct.UserUseCase.ChangePassword(c.Request().Context(), request); err != nil {
+		return err
+	}
+
+	response := model.DataResponse[any]{
+		Code:   http.StatusOK,
+		Status: "OK",
+	}
+	return c.JSON(response.Code, response)
+}
+
+func (ct *UserController) ForgotPassword(c echo.Context) error {
+	request := new(model.ForgotPasswordRequest)
+	if err := c.Bind(request); err != nil {
+		return err
+	}
+
+	if err := ct.UserUseCase.ForgotPassword(c.Request().Context(), request); err != nil {
+		return err
+	}
+
+	response := model.DataResponse[any]{
+		Code:   http.StatusOK,
+		Status: "OK",
+	}
+	return c.JSON(response.Code, response)
+}
+
+func (ct *UserController) ResetPassword(c echo.Context) error {
+	request := new(model.ResetPasswordRequest)
+	if err := c.Bind(request); err != nil {
+		return err
+	}
+
+	if err := ct.UserUseCase.ResetPassword(c.Request().Context(), request); err != nil {
+		return err
+	}
+
+	response := model.DataResponse[any]{
+		Code:   http.StatusOK,
+		Status: "OK",
+	}
+	return c.JSON(response.Code, response)
+}
+
+func (ct *UserController) EnrollTOTP(c echo.Context) error {
+	currentUser, err := middleware.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	enrollment, err := ct.UserUseCase.EnrollTOTP(c.Request().Context(), currentUser)
+	if err != nil {
+		return err
+	}
+
+	response := model.DataResponse[*model.TOTPEnrollmentResponse]{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   enrollment,
+	}
+	return c.JSON(response.Code, response)
+}
+
+func (ct *UserController) ConfirmTOTP(c echo.Context) error {
+	currentUser, err := middleware.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	request := new(model.TOTPCodeRequest)
+	if err := c.Bind(request); err != nil {
+		return err
+	}
+
+	request.UserID = currentUser.ID
+
+	recoveryCodes, err := ct.UserUseCase.ConfirmTOTP(c.Request().Context(), request)
+	if err != nil {
+		return err
+	}
+
+	response := model.DataResponse[*model.RecoveryCodesResponse]{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   recoveryCodes,
+	}
+	return c.JSON(response.Code, response)
+}
+
+func (ct *UserController) DisableTOTP(c echo.Context) error {
+	currentUser, err := middleware.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	request := new(model.TOTPDisableRequest)
+	if err := c.Bind(request); err != nil {
+		return err
+	}
+
+	request.UserID = currentUser.ID
+
+	if err := ct.UserUseCase.DisableTOTP(c.Request().Context(), request); err != nil {
+		return err
+	}
+
+	response := model.DataResponse[any]{
+		Code:   http.StatusOK,
+		Status: "OK",
+	}
+	return c.JSON(response.Code, response)
+}
+
+func (ct *UserController) RegenerateRecoveryCodes(c echo.Context) error {
+	currentUser, err := middleware.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	request := new(model.TOTPCodeRequest)
+	if err := c.Bind(request); err != nil {
+		return err
+	}
+
+	request.UserID = currentUser.ID
+
+	recoveryCodes, err := ct.UserUseCase.RegenerateRecoveryCodes(c.Request().Context(), request)
+	if err != nil {
+		return err
+	}
+
+	response := model.DataResponse[*model.RecoveryCodesResponse]{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   recoveryCodes,
+	}
+	return c.JSON(response.Code, response)
+}
+
+func (ct *UserController) UpdateLocale(c echo.Context) error {
+	currentUser, err := middleware.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	request := new(model.UpdateLocaleRequest)
+	if err := c.Bind(request); err != nil {
+		return err
+	}
+
+	request.UserID = currentUser.ID
+
+	if err := ct.UserUseCase.UpdateLocale(c.Request().Context(), request); err != nil {
+		return err
+	}
+
+	response := model.DataResponse[any]{
+		Code:   http.StatusOK,
+		Status: "OK",
+	}
+	return c.JSON(response.Code, response)
+}
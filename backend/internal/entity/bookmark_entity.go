@@ -0,0 +1,36 @@
+package entity
+
+import "time"
+
+type Bookmark struct {
+	UserID    string    `gorm:"primaryKey"`
+	PostID    uint64    `gorm:"primaryKey"`
+	CreatedAt time.Time `gorm:"<-create"`
+}
+
+func (e *Bookmark) EntityName() string {
+	return "bookmark"
+}
+
+// ReadingList is a named collection of posts of a user, in the order the user chose
+type ReadingList struct {
+	ID        uint64 `gorm:"primaryKey"`
+	UserID    string
+	Name      string    // Unique per user
+	CreatedAt time.Time `gorm:"<-create"`
+}
+
+func (e *ReadingList) EntityName() string {
+	return "reading list"
+}
+
+type ReadingListItem struct {
+	ReadingListID uint64    `gorm:"primaryKey"`
+	PostID        uint64    `gorm:"primaryKey"`
+	Position      int       // From 1, without gaps
+	CreatedAt     time.Time `gorm:"<-create"`
+}
+
+func (e *ReadingListItem) EntityName() string {
+	return "reading list item"
+}
@@ -0,0 +1,15 @@
+package entity
+
+import "time"
+
+type Category struct {
+	ID        uint64 `gorm:"primaryKey"`
+	Name      string
+	Slug      string
+	ParentID  *uint64   // Nil for top level categories
+	CreatedAt time.Time `gorm:"<-create"`
+}
+
+func (e *Category) EntityName() string {
+	return "category"
+}
@@ -0,0 +1,21 @@
+package entity
+
+import "time"
+
+// Comment is a comment on a post, or a reply to ParentID when it is set
+type Comment struct {
+	ID         uint64 `gorm:"primaryKey"`
+	PostID     uint64
+	ParentID   *uint64
+	UserID     string
+	Content    string
+	Status     string
+	SpamReason *string   // Why the spam checker held the comment for moderation
+	CreatedAt  time.Time `gorm:"<-create"`
+	UpdatedAt  time.Time
+	DeletedAt  *time.Time // Set when a comment with replies is deleted, its replies stay in the thread
+}
+
+func (e *Comment) EntityName() string {
+	return "comment"
+}
@@ -1,13 +1,22 @@
 package entity
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type Post struct {
-	ID        uint64 `gorm:"primaryKey"`
-	Title     string
-	Content   string
-	CreatedAt time.Time `gorm:"<-create"`
-	UserID    string
+	ID          uint64 `gorm:"primaryKey"`
+	Title       string
+	Slug        string // Unique, from the title. Previous slugs are kept as PostSlug
+	Content     string
+	Status      string
+	CategoryID  *uint64        // The primary category, posts of its subcategories are listed in it too
+	PublishedAt *time.Time     // When a scheduled post goes public, nil for drafts
+	CreatedAt   time.Time      `gorm:"<-create"`
+	DeletedAt   gorm.DeletedAt // Set while the post is in the trash, queries leave it out unless Unscoped
+	UserID      string
 }
 
 func (e *Post) EntityName() string {
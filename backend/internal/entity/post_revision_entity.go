@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+// PostRevision is one version of the title and content of a post, numbered from 1 per post.
+// The revision with the highest number is the current version.
+type PostRevision struct {
+	ID           uint64 `gorm:"primaryKey"`
+	PostID       uint64
+	Number       int
+	Title        string
+	Content      string
+	UserID       *string   // Who wrote this version, nil once that user is deleted
+	RestoredFrom *int      // Number of the revision this one restored
+	CreatedAt    time.Time `gorm:"<-create"`
+}
+
+func (e *PostRevision) EntityName() string {
+	return "post revision"
+}
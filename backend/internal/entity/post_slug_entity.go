@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+// PostSlug is a previous slug of a post, kept so links to it still resolve after the post is renamed
+type PostSlug struct {
+	Slug      string `gorm:"primaryKey"`
+	PostID    uint64
+	CreatedAt time.Time `gorm:"<-create"`
+}
+
+func (e *PostSlug) EntityName() string {
+	return "post slug"
+}
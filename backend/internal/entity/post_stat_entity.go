@@ -0,0 +1,14 @@
+package entity
+
+// PostStat is the views of a post on a day, Date being formatted like 2006-01-02 in UTC.
+// Uniques counts the distinct visitors of the day, estimated in Redis.
+type PostStat struct {
+	PostID  uint64 `gorm:"primaryKey"`
+	Date    string `gorm:"primaryKey;type:date"`
+	Views   int64
+	Uniques int64
+}
+
+func (e *PostStat) EntityName() string {
+	return "post stat"
+}
@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+type PostReaction struct {
+	PostID    uint64    `gorm:"primaryKey"`
+	UserID    string    `gorm:"primaryKey"`
+	Kind      string    `gorm:"primaryKey"`
+	CreatedAt time.Time `gorm:"<-create"`
+}
+
+func (e *PostReaction) EntityName() string {
+	return "post reaction"
+}
+
+// PostReactionCount is the number of reactions of a kind on a post, as last reconciled from PostReaction
+type PostReactionCount struct {
+	PostID uint64 `gorm:"primaryKey"`
+	Kind   string `gorm:"primaryKey"`
+	Count  int64
+}
+
+func (e *PostReactionCount) EntityName() string {
+	return "post reaction count"
+}
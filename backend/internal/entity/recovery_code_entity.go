@@ -0,0 +1,15 @@
+package entity
+
+import "time"
+
+type RecoveryCode struct {
+	ID        uint64 `gorm:"primaryKey"`
+	UserID    string
+	CodeHash  string
+	UsedAt    *time.Time
+	CreatedAt time.Time `gorm:"<-create"`
+}
+
+func (e *RecoveryCode) EntityName() string {
+	return "recovery code"
+}
@@ -0,0 +1,19 @@
+package entity
+
+import "time"
+
+// Session is a single login of a user on a device. Sessions are stored in Redis, not in Postgres.
+type Session struct {
+	ID               string    `json:"id"`
+	UserID           string    `json:"user_id"`
+	UserAgent        string    `json:"user_agent"`
+	IPAddress        string    `json:"ip_address"`
+	RefreshTokenHash string    `json:"refresh_token_hash"`
+	CreatedAt        time.Time `json:"created_at"`
+	LastSeenAt       time.Time `json:"last_seen_at"`
+	ExpiresAt        time.Time `json:"expires_at"`
+}
+
+func (e *Session) EntityName() string {
+	return "session"
+}
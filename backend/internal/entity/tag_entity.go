@@ -0,0 +1,23 @@
+package entity
+
+import "time"
+
+type Tag struct {
+	ID        uint64 `gorm:"primaryKey"`
+	Name      string // As first written, tags written differently but with the same slug are the same tag
+	Slug      string
+	CreatedAt time.Time `gorm:"<-create"`
+}
+
+func (e *Tag) EntityName() string {
+	return "tag"
+}
+
+type PostTag struct {
+	PostID uint64 `gorm:"primaryKey"`
+	TagID  uint64 `gorm:"primaryKey"`
+}
+
+func (e *PostTag) EntityName() string {
+	return "post tag"
+}
@@ -5,12 +5,18 @@ import (
 )
 
 type User struct {
-	ID        string `gorm:"primaryKey"`
-	Name      string
-	Email     string
-	Password  string
-	CreatedAt time.Time
-	Posts     []Post
+	ID               string `gorm:"primaryKey"`
+	Name             string
+	Email            string
+	Password         string
+	Role             string
+	VerifiedAt       *time.Time
+	Locale           string     // Empty when the user has no preference
+	TOTPSecret       *string    `gorm:"column:totp_secret"`
+	TOTPEnabledAt    *time.Time `gorm:"column:totp_enabled_at"` // Nil until the secret is confirmed with a code
+	TrustedCommenter bool       // Comments of trusted commenters are approved without a spam check
+	CreatedAt        time.Time
+	Posts            []Post
 }
 
 func (e *User) EntityName() string {
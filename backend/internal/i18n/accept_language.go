@@ -0,0 +1,51 @@
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParseAcceptLanguage returns the language tags of an Accept-Language header, most preferred first.
+// Tags with q=0 and the * wildcard are left out.
+func ParseAcceptLanguage(header string) []string {
+	type weightedTag struct {
+		tag    string
+		weight float64
+	}
+
+	var weightedTags []weightedTag
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		weight := 1.0
+		if q, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+			weight = parsed
+		}
+		if weight <= 0 {
+			continue
+		}
+
+		weightedTags = append(weightedTags, weightedTag{tag: tag, weight: weight})
+	}
+
+	// Stable, so tags of the same weight keep the order of the header
+	sort.SliceStable(weightedTags, func(i, j int) bool {
+		return weightedTags[i].weight > weightedTags[j].weight
+	})
+
+	tags := make([]string, len(weightedTags))
+	for i, weightedTag := range weightedTags {
+		tags[i] = weightedTag.tag
+	}
+
+	return tags
+}
@@ -0,0 +1,137 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+// DEFAULT_LOCALE is used when the client asks for no supported locale, and for keys missing in a locale
+const DEFAULT_LOCALE = "en"
+
+// Default is the catalog of the embedded locales/*.json files
+var Default = mustLoadDefault()
+
+// Catalog holds message templates by locale and key. Keys look like error.USER_NOT_FOUND or
+// validation.required, and templates refer to their parameters as {name}.
+type Catalog struct {
+	messages map[string]map[string]string
+}
+
+// NewCatalog loads every <locale>.json file of dir in fsys, each holding a flat object of key to template
+func NewCatalog(fsys fs.FS, dir string) (*Catalog, error) {
+	paths, err := fs.Glob(fsys, dir+"/*.json")
+	if err != nil {
+		return nil, err
+	}
+
+	catalog := &Catalog{messages: make(map[string]map[string]string, len(paths))}
+	for _, path := range paths {
+		content, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return nil, err
+		}
+
+		messages := make(map[string]string)
+		if err := json.Unmarshal(content, &messages); err != nil {
+			return nil, fmt.Errorf("loading %s: %w", path, err)
+		}
+
+		catalog.messages[strings.TrimSuffix(filepath.Base(path), ".json")] = messages
+	}
+
+	if _, ok := catalog.messages[DEFAULT_LOCALE]; !ok {
+		return nil, fmt.Errorf("default locale %s not found in %s", DEFAULT_LOCALE, dir)
+	}
+
+	return catalog, nil
+}
+
+func mustLoadDefault() *Catalog {
+	catalog, err := NewCatalog(localeFiles, "locales")
+	if err != nil {
+		panic(err)
+	}
+
+	return catalog
+}
+
+// Locales returns the supported locales, sorted
+func (c *Catalog) Locales() []string {
+	locales := make([]string, 0, len(c.messages))
+	for locale := range c.messages {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+
+	return locales
+}
+
+func (c *Catalog) Supports(locale string) bool {
+	_, ok := c.messages[locale]
+
+	return ok
+}
+
+// Keys returns every key of locale
+func (c *Catalog) Keys(locale string) []string {
+	keys := make([]string, 0, len(c.messages[locale]))
+	for key := range c.messages[locale] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Has tells if key is translated in locale, without falling back to the default locale
+func (c *Catalog) Has(locale string, key string) bool {
+	_, ok := c.messages[locale][key]
+
+	return ok
+}
+
+// Translate fills the template of key in locale with params. Keys missing in locale are taken from
+// the default locale, and false is returned when no locale has the key.
+func (c *Catalog) Translate(locale string, key string, params map[string]string) (string, bool) {
+	template, ok := c.messages[locale][key]
+	if !ok {
+		if template, ok = c.messages[DEFAULT_LOCALE][key]; !ok {
+			return "", false
+		}
+	}
+
+	replacements := make([]string, 0, 2*len(params))
+	for name, value := range params {
+		replacements = append(replacements, "{"+name+"}", value)
+	}
+
+	return strings.NewReplacer(replacements...).Replace(template), true
+}
+
+// Match returns the first supported locale of tags, trying the language of a tag like id-ID too.
+// It returns the default locale when none is supported.
+func (c *Catalog) Match(tags ...string) string {
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+
+		if c.Supports(tag) {
+			return tag
+		}
+		if language, _, found := strings.Cut(tag, "-"); found && c.Supports(language) {
+			return language
+		}
+	}
+
+	return DEFAULT_LOCALE
+}
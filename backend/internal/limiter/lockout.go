@@ -0,0 +1,112 @@
+package limiter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Lockout locks a key after Threshold failures within FailureWindow. Each lockout lasts twice as long
+// as the previous one, starting at Duration and capped at MaxDuration. The lockout count is forgotten
+// after a day without lockout, or on Reset.
+type Lockout struct {
+	Redis         redis.Cmdable
+	Prefix        string
+	Threshold     int
+	FailureWindow time.Duration
+	Duration      time.Duration
+	MaxDuration   time.Duration
+}
+
+const lockoutCountTTL = 24 * time.Hour
+
+func NewLockout(rdb redis.Cmdable, prefix string, threshold int, failureWindow time.Duration,
+	duration time.Duration, maxDuration time.Duration) *Lockout {
+	return &Lockout{
+		Redis:         rdb,
+		Prefix:        prefix,
+		Threshold:     threshold,
+		FailureWindow: failureWindow,
+		Duration:      duration,
+		MaxDuration:   maxDuration,
+	}
+}
+
+// LockedFor returns how long key stays locked, zero if it isn't locked
+func (l *Lockout) LockedFor(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := l.Redis.PTTL(ctx, l.lockKey(key)).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return 0, err
+	}
+
+	// PTTL is negative when the key doesn't exist
+	if ttl < 0 {
+		return 0, nil
+	}
+
+	return ttl, nil
+}
+
+// Fail records a failure of key. It returns how long key is locked if this failure locked it, otherwise zero.
+func (l *Lockout) Fail(ctx context.Context, key string) (time.Duration, error) {
+	failuresKey := l.failuresKey(key)
+
+	var failures *redis.IntCmd
+	if _, err := l.Redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		failures = pipe.Incr(ctx, failuresKey)
+		pipe.Expire(ctx, failuresKey, l.FailureWindow)
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	if failures.Val() < int64(l.Threshold) {
+		return 0, nil
+	}
+
+	countKey := l.countKey(key)
+
+	var count *redis.IntCmd
+	if _, err := l.Redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, failuresKey)
+		count = pipe.Incr(ctx, countKey)
+		pipe.Expire(ctx, countKey, lockoutCountTTL)
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	duration := l.Duration
+	for i := int64(1); i < count.Val() && duration < l.MaxDuration; i++ {
+		duration *= 2
+	}
+	if duration > l.MaxDuration {
+		duration = l.MaxDuration
+	}
+
+	if err := l.Redis.Set(ctx, l.lockKey(key), 1, duration).Err(); err != nil {
+		return 0, err
+	}
+
+	return duration, nil
+}
+
+// Reset unlocks key and forgets its failures and previous lockouts
+func (l *Lockout) Reset(ctx context.Context, key string) error {
+	return l.Redis.Del(ctx, l.lockKey(key), l.failuresKey(key), l.countKey(key)).Err()
+}
+
+func (l *Lockout) lockKey(key string) string {
+	return fmt.Sprintf("LOCKOUT:%s:%s", l.Prefix, key)
+}
+
+func (l *Lockout) failuresKey(key string) string {
+	return fmt.Sprintf("LOCKOUT_FAILURES:%s:%s", l.Prefix, key)
+}
+
+func (l *Lockout) countKey(key string) string {
+	return fmt.Sprintf("LOCKOUT_COUNT:%s:%s", l.Prefix, key)
+}
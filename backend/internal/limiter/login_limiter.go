@@ -0,0 +1,64 @@
+package limiter
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// LoginLimiter protects logins against brute force: attempts are rate limited per IP address
+// and per email, and an email is locked out after too many wrong passwords
+type LoginLimiter struct {
+	IPWindow    *SlidingWindow
+	EmailWindow *SlidingWindow
+	Lockout     *Lockout
+}
+
+func NewLoginLimiter(IPWindow *SlidingWindow, emailWindow *SlidingWindow, lockout *Lockout) *LoginLimiter {
+	return &LoginLimiter{
+		IPWindow:    IPWindow,
+		EmailWindow: emailWindow,
+		Lockout:     lockout,
+	}
+}
+
+// LockedFor returns how long the account of email stays locked, zero if it isn't locked
+func (l *LoginLimiter) LockedFor(ctx context.Context, email string) (time.Duration, error) {
+	return l.Lockout.LockedFor(ctx, normalizeEmail(email))
+}
+
+// Allow records a login attempt. It returns zero if the attempt is allowed,
+// otherwise how long to wait before trying again.
+func (l *LoginLimiter) Allow(ctx context.Context, IPAddress string, email string) (time.Duration, error) {
+	retryAfter, err := l.IPWindow.Allow(ctx, IPAddress)
+	if err != nil || retryAfter > 0 {
+		return retryAfter, err
+	}
+
+	return l.EmailWindow.Allow(ctx, normalizeEmail(email))
+}
+
+// Fail records a wrong email or password. It returns how long the account is locked if this failure locked it.
+func (l *LoginLimiter) Fail(ctx context.Context, email string) (time.Duration, error) {
+	return l.Lockout.Fail(ctx, normalizeEmail(email))
+}
+
+// Succeed forgets the failures of email, so mistakes spread over a long time never lock the account
+func (l *LoginLimiter) Succeed(ctx context.Context, email string) error {
+	return l.Lockout.Reset(ctx, normalizeEmail(email))
+}
+
+// Unlock lifts the lockout of email and resets its rate limit
+func (l *LoginLimiter) Unlock(ctx context.Context, email string) error {
+	email = normalizeEmail(email)
+
+	if err := l.Lockout.Reset(ctx, email); err != nil {
+		return err
+	}
+
+	return l.EmailWindow.Reset(ctx, email)
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
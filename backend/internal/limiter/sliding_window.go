@@ -0,0 +1,74 @@
+package limiter
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// slidingWindowScript drops the events older than the window, then records the new event if the limit
+// isn't reached. It returns 0 when allowed, otherwise the milliseconds until the oldest event leaves the window.
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+local member = ARGV[4]
+
+redis.call("ZREMRANGEBYSCORE", key, "-inf", now - window)
+
+if redis.call("ZCARD", key) < limit then
+	redis.call("ZADD", key, now, member)
+	redis.call("PEXPIRE", key, window)
+	return 0
+end
+
+local oldest = redis.call("ZRANGE", key, 0, 0, "WITHSCORES")
+return tonumber(oldest[2]) + window - now
+`)
+
+// SlidingWindow allows Limit events per key in any period of Window. Events are kept in a Redis
+// sorted set scored by time, so unlike fixed windows a burst can't straddle two windows.
+type SlidingWindow struct {
+	Redis  redis.Cmdable
+	Prefix string
+	Limit  int
+	Window time.Duration
+}
+
+func NewSlidingWindow(rdb redis.Cmdable, prefix string, limit int, window time.Duration) *SlidingWindow {
+	return &SlidingWindow{
+		Redis:  rdb,
+		Prefix: prefix,
+		Limit:  limit,
+		Window: window,
+	}
+}
+
+// Allow records an event for key. It returns zero if the event is allowed,
+// otherwise how long to wait before the next event is allowed.
+func (w *SlidingWindow) Allow(ctx context.Context, key string) (time.Duration, error) {
+	now := time.Now()
+
+	// The member only has to be unique, two events in the same millisecond are still two events
+	member := fmt.Sprintf("%d", now.UnixNano())
+
+	retryAfter, err := slidingWindowScript.Run(ctx, w.Redis, []string{w.redisKey(key)},
+		now.UnixMilli(), w.Window.Milliseconds(), w.Limit, member).Int64()
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(retryAfter) * time.Millisecond, nil
+}
+
+// Reset forgets every event of key
+func (w *SlidingWindow) Reset(ctx context.Context, key string) error {
+	return w.Redis.Del(ctx, w.redisKey(key)).Err()
+}
+
+func (w *SlidingWindow) redisKey(key string) string {
+	return fmt.Sprintf("RATE_LIMIT:%s:%s", w.Prefix, key)
+}
@@ -0,0 +1,49 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// FileMailer writes every message to its own .eml file in Dir and logs the recipient,
+// so links in the messages can be opened without an SMTP server
+type FileMailer struct {
+	Dir   string
+	From  string
+	count atomic.Int64
+}
+
+func NewFileMailer(dir string, from string) *FileMailer {
+	return &FileMailer{
+		Dir:  dir,
+		From: from,
+	}
+}
+
+func (m *FileMailer) Send(ctx context.Context, message *Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return err
+	}
+
+	// The counter keeps file names unique and ordered within the same nanosecond
+	name := fmt.Sprintf("%d-%06d.eml", time.Now().UnixNano(), m.count.Add(1))
+	path := filepath.Join(m.Dir, name)
+
+	if err := os.WriteFile(path, FormatMessage(m.From, message), 0o644); err != nil {
+		return err
+	}
+
+	log.Printf("mail %q to %s written to %s", message.Subject, strings.Join(message.To, ", "), path)
+
+	return nil
+}
@@ -0,0 +1,16 @@
+package mail
+
+import "context"
+
+// Message is a plain text email
+type Message struct {
+	To      []string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers messages. SMTPMailer sends real emails, FileMailer writes them to disk
+// for local development and tests.
+type Mailer interface {
+	Send(ctx context.Context, message *Message) error
+}
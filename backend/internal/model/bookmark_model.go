@@ -0,0 +1,66 @@
+package model
+
+import "time"
+
+// BookmarkRequest saves or unsaves a post for UserID
+type BookmarkRequest struct {
+	PostID uint64 `validate:"required,min=1"`
+	UserID string `validate:"required"`
+}
+
+type ReadingListResponse struct {
+	ID        uint64    `json:"id"`
+	Name      string    `json:"name"`
+	PostCount int64     `json:"post_count"`
+	CreatedAt time.Time `json:"created_at"`
+
+	Posts []ReadingListItemResponse `json:"posts,omitempty" gorm:"-"` // Only set when a single list is read
+}
+
+type ReadingListItemResponse struct {
+	Position int       `json:"position"`
+	PostID   uint64    `json:"post_id"`
+	Title    string    `json:"title"`
+	Slug     string    `json:"slug"`
+	Author   string    `json:"author"`
+	AddedAt  time.Time `json:"added_at"`
+}
+
+type ReadingListCreateRequest struct {
+	Name   string `json:"name" validate:"required,max=100"`
+	UserID string `json:"-" validate:"required"`
+}
+
+type ReadingListUpdateRequest struct {
+	ID     uint64 `json:"-" validate:"required,min=1"`
+	Name   string `json:"name" validate:"required,max=100"`
+	UserID string `json:"-" validate:"required"`
+}
+
+// ReadingListGetRequest gets or deletes a reading list of UserID
+type ReadingListGetRequest struct {
+	ID     uint64 `validate:"required,min=1"`
+	UserID string `validate:"required"`
+}
+
+// ReadingListItemAddRequest adds a published post to a reading list at Position, or at the end when Position
+// is 0 or past the end. A post already in the list is moved to Position.
+type ReadingListItemAddRequest struct {
+	ReadingListID uint64 `json:"-" validate:"required,min=1"`
+	PostID        uint64 `json:"post_id" validate:"required,min=1"`
+	Position      int    `json:"position" validate:"min=0"`
+	UserID        string `json:"-" validate:"required"`
+}
+
+type ReadingListItemRemoveRequest struct {
+	ReadingListID uint64 `validate:"required,min=1"`
+	PostID        uint64 `validate:"required,min=1"`
+	UserID        string `validate:"required"`
+}
+
+// ReadingListReorderRequest orders the posts of a reading list like PostIDs, which must hold each published one once
+type ReadingListReorderRequest struct {
+	ReadingListID uint64   `json:"-" validate:"required,min=1"`
+	PostIDs       []uint64 `json:"post_ids" validate:"required,dive,min=1"`
+	UserID        string   `json:"-" validate:"required"`
+}
@@ -0,0 +1,39 @@
+package model
+
+type CategoryResponse struct {
+	ID       uint64  `json:"id"`
+	Name     string  `json:"name"`
+	Slug     string  `json:"slug"`
+	ParentID *uint64 `json:"parent_id"`
+
+	// The categories above, from the top level one, when a single category is read
+	Ancestors []CategoryPathResponse `json:"ancestors,omitempty"`
+	Children  []*CategoryResponse    `json:"children"`
+}
+
+type CategoryPathResponse struct {
+	ID   uint64 `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+type CategoryGetBySlugRequest struct {
+	Slug string `validate:"required"`
+}
+
+// CategoryCreateRequest creates a top level category unless ParentID is given
+type CategoryCreateRequest struct {
+	Name     string  `json:"name" validate:"required,max=100"`
+	ParentID *uint64 `json:"parent_id"`
+}
+
+// CategoryUpdateRequest renames a category and moves it under ParentID, or to the top level when ParentID is nil
+type CategoryUpdateRequest struct {
+	ID       uint64  `json:"-" validate:"required,min=1"`
+	Name     string  `json:"name" validate:"required,max=100"`
+	ParentID *uint64 `json:"parent_id"`
+}
+
+type CategoryDeleteRequest struct {
+	ID uint64 `validate:"required,min=1"`
+}
@@ -0,0 +1,78 @@
+package model
+
+// CommentListRequest pages through the top level comments of a post, each comes with all its replies
+type CommentListRequest struct {
+	PostID   uint64 `validate:"required,min=1"`
+	Page     int
+	PageSize int
+}
+
+// CommentCreateRequest comments on a post, or replies to the comment ParentID of the same post
+type CommentCreateRequest struct {
+	PostID    uint64  `json:"-" validate:"required,min=1"`
+	ParentID  *uint64 `json:"parent_id"`
+	Content   string  `json:"content" validate:"required,max=5000"`
+	UserID    string  `json:"-" validate:"required"`
+	IPAddress string  `json:"-"`
+	UserAgent string  `json:"-"`
+}
+
+// CommentUpdateRequest edits a comment, which goes through the spam check again
+type CommentUpdateRequest struct {
+	ID        uint64 `json:"-" validate:"required,min=1"`
+	Content   string `json:"content" validate:"required,max=5000"`
+	UserID    string `json:"-" validate:"required"`
+	IPAddress string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+// CommentDeleteRequest deletes a comment written by UserID, or any comment on a post written by UserID,
+// or any comment at all if UserRole can moderate comments
+type CommentDeleteRequest struct {
+	ID       uint64 `validate:"required,min=1"`
+	UserID   string `validate:"required"`
+	UserRole string
+}
+
+type CommentResponse struct {
+	ID        uint64  `json:"id"`
+	ParentID  *uint64 `json:"parent_id"`
+	Content   string  `json:"content"`
+	Author    string  `json:"author"`
+	CreatedAt string  `json:"created_at"`
+	UpdatedAt string  `json:"updated_at"`
+	Status    string  `json:"status"`  // Threads only show approved comments, new comments may be pending
+	Deleted   bool    `json:"deleted"` // A deleted comment kept for its replies, without content or author
+
+	Replies []*CommentResponse `json:"replies" gorm:"-"`
+}
+
+// CommentQueueRequest lists the comments in Status for moderators, the pending ones when Status is empty
+type CommentQueueRequest struct {
+	Status   string `validate:"omitempty,oneof=pending approved rejected"`
+	Page     int
+	PageSize int
+}
+
+// CommentModerateRequest approves or rejects every comment in IDs at once
+type CommentModerateRequest struct {
+	IDs    []uint64 `json:"ids" validate:"required,min=1,max=100,dive,min=1"`
+	Status string   `json:"-" validate:"required,oneof=approved rejected"`
+}
+
+type CommentQueueResponse struct {
+	ID          uint64  `json:"id"`
+	PostID      uint64  `json:"post_id"`
+	PostTitle   string  `json:"post_title"`
+	ParentID    *uint64 `json:"parent_id"`
+	Content     string  `json:"content"`
+	Author      string  `json:"author"`
+	AuthorEmail string  `json:"author_email"`
+	Status      string  `json:"status"`
+	SpamReason  *string `json:"spam_reason"`
+	CreatedAt   string  `json:"created_at"`
+}
+
+type CommentModerateResponse struct {
+	Moderated int64 `json:"moderated"` // Comments whose status changed, deleted comments are skipped
+}
@@ -0,0 +1,16 @@
+package converter
+
+import (
+	"backend/internal/entity"
+	"backend/internal/model"
+)
+
+func CategoryToResponse(category *entity.Category) *model.CategoryResponse {
+	return &model.CategoryResponse{
+		ID:       category.ID,
+		Name:     category.Name,
+		Slug:     category.Slug,
+		ParentID: category.ParentID,
+		Children: []*model.CategoryResponse{},
+	}
+}
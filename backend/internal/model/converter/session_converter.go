@@ -0,0 +1,17 @@
+package converter
+
+import (
+	"backend/internal/entity"
+	"backend/internal/model"
+)
+
+func SessionToResponse(session *entity.Session, currentSessionID string) *model.SessionResponse {
+	return &model.SessionResponse{
+		ID:         session.ID,
+		UserAgent:  session.UserAgent,
+		IPAddress:  session.IPAddress,
+		CreatedAt:  session.CreatedAt,
+		LastSeenAt: session.LastSeenAt,
+		Current:    session.ID == currentSessionID,
+	}
+}
@@ -0,0 +1,17 @@
+package converter
+
+import (
+	"backend/internal/entity"
+	"backend/internal/model"
+)
+
+func UserToResponse(user *entity.User) *model.UserResponse {
+	return &model.UserResponse{
+		ID:               user.ID,
+		Name:             user.Name,
+		Email:            user.Email,
+		Role:             user.Role,
+		TrustedCommenter: user.TrustedCommenter,
+		CreatedAt:        user.CreatedAt,
+	}
+}
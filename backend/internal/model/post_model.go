@@ -1,33 +1,70 @@
 package model
 
-import "strings"
+import (
+	"strings"
+	"time"
+)
 
 type PostListRequest struct {
 	Page       int
 	PageSize   int
 	UserID     string
 	TitleQuery string
+	Status     string `validate:"omitempty,oneof=draft scheduled published archived"` // Every status when empty
+	Trashed    bool   // Lists the posts in the trash instead, from the last deleted
+	Tags       []string
+	TagMatch   string `validate:"omitempty,oneof=any all"` // Posts with any of the Tags when empty
+	Category   string // Slug of a category, its subcategories are included
+	// Lists the posts BookmarkedBy bookmarked instead, from the last bookmarked
+	BookmarkedBy string
+	ViewerID     string // The authenticated user, if any, whose bookmarks are flagged on the posts
 }
 
+// PostCreateRequest creates a draft unless another Status is given.
+// A scheduled post needs a PublishedAt in the future, the scheduler publishes it at that time.
 type PostCreateRequest struct {
-	Title    string `json:"title" validate:"required"`
-	Content  string `json:"content" validate:"required"`
-	AuthorID string `json:"-" validate:"required"`
+	Title       string     `json:"title" validate:"required"`
+	Content     string     `json:"content" validate:"required"`
+	Status      string     `json:"status" validate:"omitempty,oneof=draft scheduled published"`
+	PublishedAt *time.Time `json:"published_at"`
+	Tags        []string   `json:"tags" validate:"max=10,dive,max=50"`
+	CategoryID  *uint64    `json:"category_id"`
+	AuthorID    string     `json:"-" validate:"required"`
 }
 
+// PostUpdateRequest keeps the current status of the post when Status is empty, and its current
+// tags or category when Tags or CategoryID is left out. A CategoryID of 0 removes the category.
 type PostUpdateRequest struct {
-	ID       uint64 `json:"-" validate:"required,min=1"`
-	Title    string `json:"title" validate:"required"`
-	Content  string `json:"content" validate:"required"`
-	AuthorID string `json:"-" validate:"required"`
+	ID          uint64     `json:"-" validate:"required,min=1"`
+	Title       string     `json:"title" validate:"required"`
+	Content     string     `json:"content" validate:"required"`
+	Status      string     `json:"status" validate:"omitempty,oneof=draft scheduled published archived"`
+	PublishedAt *time.Time `json:"published_at"`
+	Tags        []string   `json:"tags" validate:"max=10,dive,max=50"`
+	CategoryID  *uint64    `json:"category_id"`
+	AuthorID    string     `json:"-" validate:"required"`
+	UserRole    string     `json:"-"`
 }
 
 type PostResponse struct {
-	ID        uint64 `json:"id"`
-	Title     string `json:"title"`
-	Content   string `json:"content"`
-	CreatedAt string `json:"created_at"`
-	Author    string `json:"author"`
+	ID          uint64  `json:"id"`
+	Title       string  `json:"title"`
+	Slug        string  `json:"slug"`
+	Content     string  `json:"content"`
+	Status      string  `json:"status"`
+	PublishedAt *string `json:"published_at"`
+	CreatedAt   string  `json:"created_at"`
+	DeletedAt   *string `json:"deleted_at,omitempty"` // Only set for posts in the trash
+	Author      string  `json:"author"`
+
+	CategoryID   *uint64 `json:"category_id"`
+	CategoryName *string `json:"category_name"`
+	CategorySlug *string `json:"category_slug"`
+	CommentCount int64   `json:"comment_count"` // Deleted comments kept for their replies are not counted
+
+	Tags       []TagResponse    `json:"tags" gorm:"-"`
+	Reactions  map[string]int64 `json:"reactions" gorm:"-"`            // Count of every reaction kind
+	Bookmarked *bool            `json:"bookmarked,omitempty" gorm:"-"` // Only set for authenticated requests
 }
 
 // GetContentSummary returns summary of the Content by returning first 50 words
@@ -45,10 +82,78 @@ func (e *PostResponse) GetContentSummary() string {
 }
 
 type PostGetByIDRequest struct {
-	ID uint64
+	ID       uint64
+	ViewerID string
+}
+
+// PostGetBySlugRequest finds a post by its current slug or by one of its previous slugs
+type PostGetBySlugRequest struct {
+	Slug     string `validate:"required"`
+	ViewerID string
+}
+
+// PostGetEditableRequest gets a post in any status, if UserID wrote it or UserRole can edit every post
+type PostGetEditableRequest struct {
+	ID       uint64 `validate:"required,min=1"`
+	UserID   string `validate:"required"`
+	UserRole string
 }
 
+// PostDeleteRequest moves a post to the trash, PostRestoreRequest takes it back out
 type PostDeleteRequest struct {
-	ID     uint64 `validate:"required,min=1"`
-	UserID string `validate:"required"`
+	ID       uint64 `validate:"required,min=1"`
+	UserID   string `validate:"required"`
+	UserRole string
+}
+
+type PostRestoreRequest struct {
+	ID       uint64 `validate:"required,min=1"`
+	UserID   string `validate:"required"`
+	UserRole string
+}
+
+type PostRevisionListRequest struct {
+	PostID   uint64 `validate:"required,min=1"`
+	UserID   string `validate:"required"`
+	UserRole string
+}
+
+// PostRevisionGetRequest gets or restores the revision Number of a post
+type PostRevisionGetRequest struct {
+	PostID   uint64 `validate:"required,min=1"`
+	Number   int    `validate:"required,min=1"`
+	UserID   string `validate:"required"`
+	UserRole string
+}
+
+type PostRevisionDiffRequest struct {
+	PostID   uint64 `validate:"required,min=1"`
+	From     int    `validate:"required,min=1"`
+	To       int    `validate:"required,min=1"`
+	Mode     string `validate:"omitempty,oneof=line word"` // Compares lines when empty
+	UserID   string `validate:"required"`
+	UserRole string
+}
+
+type PostRevisionResponse struct {
+	Number       int    `json:"number"`
+	Title        string `json:"title"`
+	Content      string `json:"content,omitempty"` // Left out of revision lists
+	Author       string `json:"author"`
+	RestoredFrom *int   `json:"restored_from,omitempty"`
+	CreatedAt    string `json:"created_at"`
+}
+
+// DiffChunk is a run of text that is equal in both revisions, or only in the older (delete) or newer one (insert)
+type DiffChunk struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+type PostRevisionDiffResponse struct {
+	From    int         `json:"from"`
+	To      int         `json:"to"`
+	Mode    string      `json:"mode"`
+	Title   []DiffChunk `json:"title"`
+	Content []DiffChunk `json:"content"`
 }
@@ -0,0 +1,35 @@
+package model
+
+// PostViewRequest records a view of a published post. The visitor is UserID, or the hash of IPAddress
+// and UserAgent for guests.
+type PostViewRequest struct {
+	PostID    uint64 `validate:"required,min=1"`
+	UserID    string
+	IPAddress string
+	UserAgent string
+}
+
+// PostStatsRequest gets the daily stats of a post from the day From to the day To (UTC), both included,
+// if UserID wrote the post or UserRole can edit every post. To defaults to today and From to 29 days before To.
+type PostStatsRequest struct {
+	PostID   uint64 `validate:"required,min=1"`
+	From     string `validate:"omitempty,datetime=2006-01-02"`
+	To       string `validate:"omitempty,datetime=2006-01-02"`
+	UserID   string `validate:"required"`
+	UserRole string
+}
+
+type PostStatsResponse struct {
+	PostID uint64                 `json:"post_id"`
+	From   string                 `json:"from"`
+	To     string                 `json:"to"`
+	Views  int64                  `json:"views"` // Views over the whole range
+	Days   []PostDayStatsResponse `json:"days"`  // Every day of the range, days without views included
+}
+
+// PostDayStatsResponse is the views of a day, Uniques being the estimated count of distinct visitors
+type PostDayStatsResponse struct {
+	Date    string `json:"date"`
+	Views   int64  `json:"views"`
+	Uniques int64  `json:"uniques"`
+}
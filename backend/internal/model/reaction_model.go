@@ -0,0 +1,14 @@
+package model
+
+// ReactionToggleRequest adds the reaction Kind of the user to a post, or removes it if the user already reacted with it
+type ReactionToggleRequest struct {
+	PostID uint64 `validate:"required,min=1"`
+	Kind   string `validate:"required,oneof=like heart laugh wow sad clap"`
+	UserID string `validate:"required"`
+}
+
+type ReactionToggleResponse struct {
+	Kind      string           `json:"kind"`
+	Reacted   bool             `json:"reacted"` // Whether the user has the reaction on the post now
+	Reactions map[string]int64 `json:"reactions"`
+}
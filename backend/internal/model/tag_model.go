@@ -0,0 +1,11 @@
+package model
+
+type TagResponse struct {
+	Name      string `json:"name"`
+	Slug      string `json:"slug"`
+	PostCount int64  `json:"post_count,omitempty"` // Published posts with the tag, left out of the tags of a post
+}
+
+type TagGetBySlugRequest struct {
+	Slug string `validate:"required"`
+}
@@ -9,25 +9,144 @@ type RegisterUserRequest struct {
 }
 
 type LoginUserRequest struct {
-	Email    string `json:"email" validate:"email,required"`
-	Password string `json:"password" validate:"required"`
+	Email     string `json:"email" validate:"email,required"`
+	Password  string `json:"password" validate:"required"`
+	UserAgent string `json:"-"`
+	IPAddress string `json:"-"`
 }
 
 type TokenData struct {
-	AccessToken  string    `json:"access_token"`
+	AccessToken  string    `json:"access_token,omitempty"`
 	RefreshToken string    `json:"-"`
 	RefreshExpAt time.Time `json:"-"`
+	MFARequired  bool      `json:"mfa_required,omitempty"` // Set instead of the tokens when the user has two-factor authentication
+	MFAToken     string    `json:"mfa_token,omitempty"`
 }
 
 type UserAuthData struct {
-	UserID    string
-	UserEmail string
-	UserName  string
+	UserID     string
+	UserEmail  string
+	UserName   string
+	UserRole   string
+	UserLocale string
+	SessionID  string
 }
 
 type CurrentUser struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	CreatedAt time.Time `json:"created_at"`
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Email       string     `json:"email"`
+	Role        string     `json:"role"`
+	VerifiedAt  *time.Time `json:"verified_at"`
+	TOTPEnabled bool       `json:"totp_enabled"`
+	Locale      string     `json:"locale"`
+	CreatedAt   time.Time  `json:"created_at"`
+	SessionID   string     `json:"-"`
+}
+
+type UserListRequest struct {
+	Page     int
+	PageSize int
+	Role     string
+}
+
+type UserResponse struct {
+	ID               string    `json:"id"`
+	Name             string    `json:"name"`
+	Email            string    `json:"email"`
+	Role             string    `json:"role"`
+	TrustedCommenter bool      `json:"trusted_commenter"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+type UpdateUserRoleRequest struct {
+	UserID    string `json:"-" validate:"required"`
+	Role      string `json:"role" validate:"required,oneof=reader author editor admin"`
+	ChangedBy string `json:"-" validate:"required"`
+}
+
+// UpdateTrustedCommenterRequest lets the comments of a user skip the moderation queue
+type UpdateTrustedCommenterRequest struct {
+	UserID  string `json:"-" validate:"required"`
+	Trusted bool   `json:"trusted"`
+}
+
+type UnlockUserRequest struct {
+	UserID string `json:"-" validate:"required"`
+}
+
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	Current    bool      `json:"current"`
+}
+
+type SessionRevokeRequest struct {
+	ID     string `validate:"required"`
+	UserID string `validate:"required"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"email,required"`
+}
+
+type VerificationTokenData struct {
+	TokenID string
+	UserID  string
+	Email   string
+}
+
+type ChangePasswordRequest struct {
+	UserID      string `json:"-" validate:"required"`
+	SessionID   string `json:"-" validate:"required"`
+	OldPassword string `json:"old_password" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,min=5"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"email,required"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,min=5"`
+}
+
+type LoginMFARequest struct {
+	MFAToken  string `json:"mfa_token" validate:"required"`
+	Code      string `json:"code" validate:"required"` // A TOTP code or an unused recovery code
+	UserAgent string `json:"-"`
+	IPAddress string `json:"-"`
+}
+
+type TOTPEnrollmentResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+type TOTPCodeRequest struct {
+	UserID string `json:"-" validate:"required"`
+	Code   string `json:"code" validate:"required"`
+}
+
+type TOTPDisableRequest struct {
+	UserID   string `json:"-" validate:"required"`
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type UpdateLocaleRequest struct {
+	UserID string `json:"-" validate:"required"`
+	Locale string `json:"locale"` // Empty to follow the Accept-Language header again
 }
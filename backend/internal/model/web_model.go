@@ -7,7 +7,26 @@ type DataResponse[T any] struct {
 }
 
 type MessagesResponse struct {
-	Code     int      `json:"code"`
-	Status   string   `json:"status"`
-	Messages []string `json:"messages"`
+	Code      int      `json:"code"`
+	Status    string   `json:"status"`
+	ErrorCode string   `json:"error_code,omitempty"` // Stable code of the error, unlike the messages
+	Messages  []string `json:"messages"`
+}
+
+// ProblemResponse is an RFC 7807 problem, sent to clients accepting application/problem+json
+type ProblemResponse struct {
+	Type     string              `json:"type"`
+	Title    string              `json:"title"`
+	Status   int                 `json:"status"`
+	Detail   string              `json:"detail,omitempty"`
+	Instance string              `json:"instance,omitempty"`
+	Code     string              `json:"code"`             // Same as MessagesResponse.ErrorCode
+	Errors   []ProblemFieldError `json:"errors,omitempty"` // Set when the request fails validation
+}
+
+type ProblemFieldError struct {
+	Field  string `json:"field"` // JSON path of the field in the request, like tags[0].name
+	Rule   string `json:"rule"`
+	Param  string `json:"param,omitempty"`
+	Detail string `json:"detail"`
 }
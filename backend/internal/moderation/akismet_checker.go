@@ -0,0 +1,71 @@
+package moderation
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// AkismetChecker checks comments with the comment-check call of the Akismet API,
+// or of any service answering it the same way
+type AkismetChecker struct {
+	Endpoint string // Base URL of the API, like https://rest.akismet.com
+	APIKey   string
+	Blog     string // URL of the blog the comments are posted on
+	Client   *http.Client
+}
+
+func NewAkismetChecker(endpoint string, apiKey string, blog string) *AkismetChecker {
+	return &AkismetChecker{
+		Endpoint: strings.TrimSuffix(endpoint, "/"),
+		APIKey:   apiKey,
+		Blog:     blog,
+		Client:   &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Check answers "true" for spam and "false" for ham, anything else is an error explained by the debug header
+func (c *AkismetChecker) Check(ctx context.Context, submission *Submission) (*Verdict, error) {
+	form := url.Values{
+		"api_key":              {c.APIKey},
+		"blog":                 {c.Blog},
+		"user_ip":              {submission.IPAddress},
+		"user_agent":           {submission.UserAgent},
+		"comment_type":         {"comment"},
+		"comment_author":       {submission.AuthorName},
+		"comment_author_email": {submission.AuthorEmail},
+		"comment_content":      {submission.Content},
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, c.Endpoint+"/1.1/comment-check",
+		strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	response, err := c.Client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, 1024))
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.TrimSpace(string(body)) {
+	case "true":
+		return &Verdict{Spam: true, Reason: "flagged by akismet"}, nil
+	case "false":
+		return &Verdict{}, nil
+	}
+
+	return nil, fmt.Errorf("akismet comment-check failed with status %d: %s", response.StatusCode,
+		response.Header.Get("X-akismet-debug-help"))
+}
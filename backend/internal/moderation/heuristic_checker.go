@@ -0,0 +1,46 @@
+package moderation
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var linkPattern = regexp.MustCompile(`(?i)https?://|www\.`)
+
+// HeuristicChecker flags comments with more than MaxLinks links, or containing a word of Blocklist
+// in their content, author name or email
+type HeuristicChecker struct {
+	MaxLinks  int
+	Blocklist []string
+}
+
+func NewHeuristicChecker(maxLinks int, blocklist []string) *HeuristicChecker {
+	normalized := make([]string, 0, len(blocklist))
+	for _, word := range blocklist {
+		if word = strings.ToLower(strings.TrimSpace(word)); len(word) > 0 {
+			normalized = append(normalized, word)
+		}
+	}
+
+	return &HeuristicChecker{
+		MaxLinks:  maxLinks,
+		Blocklist: normalized,
+	}
+}
+
+func (c *HeuristicChecker) Check(ctx context.Context, submission *Submission) (*Verdict, error) {
+	if links := len(linkPattern.FindAllStringIndex(submission.Content, -1)); links > c.MaxLinks {
+		return &Verdict{Spam: true, Reason: fmt.Sprintf("%d links, at most %d allowed", links, c.MaxLinks)}, nil
+	}
+
+	text := strings.ToLower(strings.Join([]string{submission.Content, submission.AuthorName, submission.AuthorEmail}, "\n"))
+	for _, word := range c.Blocklist {
+		if strings.Contains(text, word) {
+			return &Verdict{Spam: true, Reason: fmt.Sprintf("blocked word %q", word)}, nil
+		}
+	}
+
+	return &Verdict{}, nil
+}
@@ -0,0 +1,24 @@
+package moderation
+
+import "context"
+
+// Submission is a comment as seen by a SpamChecker
+type Submission struct {
+	Content     string
+	AuthorName  string
+	AuthorEmail string
+	IPAddress   string
+	UserAgent   string
+}
+
+// Verdict tells if a submission looks like spam, and why
+type Verdict struct {
+	Spam   bool
+	Reason string
+}
+
+// SpamChecker decides whether a new comment is held for moderation. HeuristicChecker works offline,
+// AkismetChecker asks an Akismet compatible service.
+type SpamChecker interface {
+	Check(ctx context.Context, submission *Submission) (*Verdict, error)
+}
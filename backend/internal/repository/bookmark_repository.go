@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"backend/internal/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BookmarkRepository struct {
+	Repository[entity.Bookmark]
+}
+
+func NewBookmarkRepository() *BookmarkRepository {
+	return &BookmarkRepository{}
+}
+
+// Add bookmarks a post, bookmarking it again keeps the time it was first bookmarked
+func (r *BookmarkRepository) Add(tx *gorm.DB, bookmark *entity.Bookmark) error {
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(bookmark).Error
+}
+
+func (r *BookmarkRepository) Remove(tx *gorm.DB, userID string, postID uint64) error {
+	return tx.Where("user_id = ? AND post_id = ?", userID, postID).Delete(new(entity.Bookmark)).Error
+}
+
+// FindBookmarkedPostIDs returns which posts of postIDs userID bookmarked
+func (r *BookmarkRepository) FindBookmarkedPostIDs(tx *gorm.DB, userID string, postIDs []uint64) (map[uint64]bool, error) {
+	var bookmarked []uint64
+	err := tx.Model(new(entity.Bookmark)).
+		Where("user_id = ? AND post_id IN ?", userID, postIDs).
+		Pluck("post_id", &bookmarked).Error
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[uint64]bool, len(bookmarked))
+	for _, postID := range bookmarked {
+		found[postID] = true
+	}
+
+	return found, nil
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"backend/internal/constant"
+	"backend/internal/entity"
+	"backend/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+type CategoryRepository struct {
+	Repository[entity.Category]
+}
+
+func NewCategoryRepository() *CategoryRepository {
+	return &CategoryRepository{}
+}
+
+// LockTree serializes the changes to the tree until tx ends, so two concurrent moves can't make a cycle together
+func (r *CategoryRepository) LockTree(tx *gorm.DB) error {
+	return tx.Exec("SELECT pg_advisory_xact_lock(?)", constant.LOCK_CATEGORY_TREE).Error
+}
+
+// NextAvailableSlug returns base, or base with the lowest free suffix, that no other category uses.
+// It locks base until tx ends, like PostSlugRepository.NextAvailable.
+func (r *CategoryRepository) NextAvailableSlug(tx *gorm.DB, base string, ID uint64) (string, error) {
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(?, hashtext(?))", constant.LOCK_CATEGORY_SLUG, base).Error; err != nil {
+		return "", err
+	}
+
+	var taken []string
+	err := tx.Model(new(entity.Category)).
+		Where("id <> ? AND slug LIKE ?", ID, utils.SlugSearchPrefix(base)+"%").
+		Pluck("slug", &taken).Error
+	if err != nil {
+		return "", err
+	}
+
+	return utils.UniqueSlug(base, taken), nil
+}
+
+func (r *CategoryRepository) FindBySlug(tx *gorm.DB, category *entity.Category, slug string) error {
+	return tx.Where("slug = ?", slug).First(category).Error
+}
+
+// ListAll lists every category by name, the use case builds the tree from their parents
+func (r *CategoryRepository) ListAll(tx *gorm.DB) ([]entity.Category, error) {
+	var categories []entity.Category
+	err := tx.Order("name asc, id asc").Find(&categories).Error
+
+	return categories, err
+}
+
+// ListChildren lists the direct subcategories of a category by name
+func (r *CategoryRepository) ListChildren(tx *gorm.DB, ID uint64) ([]entity.Category, error) {
+	var categories []entity.Category
+	err := tx.Where("parent_id = ?", ID).Order("name asc, id asc").Find(&categories).Error
+
+	return categories, err
+}
+
+func (r *CategoryRepository) CountChildren(tx *gorm.DB, ID uint64) (int64, error) {
+	var count int64
+	err := tx.Model(new(entity.Category)).Where("parent_id = ?", ID).Count(&count).Error
+
+	return count, err
+}
+
+// Ancestors lists the categories above a category, from the top level one down to its parent
+func (r *CategoryRepository) Ancestors(tx *gorm.DB, ID uint64) ([]entity.Category, error) {
+	var categories []entity.Category
+	err := tx.Raw(`WITH RECURSIVE ancestors AS (
+			SELECT categories.*, 0 AS depth FROM categories WHERE id = (SELECT parent_id FROM categories WHERE id = ?)
+			UNION ALL
+			SELECT categories.*, ancestors.depth + 1 FROM categories
+			INNER JOIN ancestors ON categories.id = ancestors.parent_id
+		)
+		SELECT id, name, slug, parent_id, created_at FROM ancestors ORDER BY depth desc`, ID).
+		Scan(&categories).Error
+
+	return categories, err
+}
+
+// InSubtree tells if the category ID is rootID or one of its descendants
+func (r *CategoryRepository) InSubtree(tx *gorm.DB, rootID uint64, ID uint64) (bool, error) {
+	var found bool
+	err := tx.Raw(`WITH RECURSIVE subtree AS (
+			SELECT id FROM categories WHERE id = ?
+			UNION ALL
+			SELECT categories.id FROM categories INNER JOIN subtree ON categories.parent_id = subtree.id
+		)
+		SELECT EXISTS (SELECT 1 FROM subtree WHERE id = ?)`, rootID, ID).
+		Scan(&found).Error
+
+	return found, err
+}
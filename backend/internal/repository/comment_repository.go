@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"backend/internal/constant"
+	"backend/internal/entity"
+	"backend/internal/model"
+	"backend/internal/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type CommentRepository struct {
+	Repository[entity.Comment]
+}
+
+func NewCommentRepository() *CommentRepository {
+	return &CommentRepository{}
+}
+
+// ListThreads lists a page of the approved top level comments of a post, from the oldest, followed by
+// all their approved replies. Replies to comments that are not approved are left out with them.
+func (r *CommentRepository) ListThreads(tx *gorm.DB, postID uint64, page int, pageSize int) ([]model.CommentResponse, error) {
+	var roots []model.CommentResponse
+
+	query := r.selectWithAuthor(tx).
+		Where("comments.post_id = ? AND comments.parent_id IS NULL AND comments.status = ?",
+			postID, constant.COMMENT_STATUS_APPROVED).
+		Order("comments.id asc")
+	if page > 0 && pageSize > 0 {
+		query = query.Scopes(utils.Paginate(page, pageSize))
+	}
+	if err := query.Scan(&roots).Error; err != nil {
+		return nil, err
+	}
+	if len(roots) == 0 {
+		return roots, nil
+	}
+
+	rootIDs := make([]uint64, len(roots))
+	for i, root := range roots {
+		rootIDs[i] = root.ID
+	}
+
+	var replies []model.CommentResponse
+	err := r.selectWithAuthor(tx).
+		Where(`comments.id IN (WITH RECURSIVE thread AS (
+				SELECT id FROM comments WHERE parent_id IN ? AND status = ?
+				UNION ALL
+				SELECT comments.id FROM comments INNER JOIN thread ON comments.parent_id = thread.id
+				WHERE comments.status = ?
+			)
+			SELECT id FROM thread)`, rootIDs, constant.COMMENT_STATUS_APPROVED, constant.COMMENT_STATUS_APPROVED).
+		Order("comments.id asc").
+		Scan(&replies).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return append(roots, replies...), nil
+}
+
+// FindApproved finds an approved comment of the post that is not deleted
+func (r *CommentRepository) FindApproved(tx *gorm.DB, comment *entity.Comment, postID uint64, ID uint64) error {
+	return tx.Where("id = ? AND post_id = ? AND status = ? AND deleted_at IS NULL", ID, postID, constant.COMMENT_STATUS_APPROVED).
+		First(comment).Error
+}
+
+// FindActive finds a comment that is not deleted
+func (r *CommentRepository) FindActive(tx *gorm.DB, comment *entity.Comment, ID uint64) error {
+	return tx.Where("id = ? AND deleted_at IS NULL", ID).First(comment).Error
+}
+
+func (r *CommentRepository) CountReplies(tx *gorm.DB, ID uint64) (int64, error) {
+	var count int64
+	err := tx.Model(new(entity.Comment)).Where("parent_id = ?", ID).Count(&count).Error
+
+	return count, err
+}
+
+// Tombstone deletes the content of a comment but keeps it in its thread, for its replies
+func (r *CommentRepository) Tombstone(tx *gorm.DB, comment *entity.Comment, now time.Time) error {
+	return tx.Model(comment).Updates(map[string]any{"content": "", "deleted_at": now}).Error
+}
+
+// ListQueue lists the comments in status for moderators, from the oldest
+func (r *CommentRepository) ListQueue(tx *gorm.DB, request *model.CommentQueueRequest) ([]model.CommentQueueResponse, error) {
+	var comments []model.CommentQueueResponse
+
+	query := tx.Model(new(entity.Comment)).
+		Select(`comments.id,
+			comments.post_id,
+			posts.title as post_title,
+			comments.parent_id,
+			comments.content,
+			users.name as author,
+			users.email as author_email,
+			comments.status,
+			comments.spam_reason,
+			comments.created_at`).
+		Joins("inner join users on users.id = comments.user_id").
+		Joins("inner join posts on posts.id = comments.post_id").
+		Where("comments.status = ? AND comments.deleted_at IS NULL", request.Status).
+		Order("comments.id asc")
+	if request.Page > 0 && request.PageSize > 0 {
+		query = query.Scopes(utils.Paginate(request.Page, request.PageSize))
+	}
+
+	err := query.Scan(&comments).Error
+
+	return comments, err
+}
+
+// SetStatus moves the comments in IDs that are not deleted to status, and returns how many changed
+func (r *CommentRepository) SetStatus(tx *gorm.DB, IDs []uint64, status string) (int64, error) {
+	result := tx.Model(new(entity.Comment)).
+		Where("id IN ? AND status <> ? AND deleted_at IS NULL", IDs, status).
+		Update("status", status)
+
+	return result.RowsAffected, result.Error
+}
+
+func (r *CommentRepository) GetWithAuthor(tx *gorm.DB, comment *model.CommentResponse, ID uint64) error {
+	return r.selectWithAuthor(tx).
+		Where("comments.id = ?", ID).
+		Scan(comment).Error
+}
+
+// selectWithAuthor leaves the author of deleted comments out
+func (r *CommentRepository) selectWithAuthor(tx *gorm.DB) *gorm.DB {
+	return tx.Model(new(entity.Comment)).
+		Select(`comments.id,
+			comments.parent_id,
+			comments.content,
+			CASE WHEN comments.deleted_at IS NULL THEN users.name ELSE '' END as author,
+			comments.created_at,
+			comments.updated_at,
+			comments.status,
+			comments.deleted_at IS NOT NULL as deleted`).
+		Joins("inner join users on users.id = comments.user_id")
+}
@@ -1,10 +1,12 @@
 package repository
 
 import (
+	"backend/internal/constant"
 	"backend/internal/entity"
 	"backend/internal/model"
 	"backend/internal/utils"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -24,19 +26,50 @@ func (r *PostRepository) List(tx *gorm.DB, request *model.PostListRequest) ([]mo
 		tx = tx.Scopes(utils.Paginate(request.Page, request.PageSize))
 	}
 
-	query := tx.Model(new(entity.Post)).
-		Order("posts.id asc").
-		Select(`posts.id,
-			posts.title,
-			posts.content,
-			posts.created_at,
-			users.name as author`).
-		Joins("inner join users on users.id = posts.user_id")
+	query := r.selectWithAuthor(tx).
+		Order("posts.id asc")
+	if request.Trashed {
+		query = r.selectWithAuthor(tx.Unscoped()).
+			Where("posts.deleted_at IS NOT NULL").
+			Order("posts.deleted_at desc")
+	}
+	if len(request.BookmarkedBy) > 0 {
+		query = r.selectWithAuthor(tx).
+			Joins("inner join bookmarks on bookmarks.post_id = posts.id AND bookmarks.user_id = ?", request.BookmarkedBy).
+			Order("bookmarks.created_at desc, posts.id desc")
+	}
 
 	if len(request.UserID) > 0 {
 		query = query.Where("users.id = ?", request.UserID)
 	}
 
+	if len(request.Status) > 0 {
+		query = query.Where("posts.status = ?", request.Status)
+	}
+
+	if len(request.Tags) > 0 {
+		// A new session, the subquery must not be paginated like the query
+		taggedPosts := tx.Session(&gorm.Session{NewDB: true}).Model(new(entity.PostTag)).
+			Select("post_tags.post_id").
+			Joins("inner join tags on tags.id = post_tags.tag_id").
+			Where("tags.slug IN ?", request.Tags)
+		// With all, a post must have as many of the tags as there are
+		if request.TagMatch == constant.TAG_MATCH_ALL {
+			taggedPosts = taggedPosts.Group("post_tags.post_id").Having("count(*) = ?", len(request.Tags))
+		}
+
+		query = query.Where("posts.id IN (?)", taggedPosts)
+	}
+
+	if len(request.Category) > 0 {
+		query = query.Where(`posts.category_id IN (WITH RECURSIVE subtree AS (
+				SELECT id FROM categories WHERE slug = ?
+				UNION ALL
+				SELECT categories.id FROM categories INNER JOIN subtree ON categories.parent_id = subtree.id
+			)
+			SELECT id FROM subtree)`, request.Category)
+	}
+
 	if len(request.TitleQuery) > 0 {
 		query = query.Where("LOWER(posts.title) LIKE ?", "%"+strings.ToLower(request.TitleQuery)+"%")
 	}
@@ -53,17 +86,90 @@ func (r *PostRepository) List(tx *gorm.DB, request *model.PostListRequest) ([]mo
 }
 
 func (r *PostRepository) GetWithAuthor(tx *gorm.DB, post *model.PostResponse, ID uint64) error {
-	return tx.Model(new(entity.Post)).
+	return r.selectWithAuthor(tx).
 		Where("posts.id = ?", ID).
+		Scan(&post).Error
+}
+
+// GetPublishedWithAuthor is GetWithAuthor for guests, it leaves post empty unless the post is published
+func (r *PostRepository) GetPublishedWithAuthor(tx *gorm.DB, post *model.PostResponse, ID uint64) error {
+	return r.selectWithAuthor(tx).
+		Where("posts.id = ? and posts.status = ?", ID, constant.POST_STATUS_PUBLISHED).
+		Scan(&post).Error
+}
+
+// GetPublishedWithAuthorBySlug is GetPublishedWithAuthor for the post whose current or previous slug is slug
+func (r *PostRepository) GetPublishedWithAuthorBySlug(tx *gorm.DB, post *model.PostResponse, slug string) error {
+	return r.selectWithAuthor(tx).
+		Where("(posts.slug = ? OR posts.id = (SELECT post_id FROM post_slugs WHERE slug = ?)) and posts.status = ?",
+			slug, slug, constant.POST_STATUS_PUBLISHED).
+		Scan(&post).Error
+}
+
+func (r *PostRepository) selectWithAuthor(tx *gorm.DB) *gorm.DB {
+	return tx.Model(new(entity.Post)).
 		Select(`posts.id,
 			posts.title,
+			posts.slug,
 			posts.content,
+			posts.status,
+			posts.published_at,
 			posts.created_at,
-			users.name as author`).
+			posts.deleted_at,
+			users.name as author,
+			posts.category_id,
+			categories.name as category_name,
+			categories.slug as category_slug,
+			(SELECT count(*) FROM comments WHERE comments.post_id = posts.id AND comments.status = ?
+				AND comments.deleted_at IS NULL) as comment_count`, constant.COMMENT_STATUS_APPROVED).
 		Joins("inner join users on users.id = posts.user_id").
-		Scan(&post).Error
+		Joins("left join categories on categories.id = posts.category_id")
+}
+
+// FindPublished finds a published post that is not in the trash
+func (r *PostRepository) FindPublished(tx *gorm.DB, post *entity.Post, ID uint64) error {
+	return tx.Where("id = ? AND status = ?", ID, constant.POST_STATUS_PUBLISHED).First(post).Error
 }
 
 func (r *PostRepository) GetByIDandAuthorID(tx *gorm.DB, post *entity.Post, ID uint64, userID string) error {
 	return tx.Where("id = ? and user_id = ?", ID, userID).First(post).Error
 }
+
+// FindTrashed finds a post in the trash, written by userID unless userID is empty
+func (r *PostRepository) FindTrashed(tx *gorm.DB, post *entity.Post, ID uint64, userID string) error {
+	query := tx.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", ID)
+	if len(userID) > 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	return query.First(post).Error
+}
+
+// Restore takes post out of the trash
+func (r *PostRepository) Restore(tx *gorm.DB, post *entity.Post) error {
+	return tx.Unscoped().Model(post).Update("deleted_at", nil).Error
+}
+
+// Purge removes the posts that were moved to the trash before deletedBefore for good,
+// their slugs and revisions go with them
+func (r *PostRepository) Purge(tx *gorm.DB, deletedBefore time.Time) (int64, error) {
+	result := tx.Unscoped().Where("deleted_at < ?", deletedBefore).Delete(new(entity.Post))
+
+	return result.RowsAffected, result.Error
+}
+
+// FindBySlug finds the post whose current slug is slug, even in the trash
+func (r *PostRepository) FindBySlug(tx *gorm.DB, post *entity.Post, slug string) error {
+	return tx.Unscoped().Where("slug = ?", slug).First(post).Error
+}
+
+// PublishDue publishes every scheduled post whose published_at is not after now and returns their IDs
+func (r *PostRepository) PublishDue(tx *gorm.DB, now time.Time) ([]uint64, error) {
+	var IDs []uint64
+	err := tx.Raw(`UPDATE posts SET status = ?
+		WHERE status = ? AND published_at <= ? AND deleted_at IS NULL
+		RETURNING id`, constant.POST_STATUS_PUBLISHED, constant.POST_STATUS_SCHEDULED, now).
+		Scan(&IDs).Error
+
+	return IDs, err
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"backend/internal/entity"
+	"backend/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type PostRevisionRepository struct {
+	Repository[entity.PostRevision]
+}
+
+func NewPostRevisionRepository() *PostRevisionRepository {
+	return &PostRevisionRepository{}
+}
+
+// Record saves the current title and content of post as its next revision. The post must be saved in tx first,
+// so its row stays locked until tx ends and concurrent edits of the post get consecutive numbers.
+func (r *PostRevisionRepository) Record(tx *gorm.DB, post *entity.Post, userID string, restoredFrom *int) (*entity.PostRevision, error) {
+	var number int
+	err := tx.Model(new(entity.PostRevision)).
+		Where("post_id = ?", post.ID).
+		Select("coalesce(max(number), 0) + 1").
+		Scan(&number).Error
+	if err != nil {
+		return nil, err
+	}
+
+	revision := &entity.PostRevision{
+		PostID:       post.ID,
+		Number:       number,
+		Title:        post.Title,
+		Content:      post.Content,
+		RestoredFrom: restoredFrom,
+	}
+	if len(userID) > 0 {
+		revision.UserID = &userID
+	}
+
+	return revision, r.Repository.Save(tx, revision)
+}
+
+func (r *PostRevisionRepository) FindByNumber(tx *gorm.DB, revision *entity.PostRevision, postID uint64, number int) error {
+	return tx.First(revision, "post_id = ? AND number = ?", postID, number).Error
+}
+
+// ListWithAuthor lists the revisions of a post from the newest, without their content
+func (r *PostRevisionRepository) ListWithAuthor(tx *gorm.DB, postID uint64) ([]model.PostRevisionResponse, error) {
+	var revisions []model.PostRevisionResponse
+
+	err := r.selectWithAuthor(tx, false).
+		Where("post_revisions.post_id = ?", postID).
+		Order("post_revisions.number desc").
+		Scan(&revisions).Error
+
+	return revisions, err
+}
+
+func (r *PostRevisionRepository) GetWithAuthor(tx *gorm.DB, revision *model.PostRevisionResponse, postID uint64, number int) error {
+	return r.selectWithAuthor(tx, true).
+		Where("post_revisions.post_id = ? AND post_revisions.number = ?", postID, number).
+		Scan(revision).Error
+}
+
+// selectWithAuthor leaves the author empty for revisions whose author was deleted
+func (r *PostRevisionRepository) selectWithAuthor(tx *gorm.DB, withContent bool) *gorm.DB {
+	columns := `post_revisions.number,
+			post_revisions.title,
+			post_revisions.restored_from,
+			post_revisions.created_at,
+			coalesce(users.name, '') as author`
+	if withContent {
+		columns += ", post_revisions.content"
+	}
+
+	return tx.Model(new(entity.PostRevision)).
+		Select(columns).
+		Joins("left join users on users.id = post_revisions.user_id")
+}
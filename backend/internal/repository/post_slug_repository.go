@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"backend/internal/constant"
+	"backend/internal/entity"
+	"backend/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+type PostSlugRepository struct {
+	Repository[entity.PostSlug]
+}
+
+func NewPostSlugRepository() *PostSlugRepository {
+	return &PostSlugRepository{}
+}
+
+// NextAvailable returns base, or base with the lowest free suffix, that no other post uses now or used before.
+// It locks base until tx ends, so concurrent posts with the same title can't get the same slug.
+func (r *PostSlugRepository) NextAvailable(tx *gorm.DB, base string, postID uint64) (string, error) {
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(?, hashtext(?))", constant.LOCK_POST_SLUG, base).Error; err != nil {
+		return "", err
+	}
+
+	var taken []string
+	prefix := utils.SlugSearchPrefix(base) + "%"
+	err := tx.Raw(`SELECT slug FROM posts WHERE id <> ? AND slug LIKE ?
+		UNION SELECT slug FROM post_slugs WHERE post_id <> ? AND slug LIKE ?`,
+		postID, prefix, postID, prefix).
+		Scan(&taken).Error
+	if err != nil {
+		return "", err
+	}
+
+	return utils.UniqueSlug(base, taken), nil
+}
+
+// DeleteBySlug forgets slug as a previous slug of any post
+func (r *PostSlugRepository) DeleteBySlug(tx *gorm.DB, slug string) error {
+	return tx.Where("slug = ?", slug).Delete(new(entity.PostSlug)).Error
+}
+
+func (r *PostSlugRepository) DeleteByPostIDAndSlug(tx *gorm.DB, postID uint64, slug string) error {
+	return tx.Where("post_id = ? AND slug = ?", postID, slug).Delete(new(entity.PostSlug)).Error
+}
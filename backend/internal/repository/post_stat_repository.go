@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"backend/internal/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PostStatRepository struct {
+	Repository[entity.PostStat]
+}
+
+func NewPostStatRepository() *PostStatRepository {
+	return &PostStatRepository{}
+}
+
+// Add adds the views of stats to the stored views of their post and day, and keeps the highest unique
+// visitors, since stats holds the unique visitors of the whole day so far. Posts purged since they were
+// viewed are left out.
+func (r *PostStatRepository) Add(tx *gorm.DB, stats []entity.PostStat) error {
+	postIDs := make([]uint64, len(stats))
+	for i, stat := range stats {
+		postIDs[i] = stat.PostID
+	}
+
+	var existing []uint64
+	if err := tx.Unscoped().Model(new(entity.Post)).Where("id IN ?", postIDs).Pluck("id", &existing).Error; err != nil {
+		return err
+	}
+	found := make(map[uint64]bool, len(existing))
+	for _, postID := range existing {
+		found[postID] = true
+	}
+
+	rows := make([]entity.PostStat, 0, len(stats))
+	for _, stat := range stats {
+		if found[stat.PostID] {
+			rows = append(rows, stat)
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "post_id"}, {Name: "date"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"views":   gorm.Expr("post_stats.views + excluded.views"),
+			"uniques": gorm.Expr("GREATEST(post_stats.uniques, excluded.uniques)"),
+		}),
+	}).Create(&rows).Error
+}
+
+// ListByPostID returns the stats of a post from the day from to the day to, both included, by day
+func (r *PostStatRepository) ListByPostID(tx *gorm.DB, postID uint64, from string, to string) ([]entity.PostStat, error) {
+	var stats []entity.PostStat
+	err := tx.Model(new(entity.PostStat)).
+		Select("post_id, to_char(date, 'YYYY-MM-DD') AS date, views, uniques").
+		Where("post_id = ? AND date BETWEEN ? AND ?", postID, from, to).
+		Order("date asc").
+		Scan(&stats).Error
+
+	return stats, err
+}
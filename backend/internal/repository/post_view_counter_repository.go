@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"backend/internal/entity"
+	"backend/internal/utils"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// postStatsRetention keeps the views and visitors of a day in Redis long enough to be flushed once the day is over
+const postStatsRetention = 48 * time.Hour
+
+// PostViewCounterRepository counts the views of posts in Redis, by post and day, until they are flushed to the
+// database. Visitors are kept in a HyperLogLog per post and day to estimate the unique visitors.
+type PostViewCounterRepository struct {
+}
+
+func NewPostViewCounterRepository() *PostViewCounterRepository {
+	return &PostViewCounterRepository{}
+}
+
+// Record counts a view of visitor on a post on date, unless visitor viewed the post within window already.
+// It returns whether the view was counted.
+func (r *PostViewCounterRepository) Record(ctx context.Context, rdb redis.Cmdable, postID uint64, visitor string,
+	date string, window time.Duration) (bool, error) {
+	counted, err := rdb.SetNX(ctx, utils.GeneratePostViewerRedisKey(postID, visitor), 1, window).Result()
+	if err != nil {
+		return false, err
+	}
+
+	// A visitor is added even when the view isn't counted, the window may have started the day before
+	viewsKey := utils.GeneratePostViewsRedisKey(postID, date)
+	uniquesKey := utils.GeneratePostUniquesRedisKey(postID, date)
+	_, err = rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if counted {
+			pipe.Incr(ctx, viewsKey)
+			pipe.Expire(ctx, viewsKey, postStatsRetention)
+		}
+		pipe.PFAdd(ctx, uniquesKey, visitor)
+		pipe.Expire(ctx, uniquesKey, postStatsRetention)
+		pipe.SAdd(ctx, utils.GenerateDirtyPostStatsRedisKey(), postStatMember(entity.PostStat{PostID: postID, Date: date}))
+		return nil
+	})
+
+	return counted, err
+}
+
+// claimScript moves up to ARGV[1] members of the dirty set to the flushing set, scored with the time ARGV[2].
+// Members viewed again while they are being flushed stay dirty, so a post and day is flushed by one flush at a time.
+var claimScript = redis.NewScript(`
+local members = redis.call("SPOP", KEYS[1], ARGV[1])
+local claimed = {}
+for _, member in ipairs(members) do
+	if redis.call("ZSCORE", KEYS[2], member) then
+		redis.call("SADD", KEYS[1], member)
+	else
+		redis.call("ZADD", KEYS[2], ARGV[2], member)
+		table.insert(claimed, member)
+	end
+end
+return claimed
+`)
+
+// takeScript moves the views of KEYS[1] to the flushing views KEYS[2] and returns the flushing views,
+// which still hold the views of an earlier flush that failed
+var takeScript = redis.NewScript(`
+local views = redis.call("GETDEL", KEYS[1])
+if views then
+	redis.call("INCRBY", KEYS[2], views)
+	redis.call("EXPIRE", KEYS[2], ARGV[1])
+end
+return tonumber(redis.call("GET", KEYS[2]) or "0")
+`)
+
+// requeueScript moves the members of the flushing set scored up to ARGV[1] back to the dirty set
+var requeueScript = redis.NewScript(`
+local members = redis.call("ZRANGEBYSCORE", KEYS[1], "-inf", ARGV[1])
+for _, member in ipairs(members) do
+	redis.call("ZREM", KEYS[1], member)
+	redis.call("SADD", KEYS[2], member)
+end
+return #members
+`)
+
+// Claim takes up to count of the posts and days viewed since they were flushed, without their counts, and keeps
+// them as being flushed since now until Done or Release. Replicas claiming concurrently get different ones.
+func (r *PostViewCounterRepository) Claim(ctx context.Context, rdb redis.Cmdable, count int64, now time.Time) ([]entity.PostStat, error) {
+	members, err := claimScript.Run(ctx, rdb,
+		[]string{utils.GenerateDirtyPostStatsRedisKey(), utils.GenerateFlushingPostStatsRedisKey()},
+		count, now.UnixMilli()).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]entity.PostStat, 0, len(members))
+	for _, member := range members {
+		postID, date, found := strings.Cut(member, ":")
+		if !found {
+			continue
+		}
+		if id, err := strconv.ParseUint(postID, 10, 64); err == nil {
+			stats = append(stats, entity.PostStat{PostID: id, Date: date})
+		}
+	}
+
+	return stats, nil
+}
+
+// Take sets the views of claimed stats to the views counted since the last committed flush, and their unique
+// visitors to the ones of the day so far. The views stay in Redis until Done.
+func (r *PostViewCounterRepository) Take(ctx context.Context, rdb redis.Cmdable, stats []entity.PostStat) error {
+	views := make([]*redis.Cmd, len(stats))
+	uniques := make([]*redis.IntCmd, len(stats))
+	_, err := rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, stat := range stats {
+			views[i] = takeScript.Eval(ctx, pipe, []string{
+				utils.GeneratePostViewsRedisKey(stat.PostID, stat.Date),
+				utils.GenerateFlushingPostViewsRedisKey(stat.PostID, stat.Date),
+			}, int(postStatsRetention.Seconds()))
+			uniques[i] = pipe.PFCount(ctx, utils.GeneratePostUniquesRedisKey(stat.PostID, stat.Date))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for i := range stats {
+		stats[i].Views, _ = views[i].Int64()
+		stats[i].Uniques = uniques[i].Val()
+	}
+
+	return nil
+}
+
+// Done forgets the views of claimed stats, once they are committed to the database
+func (r *PostViewCounterRepository) Done(ctx context.Context, rdb redis.Cmdable, stats []entity.PostStat) error {
+	_, err := rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, stat := range stats {
+			pipe.Del(ctx, utils.GenerateFlushingPostViewsRedisKey(stat.PostID, stat.Date))
+			pipe.ZRem(ctx, utils.GenerateFlushingPostStatsRedisKey(), postStatMember(stat))
+		}
+		return nil
+	})
+
+	return err
+}
+
+// Release puts claimed stats back with the viewed ones, when flushing them failed. Their taken views are kept
+// and flushed with them next time.
+func (r *PostViewCounterRepository) Release(ctx context.Context, rdb redis.Cmdable, stats []entity.PostStat) error {
+	_, err := rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, stat := range stats {
+			pipe.ZRem(ctx, utils.GenerateFlushingPostStatsRedisKey(), postStatMember(stat))
+			pipe.SAdd(ctx, utils.GenerateDirtyPostStatsRedisKey(), postStatMember(stat))
+		}
+		return nil
+	})
+
+	return err
+}
+
+// Requeue releases the stats claimed before claimedBefore, left by a flush that never finished
+func (r *PostViewCounterRepository) Requeue(ctx context.Context, rdb redis.Cmdable, claimedBefore time.Time) error {
+	return requeueScript.Run(ctx, rdb,
+		[]string{utils.GenerateFlushingPostStatsRedisKey(), utils.GenerateDirtyPostStatsRedisKey()},
+		claimedBefore.UnixMilli()).Err()
+}
+
+func postStatMember(stat entity.PostStat) string {
+	return fmt.Sprintf("%d:%s", stat.PostID, stat.Date)
+}
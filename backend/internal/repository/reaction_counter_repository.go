@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"backend/internal/constant"
+	"backend/internal/utils"
+	"context"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ReactionCounterRepository keeps the reaction counts of posts in Redis, a hash per post,
+// so reading them doesn't touch the database. The posts whose counts changed are kept in a set
+// until they are reconciled with the database.
+type ReactionCounterRepository struct {
+}
+
+func NewReactionCounterRepository() *ReactionCounterRepository {
+	return &ReactionCounterRepository{}
+}
+
+// Increment adds delta to the count of kind on a post and marks the post as changed
+func (r *ReactionCounterRepository) Increment(ctx context.Context, rdb redis.Cmdable, postID uint64, kind string, delta int64) error {
+	_, err := rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HIncrBy(ctx, utils.GeneratePostReactionsRedisKey(postID), kind, delta)
+		pipe.SAdd(ctx, utils.GenerateDirtyPostReactionsRedisKey(), postID)
+		return nil
+	})
+
+	return err
+}
+
+// Get returns the counts of the posts of postIDs found in Redis by kind, and the posts that weren't found
+func (r *ReactionCounterRepository) Get(ctx context.Context, rdb redis.Cmdable, postIDs []uint64) (map[uint64]map[string]int64, []uint64, error) {
+	commands := make([]*redis.MapStringStringCmd, len(postIDs))
+	_, err := rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, postID := range postIDs {
+			commands[i] = pipe.HGetAll(ctx, utils.GeneratePostReactionsRedisKey(postID))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	counts := make(map[uint64]map[string]int64, len(postIDs))
+	var missing []uint64
+	for i, command := range commands {
+		fields := command.Val()
+		if len(fields) == 0 {
+			missing = append(missing, postIDs[i])
+			continue
+		}
+
+		counts[postIDs[i]] = make(map[string]int64, len(fields))
+		for kind, value := range fields {
+			count, _ := strconv.ParseInt(value, 10, 64)
+			counts[postIDs[i]][kind] = count
+		}
+	}
+
+	return counts, missing, nil
+}
+
+// Warm stores counts for the posts missing from Redis. Every kind is set, so posts without reactions
+// are found next time, but counts incremented in the meantime are kept. The posts are marked as changed,
+// since counts lost from Redis may not have been reconciled yet, so they are counted again soon.
+func (r *ReactionCounterRepository) Warm(ctx context.Context, rdb redis.Cmdable, counts map[uint64]map[string]int64, postIDs []uint64) error {
+	_, err := rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, postID := range postIDs {
+			key := utils.GeneratePostReactionsRedisKey(postID)
+			for _, kind := range constant.REACTION_KINDS {
+				pipe.HSetNX(ctx, key, kind, counts[postID][kind])
+			}
+			pipe.SAdd(ctx, utils.GenerateDirtyPostReactionsRedisKey(), postID)
+		}
+		return nil
+	})
+
+	return err
+}
+
+// Replace overwrites the counts of the posts of postIDs with counts
+func (r *ReactionCounterRepository) Replace(ctx context.Context, rdb redis.Cmdable, counts map[uint64]map[string]int64, postIDs []uint64) error {
+	_, err := rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, postID := range postIDs {
+			values := make(map[string]any, len(constant.REACTION_KINDS))
+			for _, kind := range constant.REACTION_KINDS {
+				values[kind] = counts[postID][kind]
+			}
+			pipe.HSet(ctx, utils.GeneratePostReactionsRedisKey(postID), values)
+		}
+		return nil
+	})
+
+	return err
+}
+
+// PopDirty takes up to count of the posts whose counts changed. Replicas popping concurrently get different posts.
+func (r *ReactionCounterRepository) PopDirty(ctx context.Context, rdb redis.Cmdable, count int64) ([]uint64, error) {
+	members, err := rdb.SPopN(ctx, utils.GenerateDirtyPostReactionsRedisKey(), count).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	postIDs := make([]uint64, 0, len(members))
+	for _, member := range members {
+		if postID, err := strconv.ParseUint(member, 10, 64); err == nil {
+			postIDs = append(postIDs, postID)
+		}
+	}
+
+	return postIDs, nil
+}
+
+// MarkDirty marks posts as changed again, when reconciling them failed
+func (r *ReactionCounterRepository) MarkDirty(ctx context.Context, rdb redis.Cmdable, postIDs []uint64) error {
+	members := make([]any, len(postIDs))
+	for i, postID := range postIDs {
+		members[i] = postID
+	}
+
+	return rdb.SAdd(ctx, utils.GenerateDirtyPostReactionsRedisKey(), members...).Err()
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"backend/internal/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReactionRepository struct {
+	Repository[entity.PostReaction]
+}
+
+func NewReactionRepository() *ReactionRepository {
+	return &ReactionRepository{}
+}
+
+// Add adds reaction unless the user already reacted with its kind, and tells if it was added
+func (r *ReactionRepository) Add(tx *gorm.DB, reaction *entity.PostReaction) (bool, error) {
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(reaction)
+
+	return result.RowsAffected > 0, result.Error
+}
+
+// Remove removes the reaction of a kind a user left on a post, and tells if there was one
+func (r *ReactionRepository) Remove(tx *gorm.DB, postID uint64, userID string, kind string) (bool, error) {
+	result := tx.Where("post_id = ? AND user_id = ? AND kind = ?", postID, userID, kind).Delete(new(entity.PostReaction))
+
+	return result.RowsAffected > 0, result.Error
+}
+
+// CountByPostIDs counts the reactions on every post of postIDs by kind, from the reactions themselves
+func (r *ReactionRepository) CountByPostIDs(tx *gorm.DB, postIDs []uint64) (map[uint64]map[string]int64, error) {
+	var rows []entity.PostReactionCount
+	err := tx.Model(new(entity.PostReaction)).
+		Select("post_id, kind, count(*) as count").
+		Where("post_id IN ?", postIDs).
+		Group("post_id, kind").
+		Scan(&rows).Error
+
+	return groupReactionCounts(rows), err
+}
+
+// FindCountsByPostIDs returns the reaction counts of every post of postIDs by kind, as last reconciled
+func (r *ReactionRepository) FindCountsByPostIDs(tx *gorm.DB, postIDs []uint64) (map[uint64]map[string]int64, error) {
+	var rows []entity.PostReactionCount
+	err := tx.Where("post_id IN ?", postIDs).Find(&rows).Error
+
+	return groupReactionCounts(rows), err
+}
+
+// ReplaceCounts stores counts as the reconciled reaction counts of the posts in postIDs,
+// posts left out of counts have no reactions
+func (r *ReactionRepository) ReplaceCounts(tx *gorm.DB, postIDs []uint64, counts map[uint64]map[string]int64) error {
+	if err := tx.Where("post_id IN ?", postIDs).Delete(new(entity.PostReactionCount)).Error; err != nil {
+		return err
+	}
+
+	var rows []entity.PostReactionCount
+	for postID, kinds := range counts {
+		for kind, count := range kinds {
+			rows = append(rows, entity.PostReactionCount{PostID: postID, Kind: kind, Count: count})
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	return tx.Create(&rows).Error
+}
+
+func groupReactionCounts(rows []entity.PostReactionCount) map[uint64]map[string]int64 {
+	counts := make(map[uint64]map[string]int64)
+	for _, row := range rows {
+		if counts[row.PostID] == nil {
+			counts[row.PostID] = make(map[string]int64)
+		}
+		counts[row.PostID][row.Kind] = row.Count
+	}
+
+	return counts
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"backend/internal/constant"
+	"backend/internal/entity"
+	"backend/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReadingListRepository struct {
+	Repository[entity.ReadingList]
+}
+
+func NewReadingListRepository() *ReadingListRepository {
+	return &ReadingListRepository{}
+}
+
+// FindByIDAndUserID finds a reading list of userID, locking it until tx ends so its items can be reordered safely
+func (r *ReadingListRepository) FindByIDAndUserID(tx *gorm.DB, readingList *entity.ReadingList, ID uint64, userID string) error {
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND user_id = ?", ID, userID).
+		First(readingList).Error
+}
+
+// ListByUserID lists the reading lists of a user by name, with their number of published posts
+func (r *ReadingListRepository) ListByUserID(tx *gorm.DB, userID string) ([]model.ReadingListResponse, error) {
+	var readingLists []model.ReadingListResponse
+	err := tx.Model(new(entity.ReadingList)).
+		Select("reading_lists.id, reading_lists.name, count(posts.id) as post_count, reading_lists.created_at").
+		Joins("left join reading_list_items on reading_list_items.reading_list_id = reading_lists.id").
+		Joins("left join posts on posts.id = reading_list_items.post_id AND posts.status = ? AND posts.deleted_at IS NULL",
+			constant.POST_STATUS_PUBLISHED).
+		Where("reading_lists.user_id = ?", userID).
+		Group("reading_lists.id").
+		Order("reading_lists.name asc").
+		Scan(&readingLists).Error
+
+	return readingLists, err
+}
+
+// ListItems lists the posts of a reading list in order. Posts that are no longer published stay in the list,
+// but are left out until they are published again.
+func (r *ReadingListRepository) ListItems(tx *gorm.DB, readingListID uint64) ([]model.ReadingListItemResponse, error) {
+	var items []model.ReadingListItemResponse
+	err := tx.Model(new(entity.ReadingListItem)).
+		Select(`reading_list_items.position,
+			posts.id as post_id,
+			posts.title,
+			posts.slug,
+			users.name as author,
+			reading_list_items.created_at as added_at`).
+		Joins("inner join posts on posts.id = reading_list_items.post_id AND posts.status = ? AND posts.deleted_at IS NULL",
+			constant.POST_STATUS_PUBLISHED).
+		Joins("inner join users on users.id = posts.user_id").
+		Where("reading_list_items.reading_list_id = ?", readingListID).
+		Order("reading_list_items.position asc").
+		Scan(&items).Error
+
+	return items, err
+}
+
+// ItemPostIDs returns the posts of a reading list in order, published or not
+func (r *ReadingListRepository) ItemPostIDs(tx *gorm.DB, readingListID uint64) ([]uint64, error) {
+	var postIDs []uint64
+	err := tx.Model(new(entity.ReadingListItem)).
+		Where("reading_list_id = ?", readingListID).
+		Order("position asc").
+		Pluck("post_id", &postIDs).Error
+
+	return postIDs, err
+}
+
+// ReplaceItems stores postIDs as the posts of a reading list, in their order. Posts keep the time they were added.
+func (r *ReadingListRepository) ReplaceItems(tx *gorm.DB, readingListID uint64, postIDs []uint64) error {
+	var existing []entity.ReadingListItem
+	if err := tx.Where("reading_list_id = ?", readingListID).Find(&existing).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("reading_list_id = ?", readingListID).Delete(new(entity.ReadingListItem)).Error; err != nil {
+		return err
+	}
+	if len(postIDs) == 0 {
+		return nil
+	}
+
+	items := make([]entity.ReadingListItem, len(postIDs))
+	for i, postID := range postIDs {
+		items[i] = entity.ReadingListItem{ReadingListID: readingListID, PostID: postID, Position: i + 1}
+		for _, item := range existing {
+			if item.PostID == postID {
+				items[i].CreatedAt = item.CreatedAt
+			}
+		}
+	}
+
+	return tx.Create(&items).Error
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"backend/internal/entity"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type RecoveryCodeRepository struct {
+	Repository[entity.RecoveryCode]
+}
+
+func NewRecoveryCodeRepository() *RecoveryCodeRepository {
+	return &RecoveryCodeRepository{}
+}
+
+// Use marks the unused code of the user with codeHash as used at now, and tells if there was one.
+// Concurrent uses of a code wait for each other on its row, so only one of them gets true.
+func (r *RecoveryCodeRepository) Use(tx *gorm.DB, userID string, codeHash string, now time.Time) (bool, error) {
+	result := tx.Model(new(entity.RecoveryCode)).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", now)
+
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *RecoveryCodeRepository) DeleteByUserID(tx *gorm.DB, userID string) error {
+	return tx.Where("user_id = ?", userID).Delete(new(entity.RecoveryCode)).Error
+}
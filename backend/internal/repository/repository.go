@@ -23,3 +23,12 @@ func (e Repository[T]) CountByID(tx *gorm.DB, id any) (int64, error) {
 func (e Repository[T]) FindByID(tx *gorm.DB, entity *T, id any) error {
 	return tx.First(entity, "id = ?", id).Error
 }
+
+// TryAdvisoryXactLock takes the Postgres advisory lock key until tx ends, without waiting.
+// It returns false when another transaction holds the lock.
+func TryAdvisoryXactLock(tx *gorm.DB, key int64) (bool, error) {
+	var locked bool
+	err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", key).Scan(&locked).Error
+
+	return locked, err
+}
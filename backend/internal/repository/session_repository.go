@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"backend/internal/entity"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrRefreshTokenReused is returned by Rotate when the presented refresh token has already been rotated
+var ErrRefreshTokenReused = errors.New("refresh token has already been used")
+
+// touchScript records the last seen time of a session while the session exists, expiring with it.
+// A session revoked in the meantime stays deleted.
+var touchScript = redis.NewScript(`
+local ttl = redis.call("PTTL", KEYS[1])
+if ttl <= 0 then
+	return 0
+end
+
+redis.call("SET", KEYS[2], ARGV[1], "PX", ttl)
+return 1
+`)
+
+// SessionRepository stores sessions in Redis. Every session lives under its own key, next to a key with
+// its last seen time, and a set per user indexes the session IDs of that user.
+type SessionRepository struct {
+}
+
+func NewSessionRepository() *SessionRepository {
+	return &SessionRepository{}
+}
+
+// Save stores session until its ExpiresAt
+func (r *SessionRepository) Save(ctx context.Context, rdb redis.Cmdable, session *entity.Session) error {
+	value, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	ttl := time.Until(session.ExpiresAt)
+	userSessionsKey := utils.GenerateUserSessionsRedisKey(session.UserID)
+
+	_, err = rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, utils.GenerateSessionRedisKey(session.ID), value, ttl)
+		pipe.Set(ctx, utils.GenerateSessionLastSeenRedisKey(session.ID), session.LastSeenAt.Format(time.RFC3339Nano), ttl)
+		pipe.SAdd(ctx, userSessionsKey, session.ID)
+		// The newest session always expires last, so the index lives as long as it
+		pipe.Expire(ctx, userSessionsKey, ttl)
+		return nil
+	})
+
+	return err
+}
+
+// Rotate replaces the refresh token of a session, if presentedTokenHash is still its current refresh token.
+// A session is the family of every refresh token issued for it: when an older token of the family is presented,
+// the token has been stolen or replayed, so the whole session is deleted and ErrRefreshTokenReused is returned.
+// The session is watched while rotating, so two requests racing with the same token can't both succeed.
+func (r *SessionRepository) Rotate(ctx context.Context, rdb *redis.Client, session *entity.Session,
+	presentedTokenHash string, newTokenHash string, newExpiresAt time.Time) error {
+	return rdb.Watch(ctx, func(tx *redis.Tx) error {
+		if err := r.FindByID(ctx, tx, session, session.ID); err != nil {
+			return err
+		}
+
+		if session.RefreshTokenHash != presentedTokenHash {
+			if err := r.Delete(ctx, tx, session); err != nil {
+				return err
+			}
+			return ErrRefreshTokenReused
+		}
+
+		session.RefreshTokenHash = newTokenHash
+		session.ExpiresAt = newExpiresAt
+		session.LastSeenAt = time.Now()
+
+		return r.Save(ctx, tx, session)
+	}, utils.GenerateSessionRedisKey(session.ID))
+}
+
+// Touch updates LastSeenAt without changing the expiration of the session. It doesn't write the session itself,
+// so it can't bring back a session deleted since it was read, nor undo a concurrent Rotate.
+func (r *SessionRepository) Touch(ctx context.Context, rdb redis.Cmdable, session *entity.Session) error {
+	session.LastSeenAt = time.Now()
+
+	return touchScript.Run(ctx, rdb,
+		[]string{utils.GenerateSessionRedisKey(session.ID), utils.GenerateSessionLastSeenRedisKey(session.ID)},
+		session.LastSeenAt.Format(time.RFC3339Nano)).Err()
+}
+
+// FindByID returns redis.Nil if the session doesn't exist or has expired
+func (r *SessionRepository) FindByID(ctx context.Context, rdb redis.Cmdable, session *entity.Session, ID string) error {
+	values, err := rdb.MGet(ctx, utils.GenerateSessionRedisKey(ID), utils.GenerateSessionLastSeenRedisKey(ID)).Result()
+	if err != nil {
+		return err
+	}
+
+	value, ok := values[0].(string)
+	if !ok {
+		return redis.Nil
+	}
+	if err := json.Unmarshal([]byte(value), session); err != nil {
+		return err
+	}
+
+	if lastSeen, ok := values[1].(string); ok {
+		if lastSeenAt, err := time.Parse(time.RFC3339Nano, lastSeen); err == nil && lastSeenAt.After(session.LastSeenAt) {
+			session.LastSeenAt = lastSeenAt
+		}
+	}
+
+	return nil
+}
+
+// ListByUserID returns every active session of a user, the most recently used first
+func (r *SessionRepository) ListByUserID(ctx context.Context, rdb redis.Cmdable, userID string) ([]entity.Session, error) {
+	userSessionsKey := utils.GenerateUserSessionsRedisKey(userID)
+
+	IDs, err := rdb.SMembers(ctx, userSessionsKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]entity.Session, 0, len(IDs))
+	for _, ID := range IDs {
+		var session entity.Session
+		err := r.FindByID(ctx, rdb, &session, ID)
+		if errors.Is(err, redis.Nil) {
+			// The session has expired, remove it from the index
+			if err := rdb.SRem(ctx, userSessionsKey, ID).Err(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt)
+	})
+
+	return sessions, nil
+}
+
+func (r *SessionRepository) Delete(ctx context.Context, rdb redis.Cmdable, session *entity.Session) error {
+	_, err := rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, utils.GenerateSessionRedisKey(session.ID), utils.GenerateSessionLastSeenRedisKey(session.ID))
+		pipe.SRem(ctx, utils.GenerateUserSessionsRedisKey(session.UserID), session.ID)
+		return nil
+	})
+
+	return err
+}
+
+// DeleteByUserID deletes every session of a user, except the one with exceptID if it isn't empty
+func (r *SessionRepository) DeleteByUserID(ctx context.Context, rdb redis.Cmdable, userID string, exceptID string) error {
+	userSessionsKey := utils.GenerateUserSessionsRedisKey(userID)
+
+	IDs, err := rdb.SMembers(ctx, userSessionsKey).Result()
+	if err != nil {
+		return err
+	}
+
+	_, err = rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, ID := range IDs {
+			if ID == exceptID {
+				continue
+			}
+			pipe.Del(ctx, utils.GenerateSessionRedisKey(ID), utils.GenerateSessionLastSeenRedisKey(ID))
+			pipe.SRem(ctx, userSessionsKey, ID)
+		}
+		return nil
+	})
+
+	return err
+}
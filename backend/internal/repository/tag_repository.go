@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"backend/internal/constant"
+	"backend/internal/entity"
+	"backend/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TagRepository struct {
+	Repository[entity.Tag]
+}
+
+func NewTagRepository() *TagRepository {
+	return &TagRepository{}
+}
+
+// FindOrCreate returns the tags with the slugs of tags, creating the ones that don't exist yet.
+// Existing tags keep their name.
+func (r *TagRepository) FindOrCreate(tx *gorm.DB, tags []entity.Tag) ([]entity.Tag, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+
+	err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "slug"}}, DoNothing: true}).
+		Create(&tags).Error
+	if err != nil {
+		return nil, err
+	}
+
+	slugs := make([]string, len(tags))
+	for i, tag := range tags {
+		slugs[i] = tag.Slug
+	}
+
+	var found []entity.Tag
+	err = tx.Where("slug IN ?", slugs).Order("slug asc").Find(&found).Error
+
+	return found, err
+}
+
+// ReplacePostTags sets the tags of a post to tagIDs, removing the others
+func (r *TagRepository) ReplacePostTags(tx *gorm.DB, postID uint64, tagIDs []uint64) error {
+	if err := tx.Where("post_id = ?", postID).Delete(new(entity.PostTag)).Error; err != nil {
+		return err
+	}
+	if len(tagIDs) == 0 {
+		return nil
+	}
+
+	postTags := make([]entity.PostTag, len(tagIDs))
+	for i, tagID := range tagIDs {
+		postTags[i] = entity.PostTag{PostID: postID, TagID: tagID}
+	}
+
+	return tx.Create(&postTags).Error
+}
+
+// FindByPostIDs returns the tags of every post in postIDs by post ID, sorted by slug
+func (r *TagRepository) FindByPostIDs(tx *gorm.DB, postIDs []uint64) (map[uint64][]model.TagResponse, error) {
+	var rows []struct {
+		PostID uint64
+		model.TagResponse
+	}
+
+	err := tx.Model(new(entity.PostTag)).
+		Select("post_tags.post_id, tags.name, tags.slug").
+		Joins("inner join tags on tags.id = post_tags.tag_id").
+		Where("post_tags.post_id IN ?", postIDs).
+		Order("tags.slug asc").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	tagsByPostID := make(map[uint64][]model.TagResponse)
+	for _, row := range rows {
+		tagsByPostID[row.PostID] = append(tagsByPostID[row.PostID], row.TagResponse)
+	}
+
+	return tagsByPostID, nil
+}
+
+// ListWithPostCount lists the tags of published posts, from the most used
+func (r *TagRepository) ListWithPostCount(tx *gorm.DB) ([]model.TagResponse, error) {
+	var tags []model.TagResponse
+
+	err := r.selectWithPostCount(tx).
+		Order("post_count desc, tags.slug asc").
+		Scan(&tags).Error
+
+	return tags, err
+}
+
+// GetWithPostCount leaves tag empty unless a published post has the tag with slug
+func (r *TagRepository) GetWithPostCount(tx *gorm.DB, tag *model.TagResponse, slug string) error {
+	return r.selectWithPostCount(tx).
+		Where("tags.slug = ?", slug).
+		Scan(tag).Error
+}
+
+// selectWithPostCount counts published posts only, so tags of drafts and trashed posts stay private
+func (r *TagRepository) selectWithPostCount(tx *gorm.DB) *gorm.DB {
+	return tx.Model(new(entity.Tag)).
+		Select("tags.name, tags.slug, count(posts.id) as post_count").
+		Joins("inner join post_tags on post_tags.tag_id = tags.id").
+		Joins("inner join posts on posts.id = post_tags.post_id AND posts.status = ? AND posts.deleted_at IS NULL",
+			constant.POST_STATUS_PUBLISHED).
+		Group("tags.id")
+}
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"backend/internal/entity"
+	"backend/internal/model"
+	"backend/internal/utils"
 
 	"gorm.io/gorm"
 )
@@ -17,3 +19,17 @@ func NewUserRepository() *UserRepository {
 func (r *UserRepository) FindByEmail(tx *gorm.DB, user *entity.User, email string) error {
 	return tx.First(user, "email = ?", email).Error
 }
+
+func (r *UserRepository) List(tx *gorm.DB, users *[]entity.User, request *model.UserListRequest) error {
+	if request.Page > 0 && request.PageSize > 0 {
+		tx = tx.Scopes(utils.Paginate(request.Page, request.PageSize))
+	}
+
+	query := tx.Order("created_at asc")
+
+	if len(request.Role) > 0 {
+		query = query.Where("role = ?", request.Role)
+	}
+
+	return query.Find(users).Error
+}
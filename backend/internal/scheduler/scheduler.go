@@ -0,0 +1,84 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// JobFunc runs one pass of a background job. It is called again on the next tick whether it failed or not,
+// so it must be safe to run on several replicas at once, usually by taking a lock first.
+type JobFunc func(ctx context.Context) error
+
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      JobFunc
+}
+
+// Scheduler runs every registered job on its own ticker until the context given to Start is done
+type Scheduler struct {
+	Logger *log.Logger
+	jobs   []*Job
+}
+
+func NewScheduler(logger *log.Logger) *Scheduler {
+	return &Scheduler{
+		Logger: logger,
+	}
+}
+
+// Every registers run to be called every interval once the scheduler starts
+func (s *Scheduler) Every(name string, interval time.Duration, run JobFunc) {
+	s.jobs = append(s.jobs, &Job{
+		Name:     name,
+		Interval: interval,
+		Run:      run,
+	})
+}
+
+// Start runs every job in its own goroutine and returns right away
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, job := range s.jobs {
+		go s.loop(ctx, job)
+	}
+}
+
+// Run runs the job called name once and returns its error, so a job can be triggered without waiting for its tick
+func (s *Scheduler) Run(ctx context.Context, name string) error {
+	for _, job := range s.jobs {
+		if job.Name == name {
+			return job.Run(ctx)
+		}
+	}
+
+	return fmt.Errorf("job %q is not registered", name)
+}
+
+func (s *Scheduler) loop(ctx context.Context, job *Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.runSafely(ctx, job)
+		}
+	}
+}
+
+// runSafely logs the error or panic of a job instead of stopping the scheduler
+func (s *Scheduler) runSafely(ctx context.Context, job *Job) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.Logger.Printf("job %s panicked: %v", job.Name, r)
+		}
+	}()
+
+	if err := job.Run(ctx); err != nil {
+		s.Logger.Printf("job %s: %v", job.Name, err)
+	}
+}
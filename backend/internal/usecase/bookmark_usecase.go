@@ -0,0 +1,313 @@
+package usecase
+
+import (
+	"backend/internal/apperror"
+	"backend/internal/entity"
+	"backend/internal/model"
+	"backend/internal/repository"
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+type BookmarkUseCase struct {
+	DB                    *gorm.DB
+	Validate              *validator.Validate
+	PostRepository        *repository.PostRepository
+	BookmarkRepository    *repository.BookmarkRepository
+	ReadingListRepository *repository.ReadingListRepository
+}
+
+func NewBookmarkUseCase(db *gorm.DB, validate *validator.Validate, postRepository *repository.PostRepository,
+	bookmarkRepository *repository.BookmarkRepository, readingListRepository *repository.ReadingListRepository) *BookmarkUseCase {
+	return &BookmarkUseCase{
+		DB:                    db,
+		Validate:              validate,
+		PostRepository:        postRepository,
+		BookmarkRepository:    bookmarkRepository,
+		ReadingListRepository: readingListRepository,
+	}
+}
+
+// Save bookmarks a published post, the bookmarked posts are listed by PostUseCase.List
+func (s *BookmarkUseCase) Save(ctx context.Context, request *model.BookmarkRequest) error {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := s.Validate.Struct(request); err != nil {
+		return apperror.NewValidationError(err)
+	}
+
+	post := new(entity.Post)
+	if err := s.PostRepository.FindPublished(tx, post, request.PostID); err != nil {
+		return apperror.NewNotFoundError("post")
+	}
+
+	if err := s.BookmarkRepository.Add(tx, &entity.Bookmark{UserID: request.UserID, PostID: post.ID}); err != nil {
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// Unsave removes a bookmark, whether the post is still published or not
+func (s *BookmarkUseCase) Unsave(ctx context.Context, request *model.BookmarkRequest) error {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := s.Validate.Struct(request); err != nil {
+		return apperror.NewValidationError(err)
+	}
+
+	if err := s.BookmarkRepository.Remove(tx, request.UserID, request.PostID); err != nil {
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+func (s *BookmarkUseCase) ListReadingLists(ctx context.Context, userID string) ([]model.ReadingListResponse, error) {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	readingLists, err := s.ReadingListRepository.ListByUserID(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if readingLists == nil {
+		readingLists = []model.ReadingListResponse{}
+	}
+
+	return readingLists, nil
+}
+
+// GetReadingList returns a reading list of the user with its published posts in order
+func (s *BookmarkUseCase) GetReadingList(ctx context.Context, request *model.ReadingListGetRequest) (*model.ReadingListResponse, error) {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperror.NewValidationError(err)
+	}
+
+	readingList := new(entity.ReadingList)
+	if err := s.ReadingListRepository.FindByIDAndUserID(tx, readingList, request.ID, request.UserID); err != nil {
+		return nil, apperror.NewNotFoundError("reading list")
+	}
+
+	return s.toReadingListResponse(tx, readingList)
+}
+
+func (s *BookmarkUseCase) CreateReadingList(ctx context.Context, request *model.ReadingListCreateRequest) (*model.ReadingListResponse, error) {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperror.NewValidationError(err)
+	}
+
+	// The unique index on the user and the name rejects a name the user already has, even one saved concurrently
+	readingList := &entity.ReadingList{UserID: request.UserID, Name: request.Name}
+	if err := s.ReadingListRepository.Save(tx, readingList); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, apperror.NewConflictError("reading list")
+		}
+		return nil, err
+	}
+
+	return s.commitWithReadingList(tx, readingList)
+}
+
+// UpdateReadingList renames a reading list
+func (s *BookmarkUseCase) UpdateReadingList(ctx context.Context, request *model.ReadingListUpdateRequest) (*model.ReadingListResponse, error) {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperror.NewValidationError(err)
+	}
+
+	readingList := new(entity.ReadingList)
+	if err := s.ReadingListRepository.FindByIDAndUserID(tx, readingList, request.ID, request.UserID); err != nil {
+		return nil, apperror.NewNotFoundError("reading list")
+	}
+
+	readingList.Name = request.Name
+	if err := s.ReadingListRepository.Save(tx, readingList); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, apperror.NewConflictError("reading list")
+		}
+		return nil, err
+	}
+
+	return s.commitWithReadingList(tx, readingList)
+}
+
+func (s *BookmarkUseCase) DeleteReadingList(ctx context.Context, request *model.ReadingListGetRequest) error {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := s.Validate.Struct(request); err != nil {
+		return apperror.NewValidationError(err)
+	}
+
+	readingList := new(entity.ReadingList)
+	if err := s.ReadingListRepository.FindByIDAndUserID(tx, readingList, request.ID, request.UserID); err != nil {
+		return apperror.NewNotFoundError("reading list")
+	}
+
+	if err := s.ReadingListRepository.Delete(tx, readingList); err != nil {
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// AddToReadingList adds a published post to a reading list, or moves it when it is in the list already
+func (s *BookmarkUseCase) AddToReadingList(ctx context.Context, request *model.ReadingListItemAddRequest) (*model.ReadingListResponse, error) {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperror.NewValidationError(err)
+	}
+
+	readingList := new(entity.ReadingList)
+	if err := s.ReadingListRepository.FindByIDAndUserID(tx, readingList, request.ReadingListID, request.UserID); err != nil {
+		return nil, apperror.NewNotFoundError("reading list")
+	}
+
+	post := new(entity.Post)
+	if err := s.PostRepository.FindPublished(tx, post, request.PostID); err != nil {
+		return nil, apperror.NewNotFoundError("post")
+	}
+
+	postIDs, err := s.ReadingListRepository.ItemPostIDs(tx, readingList.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	postIDs = slices.DeleteFunc(postIDs, func(postID uint64) bool { return postID == post.ID })
+	position := len(postIDs)
+	if request.Position > 0 && request.Position <= len(postIDs) {
+		position = request.Position - 1
+	}
+	postIDs = slices.Insert(postIDs, position, post.ID)
+
+	if err := s.ReadingListRepository.ReplaceItems(tx, readingList.ID, postIDs); err != nil {
+		return nil, err
+	}
+
+	return s.commitWithReadingList(tx, readingList)
+}
+
+func (s *BookmarkUseCase) RemoveFromReadingList(ctx context.Context, request *model.ReadingListItemRemoveRequest) (*model.ReadingListResponse, error) {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperror.NewValidationError(err)
+	}
+
+	readingList := new(entity.ReadingList)
+	if err := s.ReadingListRepository.FindByIDAndUserID(tx, readingList, request.ReadingListID, request.UserID); err != nil {
+		return nil, apperror.NewNotFoundError("reading list")
+	}
+
+	postIDs, err := s.ReadingListRepository.ItemPostIDs(tx, readingList.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(postIDs, request.PostID) {
+		return nil, apperror.NewNotFoundError("post")
+	}
+
+	postIDs = slices.DeleteFunc(postIDs, func(postID uint64) bool { return postID == request.PostID })
+	if err := s.ReadingListRepository.ReplaceItems(tx, readingList.ID, postIDs); err != nil {
+		return nil, err
+	}
+
+	return s.commitWithReadingList(tx, readingList)
+}
+
+// ReorderReadingList orders the posts of a reading list. The new order must hold every published post of the
+// list once; the posts that are not published anymore keep their place after them.
+func (s *BookmarkUseCase) ReorderReadingList(ctx context.Context, request *model.ReadingListReorderRequest) (*model.ReadingListResponse, error) {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperror.NewValidationError(err)
+	}
+
+	readingList := new(entity.ReadingList)
+	if err := s.ReadingListRepository.FindByIDAndUserID(tx, readingList, request.ReadingListID, request.UserID); err != nil {
+		return nil, apperror.NewNotFoundError("reading list")
+	}
+
+	items, err := s.ReadingListRepository.ListItems(tx, readingList.ID)
+	if err != nil {
+		return nil, err
+	}
+	postIDs, err := s.ReadingListRepository.ItemPostIDs(tx, readingList.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := make([]uint64, len(items))
+	for i, item := range items {
+		visible[i] = item.PostID
+	}
+	requested := slices.Clone(request.PostIDs)
+	slices.Sort(visible)
+	slices.Sort(requested)
+	if !slices.Equal(visible, requested) {
+		return nil, apperror.NewBadRequestError(apperror.CODE_INVALID_READING_LIST_ORDER,
+			"the new order must list every post of the reading list once")
+	}
+
+	hidden := slices.DeleteFunc(postIDs, func(postID uint64) bool {
+		_, found := slices.BinarySearch(visible, postID)
+		return found
+	})
+	postIDs = append(slices.Clone(request.PostIDs), hidden...)
+
+	if err := s.ReadingListRepository.ReplaceItems(tx, readingList.ID, postIDs); err != nil {
+		return nil, err
+	}
+
+	return s.commitWithReadingList(tx, readingList)
+}
+
+func (s *BookmarkUseCase) commitWithReadingList(tx *gorm.DB, readingList *entity.ReadingList) (*model.ReadingListResponse, error) {
+	response, err := s.toReadingListResponse(tx, readingList)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (s *BookmarkUseCase) toReadingListResponse(tx *gorm.DB, readingList *entity.ReadingList) (*model.ReadingListResponse, error) {
+	items, err := s.ReadingListRepository.ListItems(tx, readingList.ID)
+	if err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []model.ReadingListItemResponse{}
+	}
+
+	return &model.ReadingListResponse{
+		ID:        readingList.ID,
+		Name:      readingList.Name,
+		PostCount: int64(len(items)),
+		CreatedAt: readingList.CreatedAt,
+		Posts:     items,
+	}, nil
+}
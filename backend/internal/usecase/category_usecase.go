@@ -0,0 +1,247 @@
+package usecase
+
+import (
+	"backend/internal/apperror"
+	"backend/internal/entity"
+	"backend/internal/model"
+	"backend/internal/model/converter"
+	"backend/internal/repository"
+	"backend/internal/utils"
+	"context"
+
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+type CategoryUseCase struct {
+	DB                 *gorm.DB
+	Validate           *validator.Validate
+	CategoryRepository *repository.CategoryRepository
+}
+
+func NewCategoryUseCase(db *gorm.DB, validate *validator.Validate,
+	categoryRepository *repository.CategoryRepository) *CategoryUseCase {
+	return &CategoryUseCase{
+		DB:                 db,
+		Validate:           validate,
+		CategoryRepository: categoryRepository,
+	}
+}
+
+// Tree returns the top level categories, each with its subcategories nested under it
+func (s *CategoryUseCase) Tree(ctx context.Context) ([]*model.CategoryResponse, error) {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	categories, err := s.CategoryRepository.ListAll(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint64]*model.CategoryResponse, len(categories))
+	for i := range categories {
+		byID[categories[i].ID] = converter.CategoryToResponse(&categories[i])
+	}
+
+	// Categories are sorted by name, so children are appended sorted too
+	roots := []*model.CategoryResponse{}
+	for _, category := range categories {
+		response := byID[category.ID]
+		if category.ParentID == nil {
+			roots = append(roots, response)
+			continue
+		}
+		if parent, ok := byID[*category.ParentID]; ok {
+			parent.Children = append(parent.Children, response)
+		}
+	}
+
+	return roots, nil
+}
+
+// GetBySlug returns a category with the path of categories above it and its direct subcategories
+func (s *CategoryUseCase) GetBySlug(ctx context.Context, request *model.CategoryGetBySlugRequest) (*model.CategoryResponse, error) {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperror.NewValidationError(err)
+	}
+
+	category := new(entity.Category)
+	if err := s.CategoryRepository.FindBySlug(tx, category, request.Slug); err != nil {
+		return nil, apperror.NewNotFoundError("category")
+	}
+
+	return s.toDetailResponse(tx, category)
+}
+
+func (s *CategoryUseCase) Create(ctx context.Context, request *model.CategoryCreateRequest) (*model.CategoryResponse, error) {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperror.NewValidationError(err)
+	}
+
+	if err := s.CategoryRepository.LockTree(tx); err != nil {
+		return nil, err
+	}
+	if request.ParentID != nil {
+		if count, err := s.CategoryRepository.CountByID(tx, *request.ParentID); err != nil {
+			return nil, err
+		} else if count == 0 {
+			return nil, apperror.NewNotFoundError("category")
+		}
+	}
+
+	category := new(entity.Category)
+	category.Name = request.Name
+	category.ParentID = request.ParentID
+	if err := s.assignSlug(tx, category); err != nil {
+		return nil, err
+	}
+
+	if err := s.CategoryRepository.Save(tx, category); err != nil {
+		return nil, err
+	}
+
+	response, err := s.toDetailResponse(tx, category)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// Update renames and moves a category with its subcategories. A category can't be moved under
+// itself or one of its own subcategories.
+func (s *CategoryUseCase) Update(ctx context.Context, request *model.CategoryUpdateRequest) (*model.CategoryResponse, error) {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperror.NewValidationError(err)
+	}
+
+	if err := s.CategoryRepository.LockTree(tx); err != nil {
+		return nil, err
+	}
+
+	category := new(entity.Category)
+	if err := s.CategoryRepository.FindByID(tx, category, request.ID); err != nil {
+		return nil, apperror.NewNotFoundError("category")
+	}
+
+	if request.ParentID != nil {
+		if count, err := s.CategoryRepository.CountByID(tx, *request.ParentID); err != nil {
+			return nil, err
+		} else if count == 0 {
+			return nil, apperror.NewNotFoundError("category")
+		}
+
+		cycle, err := s.CategoryRepository.InSubtree(tx, category.ID, *request.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		if cycle {
+			return nil, apperror.NewBadRequestError(apperror.CODE_CATEGORY_CYCLE,
+				"category can't be moved under itself or its subcategories")
+		}
+	}
+
+	category.Name = request.Name
+	category.ParentID = request.ParentID
+	if err := s.assignSlug(tx, category); err != nil {
+		return nil, err
+	}
+
+	if err := s.CategoryRepository.Save(tx, category); err != nil {
+		return nil, err
+	}
+
+	response, err := s.toDetailResponse(tx, category)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// Delete removes a category without subcategories, its posts are left without a category
+func (s *CategoryUseCase) Delete(ctx context.Context, request *model.CategoryDeleteRequest) error {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := s.Validate.Struct(request); err != nil {
+		return apperror.NewValidationError(err)
+	}
+
+	if err := s.CategoryRepository.LockTree(tx); err != nil {
+		return err
+	}
+
+	category := new(entity.Category)
+	if err := s.CategoryRepository.FindByID(tx, category, request.ID); err != nil {
+		return apperror.NewNotFoundError("category")
+	}
+
+	children, err := s.CategoryRepository.CountChildren(tx, category.ID)
+	if err != nil {
+		return err
+	}
+	if children > 0 {
+		return apperror.NewBadRequestError(apperror.CODE_CATEGORY_NOT_EMPTY, "category still has subcategories")
+	}
+
+	if err := s.CategoryRepository.Delete(tx, category); err != nil {
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// assignSlug gives category a slug from its name, unless its slug already comes from that name
+func (s *CategoryUseCase) assignSlug(tx *gorm.DB, category *entity.Category) error {
+	base := utils.Slugify(category.Name)
+	if len(category.Slug) > 0 && utils.SlugHasBase(category.Slug, base) {
+		return nil
+	}
+
+	slug, err := s.CategoryRepository.NextAvailableSlug(tx, base, category.ID)
+	if err != nil {
+		return err
+	}
+	category.Slug = slug
+
+	return nil
+}
+
+func (s *CategoryUseCase) toDetailResponse(tx *gorm.DB, category *entity.Category) (*model.CategoryResponse, error) {
+	response := converter.CategoryToResponse(category)
+
+	ancestors, err := s.CategoryRepository.Ancestors(tx, category.ID)
+	if err != nil {
+		return nil, err
+	}
+	response.Ancestors = make([]model.CategoryPathResponse, len(ancestors))
+	for i, ancestor := range ancestors {
+		response.Ancestors[i] = model.CategoryPathResponse{ID: ancestor.ID, Name: ancestor.Name, Slug: ancestor.Slug}
+	}
+
+	children, err := s.CategoryRepository.ListChildren(tx, category.ID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range children {
+		response.Children = append(response.Children, converter.CategoryToResponse(&children[i]))
+	}
+
+	return response, nil
+}
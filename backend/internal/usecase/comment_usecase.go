@@ -0,0 +1,321 @@
+package usecase
+
+import (
+	"backend/internal/apperror"
+	"backend/internal/constant"
+	"backend/internal/entity"
+	"backend/internal/model"
+	"backend/internal/moderation"
+	"backend/internal/repository"
+	"backend/internal/utils"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+type CommentUseCase struct {
+	DB                *gorm.DB
+	Validate          *validator.Validate
+	CommentRepository *repository.CommentRepository
+	PostRepository    *repository.PostRepository
+	UserRepository    *repository.UserRepository
+	SpamChecker       moderation.SpamChecker
+}
+
+func NewCommentUseCase(db *gorm.DB, validate *validator.Validate, commentRepository *repository.CommentRepository,
+	postRepository *repository.PostRepository, userRepository *repository.UserRepository,
+	spamChecker moderation.SpamChecker) *CommentUseCase {
+	return &CommentUseCase{
+		DB:                db,
+		Validate:          validate,
+		CommentRepository: commentRepository,
+		PostRepository:    postRepository,
+		UserRepository:    userRepository,
+		SpamChecker:       spamChecker,
+	}
+}
+
+// List returns a page of the top level comments of a published post, with their replies nested under them
+func (s *CommentUseCase) List(ctx context.Context, request *model.CommentListRequest) ([]*model.CommentResponse, error) {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperror.NewValidationError(err)
+	}
+
+	post := new(entity.Post)
+	if err := s.PostRepository.FindPublished(tx, post, request.PostID); err != nil {
+		return nil, apperror.NewNotFoundError("post")
+	}
+
+	comments, err := s.CommentRepository.ListThreads(tx, post.ID, request.Page, request.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	// Replies come after their parents, sorted from the oldest
+	byID := make(map[uint64]*model.CommentResponse, len(comments))
+	threads := []*model.CommentResponse{}
+	for i := range comments {
+		comment := &comments[i]
+		comment.Replies = []*model.CommentResponse{}
+		byID[comment.ID] = comment
+
+		if comment.ParentID == nil {
+			threads = append(threads, comment)
+		} else if parent, ok := byID[*comment.ParentID]; ok {
+			parent.Replies = append(parent.Replies, comment)
+		}
+	}
+
+	return threads, nil
+}
+
+// Create adds a comment, approved unless the spam checker holds it for moderation
+func (s *CommentUseCase) Create(ctx context.Context, request *model.CommentCreateRequest) (*model.CommentResponse, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperror.NewValidationError(err)
+	}
+
+	// Like posting, only users with a verified email can comment
+	user := new(entity.User)
+	if err := s.UserRepository.FindByID(s.DB.WithContext(ctx), user, request.UserID); err != nil {
+		return nil, apperror.NewNotFoundError("user")
+	}
+	if user.VerifiedAt == nil {
+		return nil, apperror.NewForbiddenError(apperror.CODE_EMAIL_NOT_VERIFIED, "email is not verified")
+	}
+
+	// The spam check may call a remote service, so it runs before the transaction begins
+	status, spamReason := s.review(ctx, user, request.Content, request.IPAddress, request.UserAgent)
+
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	post := new(entity.Post)
+	if err := s.PostRepository.FindPublished(tx, post, request.PostID); err != nil {
+		return nil, apperror.NewNotFoundError("post")
+	}
+
+	// A reply must be to a visible comment of the same post
+	if request.ParentID != nil {
+		parent := new(entity.Comment)
+		if err := s.CommentRepository.FindApproved(tx, parent, post.ID, *request.ParentID); err != nil {
+			return nil, apperror.NewNotFoundError("comment")
+		}
+	}
+
+	comment := new(entity.Comment)
+	comment.PostID = post.ID
+	comment.ParentID = request.ParentID
+	comment.UserID = request.UserID
+	comment.Content = request.Content
+	comment.Status = status
+	comment.SpamReason = spamReason
+	if err := s.CommentRepository.Save(tx, comment); err != nil {
+		return nil, err
+	}
+
+	return s.commitWithResponse(tx, comment.ID)
+}
+
+// Update edits a comment, only its author can edit it. The edit is checked for spam like a new comment,
+// so an approved comment can go back to the moderation queue.
+func (s *CommentUseCase) Update(ctx context.Context, request *model.CommentUpdateRequest) (*model.CommentResponse, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperror.NewValidationError(err)
+	}
+
+	user := new(entity.User)
+	if err := s.UserRepository.FindByID(s.DB.WithContext(ctx), user, request.UserID); err != nil {
+		return nil, apperror.NewNotFoundError("user")
+	}
+	status, spamReason := s.review(ctx, user, request.Content, request.IPAddress, request.UserAgent)
+
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	comment := new(entity.Comment)
+	if err := s.CommentRepository.FindActive(tx, comment, request.ID); err != nil || comment.UserID != request.UserID {
+		return nil, apperror.NewNotFoundError("comment")
+	}
+
+	// A rejected comment stays rejected, editing it doesn't bring it back
+	comment.Content = request.Content
+	if comment.Status != constant.COMMENT_STATUS_REJECTED {
+		comment.Status = status
+		comment.SpamReason = spamReason
+	}
+	if err := s.CommentRepository.Save(tx, comment); err != nil {
+		return nil, err
+	}
+
+	return s.commitWithResponse(tx, comment.ID)
+}
+
+// Delete deletes a comment for its author, the author of its post, or a moderator. A comment with replies
+// stays in the thread without its content. Deleting the last reply of such a comment removes it too.
+func (s *CommentUseCase) Delete(ctx context.Context, request *model.CommentDeleteRequest) error {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := s.Validate.Struct(request); err != nil {
+		return apperror.NewValidationError(err)
+	}
+
+	comment := new(entity.Comment)
+	if err := s.CommentRepository.FindActive(tx, comment, request.ID); err != nil {
+		return apperror.NewNotFoundError("comment")
+	}
+
+	allowed, err := s.canModerate(tx, comment, request.UserID, request.UserRole)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return apperror.NewNotFoundError("comment")
+	}
+
+	replies, err := s.CommentRepository.CountReplies(tx, comment.ID)
+	if err != nil {
+		return err
+	}
+	if replies > 0 {
+		if err := s.CommentRepository.Tombstone(tx, comment, time.Now()); err != nil {
+			return err
+		}
+		return tx.Commit().Error
+	}
+
+	for {
+		if err := s.CommentRepository.Delete(tx, comment); err != nil {
+			return err
+		}
+		if comment.ParentID == nil {
+			break
+		}
+
+		// Walk up the deleted parents that were only kept for this reply
+		parent := new(entity.Comment)
+		if err := s.CommentRepository.FindByID(tx, parent, *comment.ParentID); err != nil {
+			return err
+		}
+		if parent.DeletedAt == nil {
+			break
+		}
+		if replies, err := s.CommentRepository.CountReplies(tx, parent.ID); err != nil {
+			return err
+		} else if replies > 0 {
+			break
+		}
+		comment = parent
+	}
+
+	return tx.Commit().Error
+}
+
+// Queue lists the comments waiting for moderation, or the comments in request.Status
+func (s *CommentUseCase) Queue(ctx context.Context, request *model.CommentQueueRequest) ([]model.CommentQueueResponse, error) {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperror.NewValidationError(err)
+	}
+	if len(request.Status) == 0 {
+		request.Status = constant.COMMENT_STATUS_PENDING
+	}
+
+	comments, err := s.CommentRepository.ListQueue(tx, request)
+	if err != nil {
+		return nil, err
+	}
+	if comments == nil {
+		comments = []model.CommentQueueResponse{}
+	}
+
+	return comments, nil
+}
+
+// Moderate approves or rejects comments in bulk, whatever their status is
+func (s *CommentUseCase) Moderate(ctx context.Context, request *model.CommentModerateRequest) (*model.CommentModerateResponse, error) {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperror.NewValidationError(err)
+	}
+
+	moderated, err := s.CommentRepository.SetStatus(tx, request.IDs, request.Status)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return &model.CommentModerateResponse{Moderated: moderated}, nil
+}
+
+// review returns the status of a comment written by user, with the reason when it is held for moderation.
+// Comments of trusted commenters are approved without a check. When the check fails, the comment waits
+// for a moderator rather than being lost.
+func (s *CommentUseCase) review(ctx context.Context, user *entity.User, content string, IPAddress string,
+	userAgent string) (string, *string) {
+	if user.TrustedCommenter {
+		return constant.COMMENT_STATUS_APPROVED, nil
+	}
+
+	verdict, err := s.SpamChecker.Check(ctx, &moderation.Submission{
+		Content:     content,
+		AuthorName:  user.Name,
+		AuthorEmail: user.Email,
+		IPAddress:   IPAddress,
+		UserAgent:   userAgent,
+	})
+	if err != nil {
+		reason := "spam check failed: " + err.Error()
+		return constant.COMMENT_STATUS_PENDING, &reason
+	}
+	if verdict.Spam {
+		return constant.COMMENT_STATUS_PENDING, &verdict.Reason
+	}
+
+	return constant.COMMENT_STATUS_APPROVED, nil
+}
+
+// canModerate tells if userID wrote comment or the post it is on, or if role can moderate every comment.
+// A post in the trash still belongs to its author, so they keep moderating its comments.
+func (s *CommentUseCase) canModerate(tx *gorm.DB, comment *entity.Comment, userID string, role string) (bool, error) {
+	if comment.UserID == userID || utils.RoleHasPermission(role, constant.PERMISSION_COMMENT_MODERATE) {
+		return true, nil
+	}
+
+	post := new(entity.Post)
+	if err := s.PostRepository.FindByID(tx.Unscoped(), post, comment.PostID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return post.UserID == userID, nil
+}
+
+func (s *CommentUseCase) commitWithResponse(tx *gorm.DB, ID uint64) (*model.CommentResponse, error) {
+	response := new(model.CommentResponse)
+	if err := s.CommentRepository.GetWithAuthor(tx, response, ID); err != nil {
+		return nil, err
+	}
+	response.Replies = []*model.CommentResponse{}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
@@ -0,0 +1,136 @@
+package usecase
+
+import (
+	"backend/internal/apperror"
+	"backend/internal/entity"
+	"backend/internal/model"
+	"backend/internal/utils"
+	"context"
+)
+
+// ListRevisions lists the revisions of a post the current user can edit, from the newest
+func (s *PostUseCase) ListRevisions(ctx context.Context, request *model.PostRevisionListRequest) ([]model.PostRevisionResponse, error) {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperror.NewValidationError(err)
+	}
+
+	post := new(entity.Post)
+	if err := findEditablePost(tx, s.PostRepository, post, request.PostID, request.UserID, request.UserRole); err != nil {
+		return nil, apperror.NewNotFoundError("post")
+	}
+
+	return s.PostRevisionRepository.ListWithAuthor(tx, post.ID)
+}
+
+func (s *PostUseCase) GetRevision(ctx context.Context, request *model.PostRevisionGetRequest) (*model.PostRevisionResponse, error) {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperror.NewValidationError(err)
+	}
+
+	post := new(entity.Post)
+	if err := findEditablePost(tx, s.PostRepository, post, request.PostID, request.UserID, request.UserRole); err != nil {
+		return nil, apperror.NewNotFoundError("post")
+	}
+
+	response := new(model.PostRevisionResponse)
+	if err := s.PostRevisionRepository.GetWithAuthor(tx, response, post.ID, request.Number); err != nil {
+		return nil, err
+	}
+	if response.Number == 0 {
+		return nil, apperror.NewNotFoundError("post revision")
+	}
+
+	return response, nil
+}
+
+// DiffRevisions compares the content of two revisions by line or by word. Titles are always compared by word.
+func (s *PostUseCase) DiffRevisions(ctx context.Context, request *model.PostRevisionDiffRequest) (*model.PostRevisionDiffResponse, error) {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperror.NewValidationError(err)
+	}
+
+	post := new(entity.Post)
+	if err := findEditablePost(tx, s.PostRepository, post, request.PostID, request.UserID, request.UserRole); err != nil {
+		return nil, apperror.NewNotFoundError("post")
+	}
+
+	from := new(entity.PostRevision)
+	if err := s.PostRevisionRepository.FindByNumber(tx, from, post.ID, request.From); err != nil {
+		return nil, apperror.NewNotFoundError("post revision")
+	}
+	to := new(entity.PostRevision)
+	if err := s.PostRevisionRepository.FindByNumber(tx, to, post.ID, request.To); err != nil {
+		return nil, apperror.NewNotFoundError("post revision")
+	}
+
+	mode := request.Mode
+	diffContent := utils.DiffLines
+	if mode == utils.DIFF_MODE_WORD {
+		diffContent = utils.DiffWords
+	} else {
+		mode = utils.DIFF_MODE_LINE
+	}
+
+	return &model.PostRevisionDiffResponse{
+		From:    from.Number,
+		To:      to.Number,
+		Mode:    mode,
+		Title:   utils.DiffWords(from.Title, to.Title),
+		Content: diffContent(from.Content, to.Content),
+	}, nil
+}
+
+// RestoreRevision makes the title and content of an old revision current again, as a new revision,
+// so the versions written after it stay in the history
+func (s *PostUseCase) RestoreRevision(ctx context.Context, request *model.PostRevisionGetRequest) (*model.PostResponse, error) {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperror.NewValidationError(err)
+	}
+
+	post := new(entity.Post)
+	if err := findEditablePost(tx, s.PostRepository, post, request.PostID, request.UserID, request.UserRole); err != nil {
+		return nil, apperror.NewNotFoundError("post")
+	}
+
+	revision := new(entity.PostRevision)
+	if err := s.PostRevisionRepository.FindByNumber(tx, revision, post.ID, request.Number); err != nil {
+		return nil, apperror.NewNotFoundError("post revision")
+	}
+
+	post.Title = revision.Title
+	post.Content = revision.Content
+	if err := s.assignSlug(tx, post); err != nil {
+		return nil, err
+	}
+	if err := s.PostRepository.Repository.Save(tx, post); err != nil {
+		return nil, err
+	}
+	if _, err := s.PostRevisionRepository.Record(tx, post, request.UserID, &revision.Number); err != nil {
+		return nil, err
+	}
+
+	response := new(model.PostResponse)
+	if err := s.PostRepository.GetWithAuthor(tx, response, post.ID); err != nil {
+		return nil, err
+	}
+	if err := s.attachRelated(ctx, tx, response); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
@@ -0,0 +1,177 @@
+package usecase
+
+import (
+	"backend/internal/apperror"
+	"backend/internal/entity"
+	"backend/internal/model"
+	"backend/internal/repository"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+const (
+	// flushBatchSize is how many posts and days the flush takes from the viewed ones at a time
+	flushBatchSize = 500
+	// flushClaimTimeout is how long the posts and days taken by a flush wait before another flush takes them over
+	flushClaimTimeout = 10 * time.Minute
+	// maxStatsDays is the longest range of days the stats of a post are read for
+	maxStatsDays = 366
+	// defaultStatsDays is the range of days read when the request has no start
+	defaultStatsDays = 30
+)
+
+type PostStatUseCase struct {
+	DB                        *gorm.DB
+	Redis                     *redis.Client
+	Validate                  *validator.Validate
+	PostRepository            *repository.PostRepository
+	PostStatRepository        *repository.PostStatRepository
+	PostViewCounterRepository *repository.PostViewCounterRepository
+	ViewWindow                time.Duration // Views of the same visitor within it are counted once
+}
+
+func NewPostStatUseCase(db *gorm.DB, redis *redis.Client, validate *validator.Validate, postRepository *repository.PostRepository,
+	postStatRepository *repository.PostStatRepository, postViewCounterRepository *repository.PostViewCounterRepository,
+	viewWindow time.Duration) *PostStatUseCase {
+	return &PostStatUseCase{
+		DB:                        db,
+		Redis:                     redis,
+		Validate:                  validate,
+		PostRepository:            postRepository,
+		PostStatRepository:        postStatRepository,
+		PostViewCounterRepository: postViewCounterRepository,
+		ViewWindow:                viewWindow,
+	}
+}
+
+// RecordView counts a view of a post in Redis, once per visitor within ViewWindow. The post must have been found
+// published by the caller, views reach the database when they are flushed.
+func (s *PostStatUseCase) RecordView(ctx context.Context, request *model.PostViewRequest) error {
+	if err := s.Validate.Struct(request); err != nil {
+		return apperror.NewValidationError(err)
+	}
+
+	// Guests are told apart by their address and browser, which are hashed so they are never stored
+	visitor := "user:" + request.UserID
+	if len(request.UserID) == 0 {
+		hash := sha256.Sum256([]byte(request.IPAddress + "\x00" + request.UserAgent))
+		visitor = "guest:" + hex.EncodeToString(hash[:])
+	}
+
+	date := time.Now().UTC().Format(time.DateOnly)
+	_, err := s.PostViewCounterRepository.Record(ctx, s.Redis, request.PostID, visitor, date, s.ViewWindow)
+
+	return err
+}
+
+// Flush adds the views counted in Redis to the daily stats of the posts in the database. Posts and days are
+// claimed from the viewed ones atomically, so replicas running it together flush different ones, and their views
+// are only forgotten in Redis once the database has them.
+func (s *PostStatUseCase) Flush(ctx context.Context) error {
+	// Claims this old belong to a flush that died, their views are still in Redis
+	if err := s.PostViewCounterRepository.Requeue(ctx, s.Redis, time.Now().Add(-flushClaimTimeout)); err != nil {
+		return err
+	}
+
+	for {
+		stats, err := s.PostViewCounterRepository.Claim(ctx, s.Redis, flushBatchSize, time.Now())
+		if err != nil {
+			return err
+		}
+		if len(stats) == 0 {
+			return nil
+		}
+
+		if err := s.flush(ctx, stats); err != nil {
+			// Left for the next run
+			if err := s.PostViewCounterRepository.Release(ctx, s.Redis, stats); err != nil {
+				return err
+			}
+			return err
+		}
+		if err := s.PostViewCounterRepository.Done(ctx, s.Redis, stats); err != nil {
+			return err
+		}
+
+		if len(stats) < flushBatchSize {
+			return nil
+		}
+	}
+}
+
+func (s *PostStatUseCase) flush(ctx context.Context, stats []entity.PostStat) error {
+	if err := s.PostViewCounterRepository.Take(ctx, s.Redis, stats); err != nil {
+		return err
+	}
+
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := s.PostStatRepository.Add(tx, stats); err != nil {
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// Get returns the views of a post on every day of the requested range. Views counted since the last flush
+// are not in it yet.
+func (s *PostStatUseCase) Get(ctx context.Context, request *model.PostStatsRequest) (*model.PostStatsResponse, error) {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperror.NewValidationError(err)
+	}
+
+	post := new(entity.Post)
+	if err := findEditablePost(tx, s.PostRepository, post, request.PostID, request.UserID, request.UserRole); err != nil {
+		return nil, apperror.NewNotFoundError("post")
+	}
+
+	// The formats are validated already
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	if len(request.To) > 0 {
+		to, _ = time.Parse(time.DateOnly, request.To)
+	}
+	from := to.AddDate(0, 0, -(defaultStatsDays - 1))
+	if len(request.From) > 0 {
+		from, _ = time.Parse(time.DateOnly, request.From)
+	}
+	days := int(to.Sub(from).Hours()/24) + 1
+	if days < 1 || days > maxStatsDays {
+		return nil, apperror.New(apperror.KIND_BAD_REQUEST, apperror.CODE_INVALID_DATE_RANGE,
+			"from must not be after to, and the range must not be longer than "+strconv.Itoa(maxStatsDays)+" days").
+			WithParam("days", strconv.Itoa(maxStatsDays))
+	}
+
+	stats, err := s.PostStatRepository.ListByPostID(tx, post.ID, from.Format(time.DateOnly), to.Format(time.DateOnly))
+	if err != nil {
+		return nil, err
+	}
+	byDate := make(map[string]entity.PostStat, len(stats))
+	for _, stat := range stats {
+		byDate[stat.Date] = stat
+	}
+
+	response := &model.PostStatsResponse{
+		PostID: post.ID,
+		From:   from.Format(time.DateOnly),
+		To:     to.Format(time.DateOnly),
+		Days:   make([]model.PostDayStatsResponse, days),
+	}
+	for i := range response.Days {
+		date := from.AddDate(0, 0, i).Format(time.DateOnly)
+		response.Days[i] = model.PostDayStatsResponse{Date: date, Views: byDate[date].Views, Uniques: byDate[date].Uniques}
+		response.Views += byDate[date].Views
+	}
+
+	return response, nil
+}
@@ -1,11 +1,15 @@
 package usecase
 
 import (
-	"backend/internal/delivery/http/exception"
+	"backend/internal/apperror"
+	"backend/internal/constant"
 	"backend/internal/entity"
 	"backend/internal/model"
 	"backend/internal/repository"
+	"backend/internal/utils"
 	"context"
+	"slices"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/redis/go-redis/v9"
@@ -13,21 +17,41 @@ import (
 )
 
 type PostUseCase struct {
-	DB             *gorm.DB
-	Redis          *redis.Client
-	Validate       *validator.Validate
-	PostRepository *repository.PostRepository
-	UserRepository *repository.UserRepository
+	DB                     *gorm.DB
+	Redis                  *redis.Client
+	Validate               *validator.Validate
+	PostRepository         *repository.PostRepository
+	PostSlugRepository     *repository.PostSlugRepository
+	PostRevisionRepository *repository.PostRevisionRepository
+	TagRepository          *repository.TagRepository
+	CategoryRepository     *repository.CategoryRepository
+	UserRepository         *repository.UserRepository
+
+	ReactionRepository        *repository.ReactionRepository
+	ReactionCounterRepository *repository.ReactionCounterRepository
+	BookmarkRepository        *repository.BookmarkRepository
 }
 
 func NewPostUseCase(db *gorm.DB, redis *redis.Client, validate *validator.Validate, postRepository *repository.PostRepository,
-	userRepository *repository.UserRepository) *PostUseCase {
+	postSlugRepository *repository.PostSlugRepository, postRevisionRepository *repository.PostRevisionRepository,
+	tagRepository *repository.TagRepository, categoryRepository *repository.CategoryRepository,
+	userRepository *repository.UserRepository, reactionRepository *repository.ReactionRepository,
+	reactionCounterRepository *repository.ReactionCounterRepository,
+	bookmarkRepository *repository.BookmarkRepository) *PostUseCase {
 	return &PostUseCase{
-		DB:             db,
-		Redis:          redis,
-		Validate:       validate,
-		PostRepository: postRepository,
-		UserRepository: userRepository,
+		DB:                     db,
+		Redis:                  redis,
+		Validate:               validate,
+		PostRepository:         postRepository,
+		PostSlugRepository:     postSlugRepository,
+		PostRevisionRepository: postRevisionRepository,
+		TagRepository:          tagRepository,
+		CategoryRepository:     categoryRepository,
+		UserRepository:         userRepository,
+
+		ReactionRepository:        reactionRepository,
+		ReactionCounterRepository: reactionCounterRepository,
+		BookmarkRepository:        bookmarkRepository,
 	}
 }
 
@@ -35,11 +59,41 @@ func (s *PostUseCase) List(ctx context.Context, request *model.PostListRequest)
 	tx := s.DB.WithContext(ctx).Begin()
 	defer tx.Rollback()
 
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperror.NewValidationError(err)
+	}
+
+	// Tags are filtered by slug, so they match however they are written. A tag without a slug can't match
+	// any post, it is refused instead of being dropped from the filter.
+	tagSlugs := make([]string, 0, len(request.Tags))
+	for _, tag := range request.Tags {
+		name, slug := utils.NormalizeTag(tag)
+		if len(slug) == 0 {
+			return nil, apperror.New(apperror.KIND_BAD_REQUEST, apperror.CODE_INVALID_TAG,
+				"tag "+name+" has no letters or digits").WithParam("tag", name)
+		}
+		if !slices.Contains(tagSlugs, slug) {
+			tagSlugs = append(tagSlugs, slug)
+		}
+	}
+	request.Tags = tagSlugs
+
 	response, err := s.PostRepository.List(tx, request)
 	if err != nil {
 		return nil, err
 	}
 
+	posts := make([]*model.PostResponse, len(response))
+	for i := range response {
+		posts[i] = &response[i]
+	}
+	if err := s.attachRelated(ctx, tx, posts...); err != nil {
+		return nil, err
+	}
+	if err := s.attachBookmarked(tx, request.ViewerID, posts...); err != nil {
+		return nil, err
+	}
+
 	return response, nil
 }
 
@@ -48,15 +102,73 @@ func (s *PostUseCase) GetByID(ctx context.Context, request *model.PostGetByIDReq
 	defer tx.Rollback()
 
 	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperror.NewValidationError(err)
+	}
+
+	// Guests only see published posts, the others don't exist for them
+	response := new(model.PostResponse)
+	if err := s.PostRepository.GetPublishedWithAuthor(tx, response, request.ID); err != nil {
+		return nil, err
+	}
+	if response.ID == 0 {
+		return nil, apperror.NewNotFoundError("post")
+	}
+	if err := s.attachRelated(ctx, tx, response); err != nil {
+		return nil, err
+	}
+	if err := s.attachBookmarked(tx, request.ViewerID, response); err != nil {
 		return nil, err
 	}
 
+	return response, nil
+}
+
+// GetBySlug returns the published post with the slug. The slug of the response differs from
+// request.Slug when the post was found by one of its previous slugs.
+func (s *PostUseCase) GetBySlug(ctx context.Context, request *model.PostGetBySlugRequest) (*model.PostResponse, error) {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperror.NewValidationError(err)
+	}
+
 	response := new(model.PostResponse)
-	if err := s.PostRepository.GetWithAuthor(tx, response, request.ID); err != nil {
+	if err := s.PostRepository.GetPublishedWithAuthorBySlug(tx, response, request.Slug); err != nil {
 		return nil, err
 	}
 	if response.ID == 0 {
-		return nil, exception.NewNotFoundError("post")
+		return nil, apperror.NewNotFoundError("post")
+	}
+	if err := s.attachRelated(ctx, tx, response); err != nil {
+		return nil, err
+	}
+	if err := s.attachBookmarked(tx, request.ViewerID, response); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (s *PostUseCase) GetEditable(ctx context.Context, request *model.PostGetEditableRequest) (*model.PostResponse, error) {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperror.NewValidationError(err)
+	}
+
+	post := new(entity.Post)
+	if err := findEditablePost(tx, s.PostRepository, post, request.ID, request.UserID, request.UserRole); err != nil {
+		return nil, apperror.NewNotFoundError("post")
+	}
+
+	response := new(model.PostResponse)
+	if err := s.PostRepository.GetWithAuthor(tx, response, post.ID); err != nil {
+		return nil, err
+	}
+	if err := s.attachRelated(ctx, tx, response); err != nil {
+		return nil, err
 	}
 
 	return response, nil
@@ -64,10 +176,20 @@ func (s *PostUseCase) GetByID(ctx context.Context, request *model.PostGetByIDReq
 
 func (s *PostUseCase) Create(ctx context.Context, request *model.PostCreateRequest) (*model.PostResponse, error) {
 	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
 
 	// Validate request
 	if err := s.Validate.Struct(request); err != nil {
-		return nil, err
+		return nil, apperror.NewValidationError(err)
+	}
+
+	// Only users with a verified email can post
+	author := new(entity.User)
+	if err := s.UserRepository.Repository.FindByID(tx, author, request.AuthorID); err != nil {
+		return nil, apperror.NewNotFoundError("user")
+	}
+	if author.VerifiedAt == nil {
+		return nil, apperror.NewForbiddenError(apperror.CODE_EMAIL_NOT_VERIFIED, "email is not verified")
 	}
 
 	// Make entity from request
@@ -76,11 +198,30 @@ func (s *PostUseCase) Create(ctx context.Context, request *model.PostCreateReque
 	post.Content = request.Content
 	post.UserID = request.AuthorID
 
-	// Save post with repository
-	if err := s.PostRepository.Repository.Save(tx, post); err != nil {
-		if err := tx.Rollback().Error; err != nil {
+	status := request.Status
+	if len(status) == 0 {
+		status = constant.POST_STATUS_DRAFT
+	}
+	if err := applyPostStatus(post, status, request.PublishedAt, time.Now()); err != nil {
+		return nil, err
+	}
+	if request.CategoryID != nil {
+		if err := s.setCategory(tx, post, *request.CategoryID); err != nil {
 			return nil, err
 		}
+	}
+	if err := s.assignSlug(tx, post); err != nil {
+		return nil, err
+	}
+
+	// Save post with repository, its first version is its first revision
+	if err := s.PostRepository.Repository.Save(tx, post); err != nil {
+		return nil, err
+	}
+	if _, err := s.PostRevisionRepository.Record(tx, post, request.AuthorID, nil); err != nil {
+		return nil, err
+	}
+	if err := s.setTags(tx, post, request.Tags); err != nil {
 		return nil, err
 	}
 	if err := tx.Commit().Error; err != nil {
@@ -89,39 +230,66 @@ func (s *PostUseCase) Create(ctx context.Context, request *model.PostCreateReque
 
 	// Confirm created post by retrieving created post from ID
 	tx = s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
 
 	response := new(model.PostResponse)
 	if err := s.PostRepository.GetWithAuthor(tx, response, post.ID); err != nil {
 		return nil, err
 	}
+	if err := s.attachRelated(ctx, tx, response); err != nil {
+		return nil, err
+	}
 
 	return response, nil
 }
 
 func (s *PostUseCase) Update(ctx context.Context, request *model.PostUpdateRequest) (*model.PostResponse, error) {
 	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
 
 	// Validate request
 	if err := s.Validate.Struct(request); err != nil {
-		return nil, err
+		return nil, apperror.NewValidationError(err)
 	}
 
-	// Check if post exists, by confirming is this post has same author with current user
+	// Check if post exists and the current user is allowed to edit it
 	post := new(entity.Post)
-	if err := s.PostRepository.GetByIDandAuthorID(tx, post, request.ID, request.AuthorID); err != nil {
-		return nil, exception.NewNotFoundError("post")
+	if err := findEditablePost(tx, s.PostRepository, post, request.ID, request.AuthorID, request.UserRole); err != nil {
+		return nil, apperror.NewNotFoundError("post")
 	}
 
-	// Make entity from request
+	// Make entity from request, an edit of the title or content is a new revision
+	edited := post.Title != request.Title || post.Content != request.Content
 	post.Title = request.Title
 	post.Content = request.Content
 
+	if len(request.Status) > 0 {
+		if err := applyPostStatus(post, request.Status, request.PublishedAt, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+	if request.CategoryID != nil {
+		if err := s.setCategory(tx, post, *request.CategoryID); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.assignSlug(tx, post); err != nil {
+		return nil, err
+	}
+
 	// Save post with repository
 	if err := s.PostRepository.Repository.Save(tx, post); err != nil {
-		if err := tx.Rollback().Error; err != nil {
+		return nil, err
+	}
+	if edited {
+		if _, err := s.PostRevisionRepository.Record(tx, post, request.AuthorID, nil); err != nil {
+			return nil, err
+		}
+	}
+	if request.Tags != nil {
+		if err := s.setTags(tx, post, request.Tags); err != nil {
 			return nil, err
 		}
-		return nil, err
 	}
 	if err := tx.Commit().Error; err != nil {
 		return nil, err
@@ -129,11 +297,15 @@ func (s *PostUseCase) Update(ctx context.Context, request *model.PostUpdateReque
 
 	// Confirm updated post by retrieving updated post from ID
 	tx = s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
 
 	response := new(model.PostResponse)
 	if err := s.PostRepository.GetWithAuthor(tx, response, post.ID); err != nil {
 		return nil, err
 	}
+	if err := s.attachRelated(ctx, tx, response); err != nil {
+		return nil, err
+	}
 
 	return response, nil
 }
@@ -144,16 +316,16 @@ func (s *PostUseCase) Delete(ctx context.Context, request *model.PostDeleteReque
 
 	// Validate request
 	if err := s.Validate.Struct(request); err != nil {
-		return nil
+		return apperror.NewValidationError(err)
 	}
 
-	// Check if post exists, by confirming is this post has same author with current user
+	// Check if post exists and the current user is allowed to delete it
 	post := new(entity.Post)
-	if err := s.PostRepository.GetByIDandAuthorID(tx, post, request.ID, request.UserID); err != nil {
-		return exception.NewNotFoundError("post")
+	if err := findEditablePost(tx, s.PostRepository, post, request.ID, request.UserID, request.UserRole); err != nil {
+		return apperror.NewNotFoundError("post")
 	}
 
-	// If post exists, delete the post
+	// If post exists, move the post to the trash
 	if err := s.PostRepository.Repository.Delete(tx, post); err != nil {
 		return err
 	}
@@ -163,3 +335,273 @@ func (s *PostUseCase) Delete(ctx context.Context, request *model.PostDeleteReque
 
 	return nil
 }
+
+// Restore takes a post out of the trash, with the status it had when it was deleted
+func (s *PostUseCase) Restore(ctx context.Context, request *model.PostRestoreRequest) (*model.PostResponse, error) {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperror.NewValidationError(err)
+	}
+
+	// Like editing, authors can only restore their own posts
+	userID := request.UserID
+	if utils.RoleHasPermission(request.UserRole, constant.PERMISSION_POST_EDIT_ANY) {
+		userID = ""
+	}
+
+	post := new(entity.Post)
+	if err := s.PostRepository.FindTrashed(tx, post, request.ID, userID); err != nil {
+		return nil, apperror.NewNotFoundError("post")
+	}
+	if err := s.PostRepository.Restore(tx, post); err != nil {
+		return nil, err
+	}
+
+	response := new(model.PostResponse)
+	if err := s.PostRepository.GetWithAuthor(tx, response, post.ID); err != nil {
+		return nil, err
+	}
+	if err := s.attachRelated(ctx, tx, response); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// PurgeTrash removes the posts that have been in the trash for longer than retention for good.
+// Only the replica holding the advisory lock purges, the others skip the run.
+func (s *PostUseCase) PurgeTrash(ctx context.Context, retention time.Duration) error {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	locked, err := repository.TryAdvisoryXactLock(tx, constant.LOCK_PURGE_TRASHED_POSTS)
+	if err != nil {
+		return err
+	}
+	if !locked {
+		return nil
+	}
+
+	if _, err := s.PostRepository.Purge(tx, time.Now().Add(-retention)); err != nil {
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// findEditablePost finds any post if role can edit every post, otherwise only a post written by userID
+func findEditablePost(tx *gorm.DB, postRepository *repository.PostRepository, post *entity.Post, ID uint64,
+	userID string, role string) error {
+	if utils.RoleHasPermission(role, constant.PERMISSION_POST_EDIT_ANY) {
+		return postRepository.Repository.FindByID(tx, post, ID)
+	}
+
+	return postRepository.GetByIDandAuthorID(tx, post, ID, userID)
+}
+
+// PublishScheduled publishes the scheduled posts that are due. Only the replica holding the
+// advisory lock publishes, the others skip the run, so every post is published exactly once.
+func (s *PostUseCase) PublishScheduled(ctx context.Context) error {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	locked, err := repository.TryAdvisoryXactLock(tx, constant.LOCK_PUBLISH_SCHEDULED_POSTS)
+	if err != nil {
+		return err
+	}
+	if !locked {
+		return nil
+	}
+
+	if _, err := s.PostRepository.PublishDue(tx, time.Now()); err != nil {
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// setTags replaces the tags of post with the tags named in names, creating the new ones.
+// Names with the same slug are the same tag.
+func (s *PostUseCase) setTags(tx *gorm.DB, post *entity.Post, names []string) error {
+	var tags []entity.Tag
+	seen := make(map[string]bool)
+
+	for _, name := range names {
+		name, slug := utils.NormalizeTag(name)
+		if len(slug) == 0 {
+			return apperror.New(apperror.KIND_BAD_REQUEST, apperror.CODE_INVALID_TAG,
+				"tag "+name+" has no letters or digits").WithParam("tag", name)
+		}
+		if seen[slug] {
+			continue
+		}
+		seen[slug] = true
+		tags = append(tags, entity.Tag{Name: name, Slug: slug})
+	}
+
+	tags, err := s.TagRepository.FindOrCreate(tx, tags)
+	if err != nil {
+		return err
+	}
+
+	tagIDs := make([]uint64, len(tags))
+	for i, tag := range tags {
+		tagIDs[i] = tag.ID
+	}
+
+	return s.TagRepository.ReplacePostTags(tx, post.ID, tagIDs)
+}
+
+// setCategory makes the category categoryID the primary category of post, or removes its category when categoryID is 0
+func (s *PostUseCase) setCategory(tx *gorm.DB, post *entity.Post, categoryID uint64) error {
+	if categoryID == 0 {
+		post.CategoryID = nil
+		return nil
+	}
+
+	count, err := s.CategoryRepository.CountByID(tx, categoryID)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return apperror.NewNotFoundError("category")
+	}
+	post.CategoryID = &categoryID
+
+	return nil
+}
+
+// attachRelated fills the tags and reaction counts of every post in posts
+func (s *PostUseCase) attachRelated(ctx context.Context, tx *gorm.DB, posts ...*model.PostResponse) error {
+	if err := s.attachTags(tx, posts...); err != nil {
+		return err
+	}
+
+	return s.attachReactions(ctx, tx, posts...)
+}
+
+// attachReactions fills the reaction counts of every post in posts, from Redis
+func (s *PostUseCase) attachReactions(ctx context.Context, tx *gorm.DB, posts ...*model.PostResponse) error {
+	postIDs := make([]uint64, len(posts))
+	for i, post := range posts {
+		postIDs[i] = post.ID
+	}
+	if len(postIDs) == 0 {
+		return nil
+	}
+
+	counts, err := loadReactionCounts(ctx, tx, s.Redis, s.ReactionRepository, s.ReactionCounterRepository, postIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, post := range posts {
+		post.Reactions = counts[post.ID]
+	}
+
+	return nil
+}
+
+// attachBookmarked flags whether viewerID bookmarked every post in posts, unless the request is anonymous
+func (s *PostUseCase) attachBookmarked(tx *gorm.DB, viewerID string, posts ...*model.PostResponse) error {
+	if len(viewerID) == 0 || len(posts) == 0 {
+		return nil
+	}
+
+	postIDs := make([]uint64, len(posts))
+	for i, post := range posts {
+		postIDs[i] = post.ID
+	}
+
+	bookmarked, err := s.BookmarkRepository.FindBookmarkedPostIDs(tx, viewerID, postIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, post := range posts {
+		isBookmarked := bookmarked[post.ID]
+		post.Bookmarked = &isBookmarked
+	}
+
+	return nil
+}
+
+// attachTags fills the tags of every post in posts
+func (s *PostUseCase) attachTags(tx *gorm.DB, posts ...*model.PostResponse) error {
+	postIDs := make([]uint64, len(posts))
+	for i, post := range posts {
+		postIDs[i] = post.ID
+	}
+	if len(postIDs) == 0 {
+		return nil
+	}
+
+	tagsByPostID, err := s.TagRepository.FindByPostIDs(tx, postIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, post := range posts {
+		post.Tags = tagsByPostID[post.ID]
+		if post.Tags == nil {
+			post.Tags = []model.TagResponse{}
+		}
+	}
+
+	return nil
+}
+
+// assignSlug gives post a slug from its title, unless its slug already comes from that title.
+// The slug a renamed post had before is kept, so links to it still find the post.
+func (s *PostUseCase) assignSlug(tx *gorm.DB, post *entity.Post) error {
+	base := utils.Slugify(post.Title)
+	if len(post.Slug) > 0 && utils.SlugHasBase(post.Slug, base) {
+		return nil
+	}
+
+	slug, err := s.PostSlugRepository.NextAvailable(tx, base, post.ID)
+	if err != nil {
+		return err
+	}
+
+	if len(post.Slug) > 0 {
+		if err := s.PostSlugRepository.Save(tx, &entity.PostSlug{Slug: post.Slug, PostID: post.ID}); err != nil {
+			return err
+		}
+		// Renaming a post back to a previous title makes the previous slug current again
+		if err := s.PostSlugRepository.DeleteByPostIDAndSlug(tx, post.ID, slug); err != nil {
+			return err
+		}
+	}
+	post.Slug = slug
+
+	return nil
+}
+
+// applyPostStatus moves post to status. A post keeps the time it was first published,
+// until it goes back to draft.
+func applyPostStatus(post *entity.Post, status string, publishedAt *time.Time, now time.Time) error {
+	switch status {
+	case constant.POST_STATUS_DRAFT:
+		post.PublishedAt = nil
+	case constant.POST_STATUS_SCHEDULED:
+		if publishedAt == nil || !publishedAt.After(now) {
+			return apperror.NewBadRequestError(apperror.CODE_INVALID_PUBLISH_TIME,
+				"published_at must be in the future to schedule a post")
+		}
+		post.PublishedAt = publishedAt
+	case constant.POST_STATUS_PUBLISHED:
+		// Publishing a scheduled post early publishes it now
+		if post.PublishedAt == nil || post.Status == constant.POST_STATUS_SCHEDULED {
+			post.PublishedAt = &now
+		}
+	}
+	post.Status = status
+
+	return nil
+}
@@ -0,0 +1,172 @@
+package usecase
+
+import (
+	"backend/internal/apperror"
+	"backend/internal/constant"
+	"backend/internal/entity"
+	"backend/internal/model"
+	"backend/internal/repository"
+	"context"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// reconcileBatchSize is how many posts the reconciliation takes from the changed posts at a time
+const reconcileBatchSize = 500
+
+type ReactionUseCase struct {
+	DB                        *gorm.DB
+	Redis                     *redis.Client
+	Validate                  *validator.Validate
+	PostRepository            *repository.PostRepository
+	ReactionRepository        *repository.ReactionRepository
+	ReactionCounterRepository *repository.ReactionCounterRepository
+}
+
+func NewReactionUseCase(db *gorm.DB, redis *redis.Client, validate *validator.Validate, postRepository *repository.PostRepository,
+	reactionRepository *repository.ReactionRepository, reactionCounterRepository *repository.ReactionCounterRepository) *ReactionUseCase {
+	return &ReactionUseCase{
+		DB:                        db,
+		Redis:                     redis,
+		Validate:                  validate,
+		PostRepository:            postRepository,
+		ReactionRepository:        reactionRepository,
+		ReactionCounterRepository: reactionCounterRepository,
+	}
+}
+
+// Toggle adds or removes a reaction of the user on a published post. The reaction is stored in the database,
+// its count is only updated in Redis and reaches the database when the post is reconciled.
+func (s *ReactionUseCase) Toggle(ctx context.Context, request *model.ReactionToggleRequest) (*model.ReactionToggleResponse, error) {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperror.NewValidationError(err)
+	}
+
+	post := new(entity.Post)
+	if err := s.PostRepository.FindPublished(tx, post, request.PostID); err != nil {
+		return nil, apperror.NewNotFoundError("post")
+	}
+
+	removed, err := s.ReactionRepository.Remove(tx, post.ID, request.UserID, request.Kind)
+	if err != nil {
+		return nil, err
+	}
+
+	// Two concurrent toggles may both find nothing to remove, only one of them adds the reaction
+	delta := int64(-1)
+	if !removed {
+		added, err := s.ReactionRepository.Add(tx, &entity.PostReaction{PostID: post.ID, UserID: request.UserID, Kind: request.Kind})
+		if err != nil {
+			return nil, err
+		}
+		delta = 0
+		if added {
+			delta = 1
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	// Counted once the reaction is committed, so a rolled back toggle never skews the count
+	if delta != 0 {
+		if err := s.ReactionCounterRepository.Increment(ctx, s.Redis, post.ID, request.Kind, delta); err != nil {
+			return nil, err
+		}
+	}
+
+	counts, err := loadReactionCounts(ctx, s.DB.WithContext(ctx), s.Redis, s.ReactionRepository,
+		s.ReactionCounterRepository, []uint64{post.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ReactionToggleResponse{
+		Kind:      request.Kind,
+		Reacted:   !removed,
+		Reactions: counts[post.ID],
+	}, nil
+}
+
+// Reconcile counts the reactions of the posts whose counts changed in Redis again from the reactions
+// in the database, stores them in the database and corrects Redis with them. Posts are taken from
+// the changed posts atomically, so replicas running it together reconcile different posts.
+func (s *ReactionUseCase) Reconcile(ctx context.Context) error {
+	for {
+		postIDs, err := s.ReactionCounterRepository.PopDirty(ctx, s.Redis, reconcileBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(postIDs) == 0 {
+			return nil
+		}
+
+		if err := s.reconcile(ctx, postIDs); err != nil {
+			// Left for the next run
+			if err := s.ReactionCounterRepository.MarkDirty(ctx, s.Redis, postIDs); err != nil {
+				return err
+			}
+			return err
+		}
+
+		if len(postIDs) < reconcileBatchSize {
+			return nil
+		}
+	}
+}
+
+func (s *ReactionUseCase) reconcile(ctx context.Context, postIDs []uint64) error {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	counts, err := s.ReactionRepository.CountByPostIDs(tx, postIDs)
+	if err != nil {
+		return err
+	}
+	if err := s.ReactionRepository.ReplaceCounts(tx, postIDs, counts); err != nil {
+		return err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	return s.ReactionCounterRepository.Replace(ctx, s.Redis, counts, postIDs)
+}
+
+// loadReactionCounts returns the reaction counts of every post of postIDs with every kind, from Redis.
+// Posts missing from Redis are read from the reconciled counts in the database and put back in Redis.
+func loadReactionCounts(ctx context.Context, tx *gorm.DB, rdb *redis.Client, reactionRepository *repository.ReactionRepository,
+	reactionCounterRepository *repository.ReactionCounterRepository, postIDs []uint64) (map[uint64]map[string]int64, error) {
+	counts, missing, err := reactionCounterRepository.Get(ctx, rdb, postIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(missing) > 0 {
+		stored, err := reactionRepository.FindCountsByPostIDs(tx, missing)
+		if err != nil {
+			return nil, err
+		}
+		if err := reactionCounterRepository.Warm(ctx, rdb, stored, missing); err != nil {
+			return nil, err
+		}
+		for _, postID := range missing {
+			counts[postID] = stored[postID]
+		}
+	}
+
+	for _, postID := range postIDs {
+		withEveryKind := make(map[string]int64, len(constant.REACTION_KINDS))
+		for _, kind := range constant.REACTION_KINDS {
+			withEveryKind[kind] = counts[postID][kind]
+		}
+		counts[postID] = withEveryKind
+	}
+
+	return counts, nil
+}
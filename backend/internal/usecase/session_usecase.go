@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"backend/internal/apperror"
+	"backend/internal/entity"
+	"backend/internal/model"
+	"backend/internal/model/converter"
+	"backend/internal/repository"
+	"context"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/redis/go-redis/v9"
+)
+
+type SessionUseCase struct {
+	Redis             *redis.Client
+	Validate          *validator.Validate
+	SessionRepository *repository.SessionRepository
+}
+
+func NewSessionUseCase(redis *redis.Client, validate *validator.Validate,
+	sessionRepository *repository.SessionRepository) *SessionUseCase {
+	return &SessionUseCase{
+		Redis:             redis,
+		Validate:          validate,
+		SessionRepository: sessionRepository,
+	}
+}
+
+// List returns every active session of the current user, flagging the one making the request
+func (s *SessionUseCase) List(ctx context.Context, currentUser *model.CurrentUser) ([]model.SessionResponse, error) {
+	sessions, err := s.SessionRepository.ListByUserID(ctx, s.Redis, currentUser.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]model.SessionResponse, len(sessions))
+	for i := range sessions {
+		response[i] = *converter.SessionToResponse(&sessions[i], currentUser.SessionID)
+	}
+
+	return response, nil
+}
+
+// Revoke logs out a single session of the current user
+func (s *SessionUseCase) Revoke(ctx context.Context, request *model.SessionRevokeRequest) error {
+	if err := s.Validate.Struct(request); err != nil {
+		return apperror.NewValidationError(err)
+	}
+
+	// Only revoke sessions owned by the current user, other users' sessions are reported as not found
+	session := new(entity.Session)
+	if err := s.SessionRepository.FindByID(ctx, s.Redis, session, request.ID); err != nil || session.UserID != request.UserID {
+		return apperror.NewNotFoundError("session")
+	}
+
+	return s.SessionRepository.Delete(ctx, s.Redis, session)
+}
+
+// RevokeAll logs out every session of the current user, including the one making the request
+func (s *SessionUseCase) RevokeAll(ctx context.Context, currentUser *model.CurrentUser) error {
+	return s.SessionRepository.DeleteByUserID(ctx, s.Redis, currentUser.ID, "")
+}
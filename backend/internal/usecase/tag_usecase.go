@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"backend/internal/apperror"
+	"backend/internal/model"
+	"backend/internal/repository"
+	"context"
+
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+type TagUseCase struct {
+	DB            *gorm.DB
+	Validate      *validator.Validate
+	TagRepository *repository.TagRepository
+}
+
+func NewTagUseCase(db *gorm.DB, validate *validator.Validate, tagRepository *repository.TagRepository) *TagUseCase {
+	return &TagUseCase{
+		DB:            db,
+		Validate:      validate,
+		TagRepository: tagRepository,
+	}
+}
+
+// List returns the tags of published posts with their number of posts, from the most used
+func (s *TagUseCase) List(ctx context.Context) ([]model.TagResponse, error) {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	return s.TagRepository.ListWithPostCount(tx)
+}
+
+// GetBySlug returns a tag for its tag page, the posts of the page come from the tag filter of the post list
+func (s *TagUseCase) GetBySlug(ctx context.Context, request *model.TagGetBySlugRequest) (*model.TagResponse, error) {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperror.NewValidationError(err)
+	}
+
+	response := new(model.TagResponse)
+	if err := s.TagRepository.GetWithPostCount(tx, response, request.Slug); err != nil {
+		return nil, err
+	}
+	if len(response.Slug) == 0 {
+		return nil, apperror.NewNotFoundError("tag")
+	}
+
+	return response, nil
+}
@@ -0,0 +1,324 @@
+package usecase
+
+import (
+	"backend/internal/apperror"
+	"backend/internal/constant"
+	"backend/internal/entity"
+	"backend/internal/model"
+	"backend/internal/utils"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// EnrollTOTP generates a new secret for the current user. Two-factor authentication is only
+// enabled once ConfirmTOTP receives a code from the authenticator app.
+func (s *UserUseCase) EnrollTOTP(ctx context.Context, currentUser *model.CurrentUser) (*model.TOTPEnrollmentResponse, error) {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	user := new(entity.User)
+	if err := s.UserRepository.Repository.FindByID(tx, user, currentUser.ID); err != nil {
+		return nil, apperror.NewNotFoundError("user")
+	}
+	if user.TOTPEnabledAt != nil {
+		return nil, apperror.NewBadRequestError(apperror.CODE_TOTP_ALREADY_ENABLED, "two-factor authentication is already enabled")
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	// Enrolling again replaces a secret that was never confirmed
+	user.TOTPSecret = &secret
+	if err := s.UserRepository.Save(tx, user); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return &model.TOTPEnrollmentResponse{
+		Secret: secret,
+		URI:    utils.GenerateTOTPURI(s.Config, user.Email, secret),
+	}, nil
+}
+
+// ConfirmTOTP enables two-factor authentication and returns the recovery codes, they are only shown once
+func (s *UserUseCase) ConfirmTOTP(ctx context.Context, request *model.TOTPCodeRequest) (*model.RecoveryCodesResponse, error) {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperror.NewValidationError(err)
+	}
+
+	user := new(entity.User)
+	if err := s.UserRepository.Repository.FindByID(tx, user, request.UserID); err != nil {
+		return nil, apperror.NewNotFoundError("user")
+	}
+	if user.TOTPEnabledAt != nil {
+		return nil, apperror.NewBadRequestError(apperror.CODE_TOTP_ALREADY_ENABLED, "two-factor authentication is already enabled")
+	}
+	if user.TOTPSecret == nil {
+		return nil, apperror.NewBadRequestError(apperror.CODE_TOTP_NOT_ENROLLED, "two-factor authentication enrollment is not started")
+	}
+
+	ok, err := s.verifyTOTPCode(ctx, user, request.Code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, apperror.NewBadRequestError(apperror.CODE_INVALID_MFA_CODE, "code doesn't match")
+	}
+
+	now := time.Now()
+	user.TOTPEnabledAt = &now
+	if err := s.UserRepository.Save(tx, user); err != nil {
+		return nil, err
+	}
+
+	codes, err := s.replaceRecoveryCodes(tx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return &model.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// DisableTOTP turns two-factor authentication off. It needs both the password and a code,
+// so a stolen session alone can't weaken the account.
+func (s *UserUseCase) DisableTOTP(ctx context.Context, request *model.TOTPDisableRequest) error {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := s.Validate.Struct(request); err != nil {
+		return apperror.NewValidationError(err)
+	}
+
+	user := new(entity.User)
+	if err := s.UserRepository.Repository.FindByID(tx, user, request.UserID); err != nil {
+		return apperror.NewNotFoundError("user")
+	}
+	if user.TOTPEnabledAt == nil {
+		return apperror.NewBadRequestError(apperror.CODE_TOTP_NOT_ENABLED, "two-factor authentication is not enabled")
+	}
+	if !utils.IsUserPasswordValid(request.Password, user.Password) {
+		return apperror.NewBadRequestError(apperror.CODE_WRONG_PASSWORD, "password doesn't match")
+	}
+
+	ok, err := s.verifySecondFactor(ctx, tx, user, request.Code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return apperror.NewBadRequestError(apperror.CODE_INVALID_MFA_CODE, "code doesn't match")
+	}
+
+	user.TOTPSecret = nil
+	user.TOTPEnabledAt = nil
+	if err := s.UserRepository.Save(tx, user); err != nil {
+		return err
+	}
+	if err := s.RecoveryCodeRepository.DeleteByUserID(tx, user.ID); err != nil {
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// RegenerateRecoveryCodes replaces every recovery code of the user, used or not
+func (s *UserUseCase) RegenerateRecoveryCodes(ctx context.Context,
+	request *model.TOTPCodeRequest) (*model.RecoveryCodesResponse, error) {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperror.NewValidationError(err)
+	}
+
+	user := new(entity.User)
+	if err := s.UserRepository.Repository.FindByID(tx, user, request.UserID); err != nil {
+		return nil, apperror.NewNotFoundError("user")
+	}
+	if user.TOTPEnabledAt == nil {
+		return nil, apperror.NewBadRequestError(apperror.CODE_TOTP_NOT_ENABLED, "two-factor authentication is not enabled")
+	}
+
+	ok, err := s.verifyTOTPCode(ctx, user, request.Code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, apperror.NewBadRequestError(apperror.CODE_INVALID_MFA_CODE, "code doesn't match")
+	}
+
+	codes, err := s.replaceRecoveryCodes(tx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return &model.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// LoginMFA completes a login started by Login with the code of the MFA challenge
+func (s *UserUseCase) LoginMFA(ctx context.Context, request *model.LoginMFARequest) (*model.TokenData, error) {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperror.NewValidationError(err)
+	}
+
+	tokenHash := utils.HashToken(request.MFAToken)
+	challengeKey := utils.GenerateMFAChallengeRedisKey(tokenHash)
+	attemptsKey := utils.GenerateMFAChallengeAttemptsRedisKey(tokenHash)
+
+	userID, err := s.Redis.Get(ctx, challengeKey).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, apperror.NewTokenError(apperror.CODE_INVALID_TOKEN)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	user := new(entity.User)
+	if err := s.UserRepository.Repository.FindByID(tx, user, userID); err != nil || user.TOTPEnabledAt == nil {
+		return nil, apperror.NewTokenError(apperror.CODE_INVALID_TOKEN)
+	}
+
+	// Codes count against the lockout of the account like passwords, a new challenge doesn't give new guesses
+	lockedFor, err := s.LoginLimiter.LockedFor(ctx, user.Email)
+	if err != nil {
+		return nil, err
+	}
+	if lockedFor > 0 {
+		return nil, accountLockedError(lockedFor)
+	}
+
+	ok, err := s.verifySecondFactor(ctx, tx, user, request.Code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if _, err := s.LoginLimiter.Fail(ctx, user.Email); err != nil {
+			return nil, err
+		}
+
+		// Too many wrong codes drop the challenge, so codes can't be guessed within its lifetime
+		attempts, err := s.Redis.Incr(ctx, attemptsKey).Result()
+		if err != nil {
+			return nil, err
+		}
+		s.Redis.Expire(ctx, attemptsKey, utils.GetMFAChallengeExpiration(s.Config))
+		if attempts >= constant.MFA_MAX_ATTEMPTS {
+			s.Redis.Del(ctx, challengeKey, attemptsKey)
+		}
+
+		return nil, apperror.NewUnauthorizedError(apperror.CODE_INVALID_MFA_CODE, "code doesn't match")
+	}
+
+	// A challenge is single-use, only the request deleting it gets a session. A recovery code is only used
+	// once tx commits, so a request losing the race for the challenge doesn't burn one. Requests with
+	// different challenges and the same recovery code are serialized by verifySecondFactor.
+	deleted, err := s.Redis.Del(ctx, challengeKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	if deleted == 0 {
+		return nil, apperror.NewTokenError(apperror.CODE_INVALID_TOKEN)
+	}
+	s.Redis.Del(ctx, attemptsKey)
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	if err := s.LoginLimiter.Succeed(ctx, user.Email); err != nil {
+		return nil, err
+	}
+
+	return s.startSession(ctx, user, request.UserAgent, request.IPAddress)
+}
+
+// startMFAChallenge returns the token to send with the code in LoginMFA
+func (s *UserUseCase) startMFAChallenge(ctx context.Context, user *entity.User) (*model.TokenData, error) {
+	token, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	key := utils.GenerateMFAChallengeRedisKey(utils.HashToken(token))
+	if err := s.Redis.SetEx(ctx, key, user.ID, utils.GetMFAChallengeExpiration(s.Config)).Err(); err != nil {
+		return nil, err
+	}
+
+	return &model.TokenData{
+		MFARequired: true,
+		MFAToken:    token,
+	}, nil
+}
+
+// verifySecondFactor accepts a TOTP code or an unused recovery code, which is marked as used in tx.
+// A recovery code used by another transaction waits for it, and is refused once it commits.
+func (s *UserUseCase) verifySecondFactor(ctx context.Context, tx *gorm.DB, user *entity.User, code string) (bool, error) {
+	if !utils.IsRecoveryCodeFormat(code) {
+		return s.verifyTOTPCode(ctx, user, code)
+	}
+
+	return s.RecoveryCodeRepository.Use(tx, user.ID, utils.HashRecoveryCode(code), time.Now())
+}
+
+// verifyTOTPCode checks code against the secret of user. Each code is accepted once.
+func (s *UserUseCase) verifyTOTPCode(ctx context.Context, user *entity.User, code string) (bool, error) {
+	if user.TOTPSecret == nil {
+		return false, nil
+	}
+
+	step, ok := utils.ValidateTOTPCode(*user.TOTPSecret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+
+	// The key outlives every period in which the code is accepted
+	ttl := time.Duration((2*utils.TOTP_SKEW+1)*utils.TOTP_PERIOD) * time.Second
+	unused, err := s.Redis.SetNX(ctx, utils.GenerateTOTPUsedRedisKey(user.ID, step), 1, ttl).Result()
+	if err != nil {
+		return false, err
+	}
+
+	return unused, nil
+}
+
+// replaceRecoveryCodes deletes the recovery codes of the user and returns new ones, only their hashes are stored
+func (s *UserUseCase) replaceRecoveryCodes(tx *gorm.DB, userID string) ([]string, error) {
+	if err := s.RecoveryCodeRepository.DeleteByUserID(tx, userID); err != nil {
+		return nil, err
+	}
+
+	codes, err := utils.GenerateRecoveryCodes(constant.RECOVERY_CODE_COUNT)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, code := range codes {
+		recoveryCode := &entity.RecoveryCode{
+			UserID:    userID,
+			CodeHash:  utils.HashRecoveryCode(code),
+			CreatedAt: time.Now(),
+		}
+		if err := s.RecoveryCodeRepository.Save(tx, recoveryCode); err != nil {
+			return nil, err
+		}
+	}
+
+	return codes, nil
+}
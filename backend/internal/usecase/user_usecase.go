@@ -1,12 +1,23 @@
 package usecase
 
 import (
-	"backend/internal/delivery/http/exception"
+	"backend/internal/apperror"
+	"backend/internal/constant"
 	"backend/internal/entity"
+	"backend/internal/i18n"
+	"backend/internal/limiter"
+	"backend/internal/mail"
 	"backend/internal/model"
+	"backend/internal/model/converter"
 	"backend/internal/repository"
 	"backend/internal/utils"
 	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/go-playground/validator/v10"
@@ -16,21 +27,33 @@ import (
 )
 
 type UserUseCase struct {
-	DB             *gorm.DB
-	Redis          *redis.Client
-	Validate       *validator.Validate
-	UserRepository *repository.UserRepository
-	Config         *viper.Viper
+	DB                     *gorm.DB
+	Redis                  *redis.Client
+	Validate               *validator.Validate
+	UserRepository         *repository.UserRepository
+	SessionRepository      *repository.SessionRepository
+	RecoveryCodeRepository *repository.RecoveryCodeRepository
+	LoginLimiter           *limiter.LoginLimiter
+	Config                 *viper.Viper
+	KeyRing                *utils.KeyRing
+	Mailer                 mail.Mailer
 }
 
 func NewUserUseCase(db *gorm.DB, redis *redis.Client, validate *validator.Validate,
-	userRepository *repository.UserRepository, config *viper.Viper) *UserUseCase {
+	userRepository *repository.UserRepository, sessionRepository *repository.SessionRepository,
+	recoveryCodeRepository *repository.RecoveryCodeRepository, loginLimiter *limiter.LoginLimiter,
+	config *viper.Viper, keyRing *utils.KeyRing, mailer mail.Mailer) *UserUseCase {
 	return &UserUseCase{
-		DB:             db,
-		Redis:          redis,
-		Validate:       validate,
-		UserRepository: userRepository,
-		Config:         config,
+		DB:                     db,
+		Redis:                  redis,
+		Validate:               validate,
+		UserRepository:         userRepository,
+		SessionRepository:      sessionRepository,
+		RecoveryCodeRepository: recoveryCodeRepository,
+		LoginLimiter:           loginLimiter,
+		Config:                 config,
+		KeyRing:                keyRing,
+		Mailer:                 mailer,
 	}
 }
 
@@ -39,12 +62,12 @@ func (s *UserUseCase) Create(ctx context.Context, request *model.RegisterUserReq
 	defer tx.Rollback()
 
 	if err := s.Validate.Struct(request); err != nil {
-		return err
+		return apperror.NewValidationError(err)
 	}
 
 	var user entity.User
 	if _ = s.UserRepository.FindByEmail(tx, &user, request.Email); len(user.ID) > 0 {
-		return exception.NewConflictError("user")
+		return apperror.NewConflictError("user")
 	}
 
 	userPassword, err := utils.HashUserPassword(request.Password)
@@ -56,6 +79,7 @@ func (s *UserUseCase) Create(ctx context.Context, request *model.RegisterUserReq
 		Name:      request.Name,
 		Email:     request.Email,
 		Password:  userPassword,
+		Role:      constant.ROLE_READER,
 		CreatedAt: time.Now(),
 	}
 	if err := s.UserRepository.Save(tx, &user); err != nil {
@@ -65,6 +89,11 @@ func (s *UserUseCase) Create(ctx context.Context, request *model.RegisterUserReq
 		return err
 	}
 
+	// The user is registered even if the email can't be sent, they can ask for another one
+	if err := s.sendVerificationEmail(ctx, &user); err != nil {
+		log.Println("sending verification email:", err)
+	}
+
 	return nil
 }
 
@@ -74,49 +103,102 @@ func (s *UserUseCase) Login(ctx context.Context, request *model.LoginUserRequest
 
 	// Validate request
 	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperror.NewValidationError(err)
+	}
+
+	// A locked account refuses every attempt, even with the right password
+	lockedFor, err := s.LoginLimiter.LockedFor(ctx, request.Email)
+	if err != nil {
 		return nil, err
 	}
+	if lockedFor > 0 {
+		return nil, accountLockedError(lockedFor)
+	}
+
+	retryAfter, err := s.LoginLimiter.Allow(ctx, request.IPAddress, request.Email)
+	if err != nil {
+		return nil, err
+	}
+	if retryAfter > 0 {
+		return nil, apperror.NewRateLimitedError(apperror.CODE_TOO_MANY_LOGINS,
+			fmt.Sprintf("too many login attempts. retry in %d seconds", int(math.Ceil(retryAfter.Seconds()))), retryAfter)
+	}
 
 	// Check if user exists
 	userFound := new(entity.User)
 	if err := s.UserRepository.FindByEmail(tx, userFound, request.Email); err != nil || userFound.ID == "" {
-		return nil, exception.NewUnauthorizedError("user not found")
+		// Unknown emails count as failures too, so lockouts don't tell which emails are registered
+		if _, err := s.LoginLimiter.Fail(ctx, request.Email); err != nil {
+			return nil, err
+		}
+		return nil, apperror.NewUnauthorizedError(apperror.CODE_INVALID_CREDENTIALS, "user not found")
 	}
 
 	// If user exits, check password
 	if !utils.IsUserPasswordValid(request.Password, userFound.Password) {
-		return nil, exception.NewUnauthorizedError("password doesn't match")
+		if _, err := s.LoginLimiter.Fail(ctx, request.Email); err != nil {
+			return nil, err
+		}
+		return nil, apperror.NewUnauthorizedError(apperror.CODE_INVALID_CREDENTIALS, "password doesn't match")
 	}
 
-	// If password valid, generate token
-	response := new(model.TokenData)
-	var (
-		err           error
-		accessExpDur  time.Duration
-		refreshExpDur time.Duration
-	)
+	// With two-factor authentication the password only gets a challenge, the tokens come with the code.
+	// The failures are only forgotten once the code is right, so wrong codes add up across challenges.
+	if userFound.TOTPEnabledAt != nil {
+		return s.startMFAChallenge(ctx, userFound)
+	}
 
-	response.AccessToken, accessExpDur, err = utils.GenerateAccessToken(s.Config, userFound)
-	if err != nil {
+	if err := s.LoginLimiter.Succeed(ctx, request.Email); err != nil {
 		return nil, err
 	}
 
-	response.RefreshToken, refreshExpDur, err = utils.GenerateRefreshToken(s.Config, userFound)
+	return s.startSession(ctx, userFound, request.UserAgent, request.IPAddress)
+}
+
+// accountLockedError is the error of a login refused because the account is locked for lockedFor
+func accountLockedError(lockedFor time.Duration) error {
+	return apperror.NewRateLimitedError(apperror.CODE_ACCOUNT_LOCKED,
+		fmt.Sprintf("account is locked after too many failed logins. retry in %d seconds",
+			int(math.Ceil(lockedFor.Seconds()))), lockedFor)
+}
+
+// startSession starts a new session for a logged in user. Other sessions of the user stay logged in.
+func (s *UserUseCase) startSession(ctx context.Context, user *entity.User, userAgent string,
+	IPAddress string) (*model.TokenData, error) {
+	sessionID, err := utils.GenerateSecureToken(24)
 	if err != nil {
 		return nil, err
 	}
 
-	response.RefreshExpAt = time.Now().Add(refreshExpDur)
+	response := new(model.TokenData)
+	var refreshExpDur time.Duration
 
-	// Store both in redis
-	if err := s.Redis.SetEx(ctx, utils.GenerateAccessTokenRedisKey(userFound.ID),
-		response.AccessToken, accessExpDur).Err(); err != nil {
-		return nil, exception.NewInternalServerError(err.Error())
+	response.AccessToken, _, err = utils.GenerateAccessToken(s.Config, s.KeyRing, user, sessionID)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := s.Redis.SetEx(ctx, utils.GenerateRefreshTokenRedisKey(userFound.ID),
-		response.RefreshToken, refreshExpDur).Err(); err != nil {
-		return nil, exception.NewInternalServerError(err.Error())
+	response.RefreshToken, refreshExpDur, err = utils.GenerateRefreshToken(s.Config, user, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	response.RefreshExpAt = now.Add(refreshExpDur)
+
+	// Store the session in redis, it lives as long as the refresh token
+	session := &entity.Session{
+		ID:               sessionID,
+		UserID:           user.ID,
+		UserAgent:        userAgent,
+		IPAddress:        IPAddress,
+		RefreshTokenHash: utils.HashToken(response.RefreshToken),
+		CreatedAt:        now,
+		LastSeenAt:       now,
+		ExpiresAt:        response.RefreshExpAt,
+	}
+	if err := s.SessionRepository.Save(ctx, s.Redis, session); err != nil {
+		return nil, apperror.NewInternalError(err)
 	}
 
 	return response, nil
@@ -132,6 +214,10 @@ func (s *UserUseCase) Current(ctx context.Context, currentUser *model.CurrentUse
 	}
 
 	currentUser.Name = user.Email
+	currentUser.Role = user.Role
+	currentUser.VerifiedAt = user.VerifiedAt
+	currentUser.TOTPEnabled = user.TOTPEnabledAt != nil
+	currentUser.Locale = user.Locale
 	currentUser.CreatedAt = user.CreatedAt
 
 	return nil
@@ -144,49 +230,412 @@ func (s *UserUseCase) Refresh(ctx context.Context, tokenData *model.TokenData) e
 		return err
 	}
 
-	// Check if refresh is available in redis
-	refreshTokenRedisKey := utils.GenerateRefreshTokenRedisKey(userAuthData.UserID)
-	redisRefreshToken, err := s.Redis.Get(ctx, refreshTokenRedisKey).Result()
-	if err != nil {
-		return exception.NewUnauthorizedError(exception.InvalidTokenMsg)
+	// Check if the session of the refresh token is still active
+	session := new(entity.Session)
+	if err := s.SessionRepository.FindByID(ctx, s.Redis, session, userAuthData.SessionID); err != nil ||
+		session.UserID != userAuthData.UserID {
+		return apperror.NewTokenError(apperror.CODE_INVALID_TOKEN)
 	}
 
-	// Check if request refresh token is same as redis
-	if tokenData.RefreshToken != redisRefreshToken {
-		return exception.NewUnauthorizedError(exception.InvalidTokenMsg)
+	// Load the user again, so the new access token carries the current role
+	user := new(entity.User)
+	if err := s.UserRepository.Repository.FindByID(s.DB.WithContext(ctx), user, userAuthData.UserID); err != nil {
+		return apperror.NewTokenError(apperror.CODE_INVALID_TOKEN)
 	}
 
-	// Generate new access token
-	newAccessToken, expTimeAccessTokenDur, err := utils.GenerateAccessToken(s.Config, &entity.User{
-		ID:    userAuthData.UserID,
-		Email: userAuthData.UserEmail,
-	})
-	tokenData.AccessToken = newAccessToken
-	// Set new access token on redis
-	accessTokenRedisKey := utils.GenerateAccessTokenRedisKey(userAuthData.UserID)
-	if err := s.Redis.SetEx(ctx, accessTokenRedisKey, newAccessToken, expTimeAccessTokenDur).Err(); err != nil {
+	// Every refresh issues a new refresh token for the same session, the presented one can't be used again
+	newRefreshToken, refreshExpDur, err := utils.GenerateRefreshToken(s.Config, user, session.ID)
+	if err != nil {
 		return err
 	}
+	refreshExpAt := time.Now().Add(refreshExpDur)
+
+	// Rotate fails if the presented token isn't the current one. A reused token revokes the whole session.
+	if err := s.SessionRepository.Rotate(ctx, s.Redis, session, utils.HashToken(tokenData.RefreshToken),
+		utils.HashToken(newRefreshToken), refreshExpAt); err != nil {
+		return apperror.NewTokenError(apperror.CODE_INVALID_TOKEN)
+	}
 
+	// Generate new access token for the same session
+	tokenData.AccessToken, _, err = utils.GenerateAccessToken(s.Config, s.KeyRing, user, session.ID)
 	if err != nil {
 		return err
 	}
+	tokenData.RefreshToken = newRefreshToken
+	tokenData.RefreshExpAt = refreshExpAt
 
 	return nil
 }
 
 func (s *UserUseCase) Logout(ctx context.Context, currentUser *model.CurrentUser) error {
-	// Delete refresh token from redis
-	refreshTokenRedisKey := utils.GenerateAccessTokenRedisKey(currentUser.ID)
-	if err := s.Redis.Del(ctx, refreshTokenRedisKey).Err(); err != nil {
+	// Delete the current session, other sessions of the user stay logged in
+	return s.SessionRepository.Delete(ctx, s.Redis, &entity.Session{
+		ID:     currentUser.SessionID,
+		UserID: currentUser.ID,
+	})
+}
+
+func (s *UserUseCase) List(ctx context.Context, request *model.UserListRequest) ([]model.UserResponse, error) {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	var users []entity.User
+	if err := s.UserRepository.List(tx, &users, request); err != nil {
+		return nil, err
+	}
+
+	response := make([]model.UserResponse, len(users))
+	for i := range users {
+		response[i] = *converter.UserToResponse(&users[i])
+	}
+
+	return response, nil
+}
+
+// UpdateRole assigns a new role to a user. The new role is used from the next login or token refresh.
+func (s *UserUseCase) UpdateRole(ctx context.Context, request *model.UpdateUserRoleRequest) (*model.UserResponse, error) {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperror.NewValidationError(err)
+	}
+
+	// Admins can't change their own role, so there is always an admin left to fix mistakes
+	if request.UserID == request.ChangedBy {
+		return nil, apperror.NewForbiddenError(apperror.CODE_OWN_ROLE_CHANGE, "can't change your own role")
+	}
+
+	user := new(entity.User)
+	if err := s.UserRepository.Repository.FindByID(tx, user, request.UserID); err != nil {
+		return nil, apperror.NewNotFoundError("user")
+	}
+
+	user.Role = request.Role
+	if err := s.UserRepository.Save(tx, user); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return converter.UserToResponse(user), nil
+}
+
+// UpdateTrustedCommenter sets whether the comments of a user are approved without a spam check
+func (s *UserUseCase) UpdateTrustedCommenter(ctx context.Context, request *model.UpdateTrustedCommenterRequest) (*model.UserResponse, error) {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperror.NewValidationError(err)
+	}
+
+	user := new(entity.User)
+	if err := s.UserRepository.Repository.FindByID(tx, user, request.UserID); err != nil {
+		return nil, apperror.NewNotFoundError("user")
+	}
+
+	user.TrustedCommenter = request.Trusted
+	if err := s.UserRepository.Save(tx, user); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return converter.UserToResponse(user), nil
+}
+
+// UpdateLocale sets the locale of the messages sent to the user. Access tokens carry the locale,
+// so it applies from the next login or token refresh.
+func (s *UserUseCase) UpdateLocale(ctx context.Context, request *model.UpdateLocaleRequest) error {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := s.Validate.Struct(request); err != nil {
+		return apperror.NewValidationError(err)
+	}
+
+	if request.Locale != "" && !i18n.Default.Supports(request.Locale) {
+		return apperror.New(apperror.KIND_BAD_REQUEST, apperror.CODE_UNSUPPORTED_LOCALE,
+			"locale "+request.Locale+" is not supported").WithParam("locale", request.Locale)
+	}
+
+	user := new(entity.User)
+	if err := s.UserRepository.Repository.FindByID(tx, user, request.UserID); err != nil {
+		return apperror.NewNotFoundError("user")
+	}
+
+	user.Locale = request.Locale
+	if err := s.UserRepository.Save(tx, user); err != nil {
 		return err
 	}
 
-	// Delete acess token from redis
-	accessTokenRedisKey := utils.GenerateRefreshTokenRedisKey(currentUser.ID)
-	if err := s.Redis.Del(ctx, accessTokenRedisKey).Err(); err != nil {
+	return tx.Commit().Error
+}
+
+// Unlock lifts the login lockout of a user before it expires
+func (s *UserUseCase) Unlock(ctx context.Context, request *model.UnlockUserRequest) (*model.UserResponse, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperror.NewValidationError(err)
+	}
+
+	user := new(entity.User)
+	if err := s.UserRepository.Repository.FindByID(s.DB.WithContext(ctx), user, request.UserID); err != nil {
+		return nil, apperror.NewNotFoundError("user")
+	}
+
+	if err := s.LoginLimiter.Unlock(ctx, user.Email); err != nil {
+		return nil, err
+	}
+
+	return converter.UserToResponse(user), nil
+}
+
+// Verify marks the email of the token's user as verified. Each token can only be used once.
+func (s *UserUseCase) Verify(ctx context.Context, request *model.VerifyEmailRequest) error {
+	if err := s.Validate.Struct(request); err != nil {
+		return apperror.NewValidationError(err)
+	}
+
+	tokenData, err := utils.ParseVerificationToken(s.Config, request.Token)
+	if err != nil {
+		return apperror.NewBadRequestError(apperror.CODE_INVALID_VERIFICATION_TOKEN, "invalid verification token")
+	}
+
+	// Consume the token, a second attempt with the same token finds nothing
+	userID, err := s.Redis.GetDel(ctx, utils.GenerateVerificationRedisKey(tokenData.TokenID)).Result()
+	if errors.Is(err, redis.Nil) {
+		return apperror.NewBadRequestError(apperror.CODE_INVALID_VERIFICATION_TOKEN,
+			"verification token has expired or has already been used")
+	}
+	if err != nil {
 		return err
 	}
+	if userID != tokenData.UserID {
+		return apperror.NewBadRequestError(apperror.CODE_INVALID_VERIFICATION_TOKEN, "invalid verification token")
+	}
 
-	return nil
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	user := new(entity.User)
+	if err := s.UserRepository.Repository.FindByID(tx, user, tokenData.UserID); err != nil {
+		return apperror.NewNotFoundError("user")
+	}
+
+	// The token only verifies the address it was sent to
+	if user.Email != tokenData.Email {
+		return apperror.NewBadRequestError(apperror.CODE_INVALID_VERIFICATION_TOKEN, "invalid verification token")
+	}
+
+	if user.VerifiedAt != nil {
+		return nil
+	}
+
+	now := time.Now()
+	user.VerifiedAt = &now
+	if err := s.UserRepository.Save(tx, user); err != nil {
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// ResendVerification sends a new verification email. It succeeds for unknown and verified emails too,
+// so the response doesn't tell whether an email is registered.
+func (s *UserUseCase) ResendVerification(ctx context.Context, request *model.ResendVerificationRequest) error {
+	if err := s.Validate.Struct(request); err != nil {
+		return apperror.NewValidationError(err)
+	}
+
+	// Throttle by email before looking the user up, so the throttle doesn't reveal registered emails either
+	email := strings.ToLower(request.Email)
+	throttleKey := utils.GenerateVerificationThrottleRedisKey(email)
+	interval := utils.GetVerificationResendInterval(s.Config)
+
+	ok, err := s.Redis.SetNX(ctx, throttleKey, 1, interval).Result()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		retryAfter, _ := s.Redis.TTL(ctx, throttleKey).Result()
+		return apperror.NewRateLimitedError(apperror.CODE_EMAIL_SENT_RECENTLY,
+			fmt.Sprintf("verification email was sent recently. retry in %d seconds", int(retryAfter.Seconds())), retryAfter)
+	}
+
+	user := new(entity.User)
+	if err := s.UserRepository.FindByEmail(s.DB.WithContext(ctx), user, request.Email); err != nil || user.VerifiedAt != nil {
+		return nil
+	}
+
+	return s.sendVerificationEmail(ctx, user)
+}
+
+func (s *UserUseCase) sendVerificationEmail(ctx context.Context, user *entity.User) error {
+	token, tokenID, expDur, err := utils.GenerateVerificationToken(s.Config, user)
+	if err != nil {
+		return err
+	}
+
+	// The token stays usable until it expires or is used
+	if err := s.Redis.SetEx(ctx, utils.GenerateVerificationRedisKey(tokenID), user.ID, expDur).Err(); err != nil {
+		return err
+	}
+
+	link := s.Config.GetString("app.frontendUrl") + "/verify-email?token=" + url.QueryEscape(token)
+
+	return s.Mailer.Send(ctx, &mail.Message{
+		To:      []string{user.Email},
+		Subject: "Verify your email",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease verify your email by opening this link:\n%s\n\n"+
+			"The link expires in %d hours. If you didn't register, you can ignore this email.\n",
+			user.Name, link, int(expDur.Hours())),
+	})
+}
+
+// ChangePassword replaces the password of the current user and logs out every other session
+func (s *UserUseCase) ChangePassword(ctx context.Context, request *model.ChangePasswordRequest) error {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := s.Validate.Struct(request); err != nil {
+		return apperror.NewValidationError(err)
+	}
+
+	user := new(entity.User)
+	if err := s.UserRepository.Repository.FindByID(tx, user, request.UserID); err != nil {
+		return apperror.NewNotFoundError("user")
+	}
+
+	if !utils.IsUserPasswordValid(request.OldPassword, user.Password) {
+		return apperror.NewBadRequestError(apperror.CODE_WRONG_PASSWORD, "old password doesn't match")
+	}
+
+	if err := s.updatePassword(tx, user, request.NewPassword); err != nil {
+		return err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	return s.SessionRepository.DeleteByUserID(ctx, s.Redis, user.ID, request.SessionID)
+}
+
+// ForgotPassword emails a password reset link. It succeeds for unknown emails too,
+// so the response doesn't tell whether an email is registered.
+func (s *UserUseCase) ForgotPassword(ctx context.Context, request *model.ForgotPasswordRequest) error {
+	if err := s.Validate.Struct(request); err != nil {
+		return apperror.NewValidationError(err)
+	}
+
+	// Throttle by email before looking the user up, so the throttle doesn't reveal registered emails either
+	email := strings.ToLower(request.Email)
+	throttleKey := utils.GeneratePasswordResetThrottleRedisKey(email)
+
+	ok, err := s.Redis.SetNX(ctx, throttleKey, 1, utils.GetPasswordResetResendInterval(s.Config)).Result()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		retryAfter, _ := s.Redis.TTL(ctx, throttleKey).Result()
+		return apperror.NewRateLimitedError(apperror.CODE_EMAIL_SENT_RECENTLY,
+			fmt.Sprintf("password reset email was sent recently. retry in %d seconds", int(retryAfter.Seconds())), retryAfter)
+	}
+
+	user := new(entity.User)
+	if err := s.UserRepository.FindByEmail(s.DB.WithContext(ctx), user, request.Email); err != nil {
+		return nil
+	}
+
+	token, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return err
+	}
+	tokenHash := utils.HashToken(token)
+	expDur := utils.GetPasswordResetExpiration(s.Config)
+
+	// Only the latest token of a user works, so drop the previous one
+	userResetKey := utils.GenerateUserPasswordResetRedisKey(user.ID)
+	previousHash, err := s.Redis.Get(ctx, userResetKey).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+
+	if _, err := s.Redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if previousHash != "" {
+			pipe.Del(ctx, utils.GeneratePasswordResetRedisKey(previousHash))
+		}
+		pipe.SetEx(ctx, utils.GeneratePasswordResetRedisKey(tokenHash), user.ID, expDur)
+		pipe.SetEx(ctx, userResetKey, tokenHash, expDur)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	link := s.Config.GetString("app.frontendUrl") + "/reset-password?token=" + url.QueryEscape(token)
+
+	return s.Mailer.Send(ctx, &mail.Message{
+		To:      []string{user.Email},
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nYou can choose a new password by opening this link:\n%s\n\n"+
+			"The link expires in %d minutes and can only be used once. "+
+			"If you didn't ask for a new password, you can ignore this email.\n",
+			user.Name, link, int(expDur.Minutes())),
+	})
+}
+
+// ResetPassword sets a new password with a token from ForgotPassword and logs out every session
+func (s *UserUseCase) ResetPassword(ctx context.Context, request *model.ResetPasswordRequest) error {
+	if err := s.Validate.Struct(request); err != nil {
+		return apperror.NewValidationError(err)
+	}
+
+	// Consume the token, a second attempt with the same token finds nothing
+	userID, err := s.Redis.GetDel(ctx, utils.GeneratePasswordResetRedisKey(utils.HashToken(request.Token))).Result()
+	if errors.Is(err, redis.Nil) {
+		return apperror.NewBadRequestError(apperror.CODE_INVALID_RESET_TOKEN, "reset token has expired or has already been used")
+	}
+	if err != nil {
+		return err
+	}
+
+	tx := s.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	user := new(entity.User)
+	if err := s.UserRepository.Repository.FindByID(tx, user, userID); err != nil {
+		return apperror.NewNotFoundError("user")
+	}
+
+	// Opening the emailed link proves the user owns the email
+	if user.VerifiedAt == nil {
+		now := time.Now()
+		user.VerifiedAt = &now
+	}
+
+	if err := s.updatePassword(tx, user, request.NewPassword); err != nil {
+		return err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	if err := s.Redis.Del(ctx, utils.GenerateUserPasswordResetRedisKey(user.ID)).Err(); err != nil {
+		return err
+	}
+
+	return s.SessionRepository.DeleteByUserID(ctx, s.Redis, user.ID, "")
+}
+
+func (s *UserUseCase) updatePassword(tx *gorm.DB, user *entity.User, plainPassword string) error {
+	hashed, err := utils.HashUserPassword(plainPassword)
+	if err != nil {
+		return err
+	}
+
+	user.Password = hashed
+
+	return s.UserRepository.Save(tx, user)
 }
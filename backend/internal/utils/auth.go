@@ -1,9 +1,10 @@
 package utils
 
 import (
-	"backend/internal/delivery/http/exception"
+	"backend/internal/apperror"
 	"backend/internal/entity"
 	"backend/internal/model"
+	"errors"
 	"fmt"
 	"time"
 
@@ -11,56 +12,67 @@ import (
 	"github.com/spf13/viper"
 )
 
-func GenerateAccessToken(viperConfig *viper.Viper, user *entity.User) (string, time.Duration, error) {
-	key := viperConfig.GetString("auth.accessTokenKey")
+// GenerateAccessToken signs an access token with the active key of keyRing, so other services
+// can verify it with the public keys from /.well-known/jwks.json
+func GenerateAccessToken(viperConfig *viper.Viper, keyRing *KeyRing, user *entity.User, sessionID string) (string, time.Duration, error) {
 	expMinutes := viperConfig.GetInt("auth.accessTokenExpMinutes")
 
-	return GenerateAuthToken(user, key, expMinutes)
+	return GenerateAuthToken(user, sessionID, keyRing, expMinutes)
 }
 
-func GenerateRefreshToken(viperConfig *viper.Viper, user *entity.User) (string, time.Duration, error) {
+// GenerateRefreshToken signs a refresh token with the HS256 auth.refreshTokenKey, since only this service verifies them
+func GenerateRefreshToken(viperConfig *viper.Viper, user *entity.User, sessionID string) (string, time.Duration, error) {
 	key := viperConfig.GetString("auth.refreshTokenKey")
 	expMinutes := viperConfig.GetInt("auth.refreshTokenExpMinutes")
 
-	return GenerateAuthToken(user, key, expMinutes)
+	return GenerateAuthToken(user, sessionID, NewHMACKeyRing(key), expMinutes)
 }
 
-func GenerateAuthToken(user *entity.User, key string, expMinutes int) (string, time.Duration, error) {
+func GenerateAuthToken(user *entity.User, sessionID string, keyRing *KeyRing, expMinutes int) (string, time.Duration, error) {
 	timeDuration := time.Duration(expMinutes) * time.Minute
 	timeExp := time.Now().Add(timeDuration)
 
-	t := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+	// A random token ID makes every token unique, even when issued in the same second for the same session
+	tokenID, err := GenerateSecureToken(16)
+	if err != nil {
+		return "", timeDuration, err
+	}
+
+	s, err := keyRing.Sign(jwt.MapClaims{
 		"exp": timeExp.Unix(),
+		"jti": tokenID,
 		"data": model.UserAuthData{
-			UserID:    user.ID,
-			UserEmail: user.Email,
-			UserName:  user.Name,
+			UserID:     user.ID,
+			UserEmail:  user.Email,
+			UserName:   user.Name,
+			UserRole:   user.Role,
+			UserLocale: user.Locale,
+			SessionID:  sessionID,
 		},
 	})
 
-	s, err := t.SignedString([]byte(key))
-
 	return s, timeDuration, err
 }
 
-func ParseAccessToken(viperConfig *viper.Viper, token string) (*model.UserAuthData, error) {
-	key := viperConfig.GetString("auth.accessTokenKey")
-
-	return ParseAuthToken(token, key)
+func ParseAccessToken(keyRing *KeyRing, token string) (*model.UserAuthData, error) {
+	return ParseAuthToken(token, keyRing)
 }
 
 func ParseRefreshToken(viperConfig *viper.Viper, token string) (*model.UserAuthData, error) {
 	key := viperConfig.GetString("auth.refreshTokenKey")
 
-	return ParseAuthToken(token, key)
+	return ParseAuthToken(token, NewHMACKeyRing(key))
 }
 
-func ParseAuthToken(token string, key string) (*model.UserAuthData, error) {
-	t, err := jwt.Parse(token, func(t *jwt.Token) (interface{}, error) {
-		return []byte(key), nil
-	})
+func ParseAuthToken(token string, keyRing *KeyRing) (*model.UserAuthData, error) {
+	t, err := jwt.Parse(token, keyRing.Keyfunc)
 	if err != nil {
-		return nil, exception.NewUnauthorizedError(exception.InvalidTokenMsg)
+		// A correctly signed but expired token lets the frontend request a new access token
+		var validationErr *jwt.ValidationError
+		if errors.As(err, &validationErr) && validationErr.Errors == jwt.ValidationErrorExpired {
+			return nil, apperror.NewTokenError(apperror.CODE_EXPIRED_TOKEN)
+		}
+		return nil, apperror.NewTokenError(apperror.CODE_INVALID_TOKEN)
 	}
 
 	claims := t.Claims.(jwt.MapClaims)
@@ -69,7 +81,7 @@ func ParseAuthToken(token string, key string) (*model.UserAuthData, error) {
 
 	// Check if token expired
 	if time.Now().Unix() > int64(timeExp) {
-		return nil, exception.NewUnauthorizedError(exception.ExpiredTokenMsg)
+		return nil, apperror.NewTokenError(apperror.CODE_EXPIRED_TOKEN)
 	}
 
 	// Parse UserAuthData one by one
@@ -78,26 +90,44 @@ func ParseAuthToken(token string, key string) (*model.UserAuthData, error) {
 
 	userAuthData.UserID, ok = dataInterface["UserID"].(string)
 	if !ok {
-		return nil, exception.NewUnauthorizedError(exception.InvalidTokenMsg)
+		return nil, apperror.NewTokenError(apperror.CODE_INVALID_TOKEN)
 	}
 
 	userAuthData.UserEmail, ok = dataInterface["UserEmail"].(string)
 	if !ok {
-		return nil, exception.NewUnauthorizedError(exception.InvalidTokenMsg)
+		return nil, apperror.NewTokenError(apperror.CODE_INVALID_TOKEN)
 	}
 
 	userAuthData.UserName, ok = dataInterface["UserName"].(string)
 	if !ok {
-		return nil, exception.NewUnauthorizedError(exception.InvalidTokenMsg)
+		return nil, apperror.NewTokenError(apperror.CODE_INVALID_TOKEN)
 	}
 
+	userAuthData.UserRole, ok = dataInterface["UserRole"].(string)
+	if !ok {
+		return nil, apperror.NewTokenError(apperror.CODE_INVALID_TOKEN)
+	}
+
+	userAuthData.SessionID, ok = dataInterface["SessionID"].(string)
+	if !ok {
+		return nil, apperror.NewTokenError(apperror.CODE_INVALID_TOKEN)
+	}
+
+	// Tokens issued before users could choose a locale don't have one
+	userAuthData.UserLocale, _ = dataInterface["UserLocale"].(string)
+
 	return userAuthData, nil
 }
 
-func GenerateAccessTokenRedisKey(userID string) string {
-	return fmt.Sprintf("ACCESS:%s", userID)
+func GenerateSessionRedisKey(sessionID string) string {
+	return fmt.Sprintf("SESSION:%s", sessionID)
+}
+
+// GenerateSessionLastSeenRedisKey is kept apart from the session, so recording activity never rewrites the session
+func GenerateSessionLastSeenRedisKey(sessionID string) string {
+	return fmt.Sprintf("SESSION_LAST_SEEN:%s", sessionID)
 }
 
-func GenerateRefreshTokenRedisKey(userID string) string {
-	return fmt.Sprintf("REFRESH:%s", userID)
+func GenerateUserSessionsRedisKey(userID string) string {
+	return fmt.Sprintf("USER_SESSIONS:%s", userID)
 }
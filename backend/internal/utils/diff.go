@@ -0,0 +1,192 @@
+package utils
+
+import (
+	"backend/internal/model"
+	"regexp"
+	"strings"
+)
+
+const (
+	DIFF_MODE_LINE = "line"
+	DIFF_MODE_WORD = "word"
+)
+
+const (
+	DIFF_OP_EQUAL  = "equal"
+	DIFF_OP_DELETE = "delete"
+	DIFF_OP_INSERT = "insert"
+)
+
+// DIFF_MAX_EDITS bounds the work of a diff, which grows with the square of the number of edits.
+// Texts that differ more are shown as one deletion and one insertion between their common prefix and suffix.
+const DIFF_MAX_EDITS = 1000
+
+var wordTokenRegex = regexp.MustCompile(`\s+|\S+`)
+
+// DiffLines compares a and b line by line, every chunk ends with its line break except maybe the last one
+func DiffLines(a string, b string) []model.DiffChunk {
+	return diffTokens(splitLines(a), splitLines(b))
+}
+
+// DiffWords compares a and b word by word, whitespace runs are tokens of their own
+func DiffWords(a string, b string) []model.DiffChunk {
+	return diffTokens(wordTokenRegex.FindAllString(a, -1), wordTokenRegex.FindAllString(b, -1))
+}
+
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// diffTokens returns the chunks turning a into b with the fewest deleted and inserted tokens, using Myers' algorithm
+func diffTokens(a []string, b []string) []model.DiffChunk {
+	var chunks []model.DiffChunk
+
+	// Common prefixes and suffixes are never edited, leaving them out keeps the search small
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	chunks = appendChunk(chunks, DIFF_OP_EQUAL, a[:prefix]...)
+	chunks = append(chunks, myersDiff(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	chunks = appendChunk(chunks, DIFF_OP_EQUAL, a[len(a)-suffix:]...)
+
+	return groupChanges(chunks)
+}
+
+func myersDiff(a []string, b []string) []model.DiffChunk {
+	n, m := len(a), len(b)
+	maxEdits := n + m
+	if maxEdits > DIFF_MAX_EDITS {
+		maxEdits = DIFF_MAX_EDITS
+	}
+
+	// v[offset+k] is the furthest x reached on diagonal k = x - y. trace[d] keeps v for diagonals -d..d
+	// as it was before the d-th edit, to walk the path back once the end is reached.
+	offset := maxEdits + 1
+	v := make([]int, 2*offset+1)
+	var trace [][]int
+	found := false
+
+	for d := 0; d <= maxEdits && !found; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+	}
+
+	if !found {
+		var chunks []model.DiffChunk
+		chunks = appendChunk(chunks, DIFF_OP_DELETE, a...)
+		return appendChunk(chunks, DIFF_OP_INSERT, b...)
+	}
+
+	// Walk back from the end, collecting tokens in reverse
+	var reversed []model.DiffChunk
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		previous := trace[d]
+		at := func(k int) int { return previous[k+d] }
+
+		k := x - y
+		var previousK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			previousK = k + 1
+		} else {
+			previousK = k - 1
+		}
+		previousX := at(previousK)
+		previousY := previousX - previousK
+
+		for x > previousX && y > previousY {
+			reversed = append(reversed, model.DiffChunk{Op: DIFF_OP_EQUAL, Text: a[x-1]})
+			x, y = x-1, y-1
+		}
+		if x == previousX {
+			reversed = append(reversed, model.DiffChunk{Op: DIFF_OP_INSERT, Text: b[y-1]})
+		} else {
+			reversed = append(reversed, model.DiffChunk{Op: DIFF_OP_DELETE, Text: a[x-1]})
+		}
+		x, y = previousX, previousY
+	}
+	for x > 0 && y > 0 {
+		reversed = append(reversed, model.DiffChunk{Op: DIFF_OP_EQUAL, Text: a[x-1]})
+		x, y = x-1, y-1
+	}
+
+	var chunks []model.DiffChunk
+	for i := len(reversed) - 1; i >= 0; i-- {
+		chunks = appendChunk(chunks, reversed[i].Op, reversed[i].Text)
+	}
+
+	return chunks
+}
+
+// appendChunk appends tokens to the last chunk if it has the same op, otherwise as a new chunk
+func appendChunk(chunks []model.DiffChunk, op string, tokens ...string) []model.DiffChunk {
+	if len(tokens) == 0 {
+		return chunks
+	}
+
+	text := strings.Join(tokens, "")
+	if last := len(chunks) - 1; last >= 0 && chunks[last].Op == op {
+		chunks[last].Text += text
+		return chunks
+	}
+
+	return append(chunks, model.DiffChunk{Op: op, Text: text})
+}
+
+// groupChanges merges the deletions and insertions between two equal chunks into one deletion followed by one insertion
+func groupChanges(chunks []model.DiffChunk) []model.DiffChunk {
+	grouped := []model.DiffChunk{}
+	var deleted, inserted []string
+
+	flush := func() {
+		grouped = appendChunk(grouped, DIFF_OP_DELETE, deleted...)
+		grouped = appendChunk(grouped, DIFF_OP_INSERT, inserted...)
+		deleted, inserted = nil, nil
+	}
+
+	for _, chunk := range chunks {
+		switch chunk.Op {
+		case DIFF_OP_DELETE:
+			deleted = append(deleted, chunk.Text)
+		case DIFF_OP_INSERT:
+			inserted = append(inserted, chunk.Text)
+		default:
+			flush()
+			grouped = appendChunk(grouped, chunk.Op, chunk.Text)
+		}
+	}
+	flush()
+
+	return grouped
+}
@@ -0,0 +1,237 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt"
+)
+
+const (
+	ALGORITHM_HS256 = "HS256"
+	ALGORITHM_RS256 = "RS256"
+	ALGORITHM_EDDSA = "EdDSA"
+)
+
+// SigningKey is a single key of a KeyRing, identified by the `kid` header of the tokens it signs
+type SigningKey struct {
+	ID         string
+	Algorithm  string
+	PrivateKey crypto.PrivateKey
+	PublicKey  crypto.PublicKey
+}
+
+// JSONWebKey is the public part of a SigningKey, as published in /.well-known/jwks.json
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Modulus   string `json:"n,omitempty"`
+	Exponent  string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// KeyRing signs tokens with its active key and verifies tokens signed by any of its keys.
+// Asymmetric keys are loaded from a directory of PEM encoded private keys named <kid>.pem.
+// To rotate, add a new key file and reload: tokens signed by the previous keys stay valid
+// as long as their files are kept in the directory.
+type KeyRing struct {
+	mu     sync.RWMutex
+	dir    string
+	keys   map[string]*SigningKey
+	active *SigningKey
+}
+
+// NewHMACKeyRing returns a key ring holding a single HS256 secret. It has no public keys to publish.
+func NewHMACKeyRing(secret string) *KeyRing {
+	key := &SigningKey{
+		ID:         "",
+		Algorithm:  ALGORITHM_HS256,
+		PrivateKey: []byte(secret),
+		PublicKey:  []byte(secret),
+	}
+
+	return &KeyRing{
+		keys:   map[string]*SigningKey{key.ID: key},
+		active: key,
+	}
+}
+
+// NewFileKeyRing loads every RSA or Ed25519 key in dir. The active key is activeKeyID,
+// or the key with the greatest ID when activeKeyID is empty, so timestamp IDs make the newest key active.
+func NewFileKeyRing(dir string, activeKeyID string) (*KeyRing, error) {
+	keyRing := &KeyRing{dir: dir}
+
+	if err := keyRing.Reload(activeKeyID); err != nil {
+		return nil, err
+	}
+
+	return keyRing, nil
+}
+
+// Reload reads the key directory again and activates activeKeyID, or the key with the greatest ID when it is empty.
+// The key ring is left unchanged if any key is invalid.
+func (k *KeyRing) Reload(activeKeyID string) error {
+	if k.dir == "" {
+		return nil
+	}
+
+	paths, err := filepath.Glob(filepath.Join(k.dir, "*.pem"))
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]*SigningKey, len(paths))
+	IDs := make([]string, 0, len(paths))
+	for _, path := range paths {
+		ID := strings.TrimSuffix(filepath.Base(path), ".pem")
+
+		key, err := loadSigningKey(ID, path)
+		if err != nil {
+			return fmt.Errorf("loading key %s: %w", path, err)
+		}
+
+		keys[ID] = key
+		IDs = append(IDs, ID)
+	}
+	if len(IDs) == 0 {
+		return fmt.Errorf("no key found in %s", k.dir)
+	}
+	sort.Strings(IDs)
+
+	if activeKeyID == "" {
+		activeKeyID = IDs[len(IDs)-1]
+	}
+	active, ok := keys[activeKeyID]
+	if !ok {
+		return fmt.Errorf("active key %s not found in %s", activeKeyID, k.dir)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.keys = keys
+	k.active = active
+
+	return nil
+}
+
+// Sign signs claims with the active key and sets its ID as the `kid` header
+func (k *KeyRing) Sign(claims jwt.Claims) (string, error) {
+	k.mu.RLock()
+	active := k.active
+	k.mu.RUnlock()
+
+	t := jwt.NewWithClaims(jwt.GetSigningMethod(active.Algorithm), claims)
+	if active.ID != "" {
+		t.Header["kid"] = active.ID
+	}
+
+	return t.SignedString(active.PrivateKey)
+}
+
+// Keyfunc returns the key verifying t, chosen by its `kid` header. The algorithm of the token
+// must be the algorithm of the key, so a public key can never be used as an HMAC secret.
+func (k *KeyRing) Keyfunc(t *jwt.Token) (interface{}, error) {
+	ID, _ := t.Header["kid"].(string)
+
+	k.mu.RLock()
+	key, ok := k.keys[ID]
+	k.mu.RUnlock()
+
+	if !ok {
+		return nil, errors.New("unknown key id")
+	}
+	if t.Method.Alg() != key.Algorithm {
+		return nil, errors.New("unexpected signing method")
+	}
+
+	return key.PublicKey, nil
+}
+
+// JWKS returns the public keys of every asymmetric key in the ring
+func (k *KeyRing) JWKS() JSONWebKeySet {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	set := JSONWebKeySet{Keys: []JSONWebKey{}}
+	for _, key := range k.keys {
+		switch publicKey := key.PublicKey.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, JSONWebKey{
+				KeyType:   "RSA",
+				KeyID:     key.ID,
+				Use:       "sig",
+				Algorithm: key.Algorithm,
+				Modulus:   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+				Exponent:  base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, JSONWebKey{
+				KeyType:   "OKP",
+				KeyID:     key.ID,
+				Use:       "sig",
+				Algorithm: key.Algorithm,
+				Curve:     "Ed25519",
+				X:         base64.RawURLEncoding.EncodeToString(publicKey),
+			})
+		}
+	}
+	sort.Slice(set.Keys, func(i, j int) bool {
+		return set.Keys[i].KeyID < set.Keys[j].KeyID
+	})
+
+	return set
+}
+
+func loadSigningKey(ID string, path string) (*SigningKey, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, errors.New("not a PEM file")
+	}
+
+	var privateKey any
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		privateKey, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		privateKey, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		err = fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch privateKey := privateKey.(type) {
+	case *rsa.PrivateKey:
+		return &SigningKey{ID: ID, Algorithm: ALGORITHM_RS256, PrivateKey: privateKey, PublicKey: &privateKey.PublicKey}, nil
+	case ed25519.PrivateKey:
+		return &SigningKey{ID: ID, Algorithm: ALGORITHM_EDDSA, PrivateKey: privateKey, PublicKey: privateKey.Public()}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", privateKey)
+	}
+}
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// GetPasswordResetExpiration returns how long a password reset token can be used
+func GetPasswordResetExpiration(viperConfig *viper.Viper) time.Duration {
+	minutes := viperConfig.GetInt("auth.passwordResetExpMinutes")
+	if minutes <= 0 {
+		minutes = 30
+	}
+
+	return time.Duration(minutes) * time.Minute
+}
+
+// GetPasswordResetResendInterval returns how long to wait before requesting another password reset email
+func GetPasswordResetResendInterval(viperConfig *viper.Viper) time.Duration {
+	seconds := viperConfig.GetInt("auth.passwordResetResendSeconds")
+	if seconds <= 0 {
+		seconds = 60
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
+// GeneratePasswordResetRedisKey takes the hash of a reset token, tokens are never stored in plain text
+func GeneratePasswordResetRedisKey(tokenHash string) string {
+	return fmt.Sprintf("PASSWORD_RESET:%s", tokenHash)
+}
+
+// GenerateUserPasswordResetRedisKey points to the latest reset token hash of a user
+func GenerateUserPasswordResetRedisKey(userID string) string {
+	return fmt.Sprintf("PASSWORD_RESET_USER:%s", userID)
+}
+
+func GeneratePasswordResetThrottleRedisKey(email string) string {
+	return fmt.Sprintf("PASSWORD_RESET_THROTTLE:%s", email)
+}
@@ -0,0 +1,14 @@
+package utils
+
+import "backend/internal/constant"
+
+// RoleHasPermission returns true if permission is granted to role in constant.ROLE_PERMISSIONS
+func RoleHasPermission(role string, permission string) bool {
+	for _, granted := range constant.ROLE_PERMISSIONS[role] {
+		if granted == permission {
+			return true
+		}
+	}
+
+	return false
+}
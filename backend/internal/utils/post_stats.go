@@ -0,0 +1,35 @@
+package utils
+
+import "fmt"
+
+// GeneratePostViewerRedisKey marks that visitor viewed a post, it expires after the window in which
+// views of the same visitor are counted once
+func GeneratePostViewerRedisKey(postID uint64, visitor string) string {
+	return fmt.Sprintf("POST_VIEWER:%d:%s", postID, visitor)
+}
+
+// GeneratePostViewsRedisKey is the number of views of a post on date that were not flushed yet
+func GeneratePostViewsRedisKey(postID uint64, date string) string {
+	return fmt.Sprintf("POST_VIEWS:%d:%s", postID, date)
+}
+
+// GeneratePostUniquesRedisKey is the HyperLogLog of the visitors of a post on date
+func GeneratePostUniquesRedisKey(postID uint64, date string) string {
+	return fmt.Sprintf("POST_UNIQUES:%d:%s", postID, date)
+}
+
+// GenerateDirtyPostStatsRedisKey is the set of the posts and dates, like 12:2006-01-02, viewed since they were flushed
+func GenerateDirtyPostStatsRedisKey() string {
+	return "POST_STATS_DIRTY"
+}
+
+// GenerateFlushingPostViewsRedisKey holds the views of a post on date taken by a flush, until the flush is committed
+func GenerateFlushingPostViewsRedisKey(postID uint64, date string) string {
+	return fmt.Sprintf("POST_VIEWS_FLUSHING:%d:%s", postID, date)
+}
+
+// GenerateFlushingPostStatsRedisKey is the sorted set of the posts and dates being flushed, scored by when
+// the flush took them
+func GenerateFlushingPostStatsRedisKey() string {
+	return "POST_STATS_FLUSHING"
+}
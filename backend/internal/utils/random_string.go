@@ -1,15 +1,49 @@
 package utils
 
-import "math/rand"
+import (
+	cryptorand "crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"math/rand"
+)
 
 var letters = []byte("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ1234567890")
 
 func GenerateRandomString(length int) string {
+	return generateRandomString(rand.Intn, length)
+}
+
+// GenerateRandomStringWithRand works like GenerateRandomString, but takes the letters from r
+// so the result is reproducible for a fixed seed
+func GenerateRandomStringWithRand(r *rand.Rand, length int) string {
+	return generateRandomString(r.Intn, length)
+}
+
+func generateRandomString(intn func(int) int, length int) string {
 	res := make([]byte, length)
 
 	for i := range res {
-		res[i] = letters[rand.Intn(len(letters))]
+		res[i] = letters[intn(len(letters))]
 	}
 
 	return string(res)
 }
+
+// GenerateSecureToken returns byteLength bytes from crypto/rand, encoded as URL safe base64.
+// Use it for anything that works as a secret, like session IDs and single-use tokens.
+func GenerateSecureToken(byteLength int) (string, error) {
+	b := make([]byte, byteLength)
+	if _, err := cryptorand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken returns the hex encoded SHA-256 of token, so secrets aren't stored in plain text
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,13 @@
+package utils
+
+import "fmt"
+
+// GeneratePostReactionsRedisKey is the hash of the reaction counts of a post, by kind
+func GeneratePostReactionsRedisKey(postID uint64) string {
+	return fmt.Sprintf("POST_REACTIONS:%d", postID)
+}
+
+// GenerateDirtyPostReactionsRedisKey is the set of the posts whose reaction counts changed since they were reconciled
+func GenerateDirtyPostReactionsRedisKey() string {
+	return "POST_REACTIONS_DIRTY"
+}
@@ -0,0 +1,134 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const SLUG_MAX_LENGTH = 80
+const SLUG_FALLBACK = "post" // For titles without a single letter or digit that can be transliterated
+
+// slugTransliterations covers the letters that don't decompose into an ASCII letter and a combining mark
+var slugTransliterations = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'å': "a", 'đ': "d", 'ð': "d", 'þ': "th", 'ł': "l", 'ı': "i",
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh", 'з': "z", 'и': "i",
+	'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t",
+	'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "",
+	'э': "e", 'ю': "yu", 'я': "ya", 'є': "ye", 'і': "i", 'ї': "yi", 'ґ': "g",
+}
+
+// Slugify turns title into lowercase ASCII words joined by dashes, like "Crème brûlée!" into "creme-brulee".
+// Accents are dropped and a few other letters transliterated, anything else separates words.
+func Slugify(title string) string {
+	slug := slugify(title)
+	if len(slug) == 0 {
+		return SLUG_FALLBACK
+	}
+
+	return slug
+}
+
+// NormalizeTag returns the name of a tag without surrounding or repeated whitespace and a leading #,
+// and its slug. The slug is empty when the name has nothing to transliterate.
+func NormalizeTag(name string) (string, string) {
+	name = strings.Join(strings.Fields(strings.TrimPrefix(strings.TrimSpace(name), "#")), " ")
+
+	return name, slugify(name)
+}
+
+func slugify(title string) string {
+	var builder strings.Builder
+	dash := false
+
+	for _, r := range norm.NFKD.String(strings.ToLower(title)) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+
+		text, ok := slugTransliterations[r]
+		if !ok && r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			text, ok = string(r), true
+		}
+		if !ok {
+			dash = builder.Len() > 0
+			continue
+		}
+		if len(text) == 0 {
+			continue
+		}
+
+		if dash {
+			builder.WriteByte('-')
+			dash = false
+		}
+		builder.WriteString(text)
+	}
+
+	slug := builder.String()
+	if len(slug) > SLUG_MAX_LENGTH {
+		// Cut at the last word that fits, unless that would leave a single long word out entirely
+		slug = slug[:SLUG_MAX_LENGTH]
+		if i := strings.LastIndexByte(slug, '-'); i > 0 {
+			slug = slug[:i]
+		}
+	}
+
+	return slug
+}
+
+// UniqueSlug returns base if it is not taken, otherwise base with the lowest free suffix from -2 on
+func UniqueSlug(base string, taken []string) string {
+	takenSet := make(map[string]bool, len(taken))
+	for _, slug := range taken {
+		takenSet[slug] = true
+	}
+
+	if !takenSet[base] {
+		return base
+	}
+	for i := 2; ; i++ {
+		slug := SlugWithSuffix(base, i)
+		if !takenSet[slug] {
+			return slug
+		}
+	}
+}
+
+// SlugWithSuffix returns base with the numeric suffix n, cutting base short when both don't fit in SLUG_MAX_LENGTH
+func SlugWithSuffix(base string, n int) string {
+	suffix := "-" + strconv.Itoa(n)
+	if len(base)+len(suffix) > SLUG_MAX_LENGTH {
+		base = strings.TrimSuffix(base[:SLUG_MAX_LENGTH-len(suffix)], "-")
+	}
+
+	return base + suffix
+}
+
+// SlugSearchPrefix returns the start shared by base and every slug UniqueSlug can make from it,
+// which is base itself unless base is long enough to be cut for a suffix
+func SlugSearchPrefix(base string) string {
+	// The longest suffix is a dash and the 19 digits of an int, and cutting base for it can leave a dash to drop
+	if n := SLUG_MAX_LENGTH - 21; len(base) > n {
+		return base[:n]
+	}
+
+	return base
+}
+
+// SlugHasBase tells if slug is base or base with a numeric suffix given by UniqueSlug
+func SlugHasBase(slug string, base string) bool {
+	if slug == base {
+		return true
+	}
+
+	i := strings.LastIndexByte(slug, '-')
+	if i < 0 {
+		return false
+	}
+	n, err := strconv.Atoi(slug[i+1:])
+
+	return err == nil && n >= 2 && SlugWithSuffix(base, n) == slug
+}
@@ -0,0 +1,150 @@
+package utils
+
+import (
+	"crypto/hmac"
+	cryptorand "crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// TOTP parameters of RFC 6238, they are the defaults every authenticator app supports
+const (
+	TOTP_PERIOD = 30
+	TOTP_DIGITS = 6
+	TOTP_SKEW   = 1 // Codes of the previous and next period are accepted too, to allow for clock drift
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTOTPSecret returns a random 160 bit secret encoded as unpadded base32
+func GenerateTOTPSecret() (string, error) {
+	b := make([]byte, 20)
+	if _, err := cryptorand.Read(b); err != nil {
+		return "", err
+	}
+
+	return totpEncoding.EncodeToString(b), nil
+}
+
+// GenerateTOTPURI returns the otpauth:// URI shown as a QR code to enroll secret in an authenticator app
+func GenerateTOTPURI(viperConfig *viper.Viper, accountName string, secret string) string {
+	issuer := viperConfig.GetString("auth.totpIssuer")
+	if issuer == "" {
+		issuer = "Blog"
+	}
+
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(TOTP_DIGITS))
+	query.Set("period", fmt.Sprint(TOTP_PERIOD))
+
+	return fmt.Sprintf("otpauth://totp/%s:%s?%s", url.PathEscape(issuer), url.PathEscape(accountName), query.Encode())
+}
+
+// GenerateTOTPCode returns the code of secret for the period containing t
+func GenerateTOTPCode(secret string, t time.Time) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", err
+	}
+
+	return hotp(key, t.Unix()/TOTP_PERIOD), nil
+}
+
+// ValidateTOTPCode checks code against the periods around t. It returns the period the code belongs to,
+// so callers can refuse a code that was already used.
+func ValidateTOTPCode(secret string, code string, t time.Time) (int64, bool) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil || len(code) != TOTP_DIGITS {
+		return 0, false
+	}
+
+	current := t.Unix() / TOTP_PERIOD
+	for step := current - TOTP_SKEW; step <= current+TOTP_SKEW; step++ {
+		if subtle.ConstantTimeCompare([]byte(hotp(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// hotp is the HMAC-SHA1 one-time password of RFC 4226 for counter
+func hotp(key []byte, counter int64) string {
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(counter))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", TOTP_DIGITS, value%1_000_000)
+}
+
+var recoveryCodeLetters = []byte("abcdefghijkmnpqrstuvwxyz23456789")
+
+// GenerateRecoveryCodes returns count random codes formatted as xxxxx-xxxxx. Ambiguous letters
+// like l, o, 0 and 1 are left out, since the codes are usually written down.
+func GenerateRecoveryCodes(count int) ([]string, error) {
+	codes := make([]string, count)
+
+	for i := range codes {
+		b := make([]byte, 10)
+		if _, err := cryptorand.Read(b); err != nil {
+			return nil, err
+		}
+		for j := range b {
+			b[j] = recoveryCodeLetters[int(b[j])%len(recoveryCodeLetters)]
+		}
+
+		codes[i] = string(b[:5]) + "-" + string(b[5:])
+	}
+
+	return codes, nil
+}
+
+// HashRecoveryCode hashes code the way it is stored, ignoring case and spaces the user may have typed
+func HashRecoveryCode(code string) string {
+	return HashToken(strings.ToLower(strings.ReplaceAll(code, " ", "")))
+}
+
+// IsRecoveryCodeFormat tells a recovery code apart from a TOTP code
+func IsRecoveryCodeFormat(code string) bool {
+	return strings.Contains(code, "-")
+}
+
+// GetMFAChallengeExpiration returns how long a user has to enter the code after the password
+func GetMFAChallengeExpiration(viperConfig *viper.Viper) time.Duration {
+	minutes := viperConfig.GetInt("auth.mfaChallengeExpMinutes")
+	if minutes <= 0 {
+		minutes = 5
+	}
+
+	return time.Duration(minutes) * time.Minute
+}
+
+func GenerateMFAChallengeRedisKey(tokenHash string) string {
+	return fmt.Sprintf("MFA_CHALLENGE:%s", tokenHash)
+}
+
+func GenerateMFAChallengeAttemptsRedisKey(tokenHash string) string {
+	return fmt.Sprintf("MFA_CHALLENGE_ATTEMPTS:%s", tokenHash)
+}
+
+// GenerateTOTPUsedRedisKey marks the code of a period as used, so a code can't be replayed in its validity window
+func GenerateTOTPUsedRedisKey(userID string, step int64) string {
+	return fmt.Sprintf("TOTP_USED:%s:%d", userID, step)
+}
@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"backend/internal/entity"
+	"backend/internal/model"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/spf13/viper"
+)
+
+const verifyEmailPurpose = "verify_email"
+
+// GenerateVerificationToken returns a signed email verification token for user and its token ID.
+// The token is only usable while its ID is stored in redis, which makes it single-use.
+func GenerateVerificationToken(viperConfig *viper.Viper, user *entity.User) (string, string, time.Duration, error) {
+	key := viperConfig.GetString("auth.verificationTokenKey")
+	expMinutes := viperConfig.GetInt("auth.verificationTokenExpMinutes")
+	if expMinutes <= 0 {
+		expMinutes = 24 * 60
+	}
+
+	timeDuration := time.Duration(expMinutes) * time.Minute
+
+	tokenID, err := GenerateSecureToken(16)
+	if err != nil {
+		return "", "", timeDuration, err
+	}
+
+	token, err := NewHMACKeyRing(key).Sign(jwt.MapClaims{
+		"exp":     time.Now().Add(timeDuration).Unix(),
+		"jti":     tokenID,
+		"sub":     user.ID,
+		"email":   user.Email,
+		"purpose": verifyEmailPurpose,
+	})
+
+	return token, tokenID, timeDuration, err
+}
+
+func ParseVerificationToken(viperConfig *viper.Viper, token string) (*model.VerificationTokenData, error) {
+	key := viperConfig.GetString("auth.verificationTokenKey")
+
+	t, err := jwt.Parse(token, NewHMACKeyRing(key).Keyfunc)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := t.Claims.(jwt.MapClaims)
+	if claims["purpose"] != verifyEmailPurpose {
+		return nil, errors.New("not an email verification token")
+	}
+
+	data := new(model.VerificationTokenData)
+	var ok bool
+
+	if data.TokenID, ok = claims["jti"].(string); !ok {
+		return nil, errors.New("missing token ID")
+	}
+	if data.UserID, ok = claims["sub"].(string); !ok {
+		return nil, errors.New("missing user ID")
+	}
+	if data.Email, ok = claims["email"].(string); !ok {
+		return nil, errors.New("missing email")
+	}
+
+	return data, nil
+}
+
+// GetVerificationResendInterval returns how long a user has to wait before requesting another verification email
+func GetVerificationResendInterval(viperConfig *viper.Viper) time.Duration {
+	seconds := viperConfig.GetInt("auth.verificationResendSeconds")
+	if seconds <= 0 {
+		seconds = 60
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
+func GenerateVerificationRedisKey(tokenID string) string {
+	return fmt.Sprintf("EMAIL_VERIFICATION:%s", tokenID)
+}
+
+func GenerateVerificationThrottleRedisKey(email string) string {
+	return fmt.Sprintf("EMAIL_VERIFICATION_THROTTLE:%s", email)
+}
@@ -16,8 +16,10 @@ import (
 )
 
 var (
-	registerUrl = "http://127.0.0.1:5000/api/auth/register"
-	loginUrl    = "http://127.0.0.1:5000/api/auth/login"
+	registerUrl  = "http://127.0.0.1:5000/api/auth/register"
+	verifyUrl    = "http://127.0.0.1:5000/api/auth/verify"
+	loginUrl     = "http://127.0.0.1:5000/api/auth/login"
+	userAdminUrl = "http://127.0.0.1:5000/api/admin/users"
 )
 
 func TestRegister(t *testing.T) {
@@ -137,17 +139,142 @@ func TestLogin(t *testing.T) {
 				require.NotEmpty(t, validToken)
 
 				var err error
-				authData, err = utils.ParseAccessToken(viperConfig, validToken)
+				authData, err = utils.ParseAccessToken(keyRing, validToken)
 				require.Nil(t, err)
 
-				accessTokenRedisKey := utils.GenerateAccessTokenRedisKey(authData.UserID)
-				accessTokenRedis, err := redisClient.Get(context.Background(), accessTokenRedisKey).Result()
-				require.Equal(t, accessTokenRedis, validToken)
+				sessionRedisKey := utils.GenerateSessionRedisKey(authData.SessionID)
+				sessionCount, err := redisClient.Exists(context.Background(), sessionRedisKey).Result()
+				require.Nil(t, err)
+				require.Equal(t, int64(1), sessionCount)
 			}
 		})
 	}
 }
 
+func TestUpdateRole(t *testing.T) {
+	// A freshly registered user is a reader, and readers can't post
+	t.Run("POST_Create_FORBIDDEN_reader", func(t *testing.T) {
+		request := newRequestWithToken(http.MethodPost, postAdminUrl,
+			`{"title":"TEST_POST", "content":"TEST_CONTENT"}`, validToken)
+
+		recorder := httptest.NewRecorder()
+		app.ServeHTTP(recorder, request)
+
+		responseBody, _ := io.ReadAll(recorder.Result().Body)
+		testResponse := new(TestResponse[any])
+		require.Nil(t, json.Unmarshal(responseBody, testResponse))
+		require.Equal(t, http.StatusForbidden, testResponse.Code)
+	})
+
+	adminToken := login("user1@mail.com", "user1")
+	require.NotEmpty(t, adminToken)
+
+	testItems := map[string]TestSchema{
+		"USER_UpdateRole_OK": {
+			"param_id":        authData.UserID,
+			"request_role":    "author",
+			"request_token":   adminToken,
+			"expected_code":   http.StatusOK,
+			"expected_status": "OK",
+		},
+		"USER_UpdateRole_FORBIDDEN_not_admin": {
+			"param_id":        authData.UserID,
+			"request_role":    "admin",
+			"request_token":   validToken,
+			"expected_code":   http.StatusForbidden,
+			"expected_status": "FORBIDDEN",
+		},
+		"USER_UpdateRole_VALIDATION_ERROR_unknown_role": {
+			"param_id":        authData.UserID,
+			"request_role":    "owner",
+			"request_token":   adminToken,
+			"expected_code":   http.StatusBadRequest,
+			"expected_status": "BAD REQUEST",
+		},
+		"USER_UpdateRole_NOT_FOUND_unknown_user": {
+			"param_id":        "USR-unknown",
+			"request_role":    "author",
+			"request_token":   adminToken,
+			"expected_code":   http.StatusNotFound,
+			"expected_status": "NOT FOUND",
+		},
+	}
+
+	for testName, testItem := range testItems {
+		t.Run(testName, func(t *testing.T) {
+			requestUrl := userAdminUrl + "/" + testItem["param_id"].(string) + "/role"
+			requestBody := fmt.Sprintf(`{"role":"%s"}`, testItem["request_role"])
+			request := newRequestWithToken(http.MethodPut, requestUrl, requestBody, testItem["request_token"].(string))
+
+			recorder := httptest.NewRecorder()
+			app.ServeHTTP(recorder, request)
+
+			responseBody, _ := io.ReadAll(recorder.Result().Body)
+			testResponse := new(TestResponse[model.UserResponse])
+			require.Nil(t, json.Unmarshal(responseBody, testResponse))
+
+			require.Equal(t, testItem["expected_code"].(int), testResponse.Code)
+			require.Equal(t, testItem["expected_status"].(string), testResponse.Status)
+		})
+	}
+
+	// The new role is only carried by tokens issued after the change
+	validToken = login("johndoe@mail.com", "johndoe")
+	require.NotEmpty(t, validToken)
+
+	var err error
+	authData, err = utils.ParseAccessToken(keyRing, validToken)
+	require.Nil(t, err)
+	require.Equal(t, "author", authData.UserRole)
+}
+
+func TestVerifyEmail(t *testing.T) {
+	postRequest := func() int {
+		request := newRequestWithToken(http.MethodPost, postAdminUrl,
+			`{"title":"TEST_POST", "content":"TEST_CONTENT"}`, validToken)
+
+		recorder := httptest.NewRecorder()
+		app.ServeHTTP(recorder, request)
+
+		responseBody, _ := io.ReadAll(recorder.Result().Body)
+		testResponse := new(TestResponse[any])
+		require.Nil(t, json.Unmarshal(responseBody, testResponse))
+
+		return testResponse.Code
+	}
+	verifyRequest := func(token string) int {
+		recorder := httptest.NewRecorder()
+		app.ServeHTTP(recorder, newRequest(http.MethodPost, verifyUrl, fmt.Sprintf(`{"token":"%s"}`, token)))
+
+		return recorder.Code
+	}
+
+	// Authors can only post once their email is verified
+	require.Equal(t, http.StatusForbidden, postRequest())
+
+	token := latestMailToken("johndoe@mail.com")
+	require.NotEmpty(t, token)
+
+	require.Equal(t, http.StatusBadRequest, verifyRequest("this.is.invalid.token"))
+	require.Equal(t, http.StatusOK, verifyRequest(token))
+
+	// Verification tokens are single-use
+	require.Equal(t, http.StatusBadRequest, verifyRequest(token))
+
+	t.Run("USER_ResendVerification_TOO_MANY_REQUESTS", func(t *testing.T) {
+		resendRequest := func(email string) int {
+			recorder := httptest.NewRecorder()
+			app.ServeHTTP(recorder, newRequest(http.MethodPost, verifyUrl+"/resend", fmt.Sprintf(`{"email":"%s"}`, email)))
+
+			return recorder.Code
+		}
+
+		// Unknown emails get the same response as registered ones
+		require.Equal(t, http.StatusOK, resendRequest("nobody@mail.com"))
+		require.Equal(t, http.StatusTooManyRequests, resendRequest("nobody@mail.com"))
+	})
+}
+
 func TestCreatePost(t *testing.T) {
 	post := model.PostResponse{
 		Title:   "TEST_POST",
@@ -0,0 +1,219 @@
+package test
+
+import (
+	"backend/internal/apperror"
+	"backend/internal/model"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	bookmarkUrl    = "http://127.0.0.1:5000/api/user/bookmarks"
+	readingListUrl = "http://127.0.0.1:5000/api/user/reading-lists"
+)
+
+func TestBookmark(t *testing.T) {
+	editorToken := login("user2@mail.com", "user2")
+	authorToken := login("user3@mail.com", "user3")
+
+	createPost := func(title string, status string) model.PostResponse {
+		post := model.PostResponse{}
+		requestBody := fmt.Sprintf(`{"title":"%s", "content":"BOOKMARK_CONTENT", "status":"%s"}`, title, status)
+		response := serve(t, newRequestWithToken(http.MethodPost, postAdminUrl, requestBody, authorToken), &post)
+		require.Equal(t, http.StatusOK, response.Code)
+
+		return post
+	}
+	bookmarkedIDs := func(query string) []uint64 {
+		var posts []model.PostResponse
+		require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodGet, bookmarkUrl+query, "", editorToken), &posts).Code)
+
+		ids := make([]uint64, len(posts))
+		for i, post := range posts {
+			require.True(t, *post.Bookmarked)
+			ids[i] = post.ID
+		}
+		return ids
+	}
+	itemIDs := func(readingList model.ReadingListResponse) []uint64 {
+		ids := make([]uint64, len(readingList.Posts))
+		for i, item := range readingList.Posts {
+			ids[i] = item.PostID
+		}
+		return ids
+	}
+
+	first := createPost("Bookmark First", "published")
+	second := createPost("Bookmark Second", "published")
+	third := createPost("Bookmark Third", "published")
+	draft := createPost("Bookmark Draft", "draft")
+
+	t.Run("BOOKMARK_save", func(t *testing.T) {
+		for _, post := range []model.PostResponse{first, second, third} {
+			url := fmt.Sprintf("%s/%d", bookmarkUrl, post.ID)
+			require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodPut, url, "", editorToken), nil).Code)
+		}
+		// Saving twice keeps the first bookmark
+		url := fmt.Sprintf("%s/%d", bookmarkUrl, first.ID)
+		require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodPut, url, "", editorToken), nil).Code)
+
+		url = fmt.Sprintf("%s/%d", bookmarkUrl, draft.ID)
+		require.Equal(t, http.StatusNotFound, serve(t, newRequestWithToken(http.MethodPut, url, "", editorToken), nil).Code)
+		require.Equal(t, http.StatusUnauthorized, serve(t, newRequest(http.MethodPut, url, ""), nil).Code)
+
+		// The last bookmarked comes first
+		require.Equal(t, []uint64{third.ID, second.ID, first.ID}, bookmarkedIDs(""))
+		require.Equal(t, []uint64{second.ID}, bookmarkedIDs("?page=2&pageSize=1"))
+
+		url = fmt.Sprintf("%s/%d", bookmarkUrl, third.ID)
+		require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodDelete, url, "", editorToken), nil).Code)
+		require.Equal(t, []uint64{second.ID, first.ID}, bookmarkedIDs(""))
+	})
+
+	t.Run("BOOKMARK_flag", func(t *testing.T) {
+		post := model.PostResponse{}
+		url := fmt.Sprintf("%s/%d", postGuestUrl, first.ID)
+		require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodGet, url, "", editorToken), &post).Code)
+		require.True(t, *post.Bookmarked)
+
+		require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodGet, url, "", authorToken), &post).Code)
+		require.False(t, *post.Bookmarked)
+
+		// Guests don't get the flag at all
+		post = model.PostResponse{}
+		require.Equal(t, http.StatusOK, serve(t, newRequest(http.MethodGet, url, ""), &post).Code)
+		require.Nil(t, post.Bookmarked)
+
+		var posts []model.PostResponse
+		require.Equal(t, http.StatusOK,
+			serve(t, newRequestWithToken(http.MethodGet, postGuestUrl+"?title=Bookmark", "", editorToken), &posts).Code)
+		require.Len(t, posts, 3)
+		for _, post := range posts {
+			require.Equal(t, post.ID != third.ID, *post.Bookmarked)
+		}
+	})
+
+	t.Run("BOOKMARK_reading_list", func(t *testing.T) {
+		readingList := model.ReadingListResponse{}
+		response := serve(t, newRequestWithToken(http.MethodPost, readingListUrl, `{"name":"Weekend"}`, editorToken), &readingList)
+		require.Equal(t, http.StatusOK, response.Code)
+		require.Equal(t, "Weekend", readingList.Name)
+
+		response = serve(t, newRequestWithToken(http.MethodPost, readingListUrl, `{"name":"Weekend"}`, editorToken), nil)
+		require.Equal(t, http.StatusConflict, response.Code)
+		// Names are only unique per user
+		response = serve(t, newRequestWithToken(http.MethodPost, readingListUrl, `{"name":"Weekend"}`, authorToken), nil)
+		require.Equal(t, http.StatusOK, response.Code)
+
+		listUrl := fmt.Sprintf("%s/%d", readingListUrl, readingList.ID)
+
+		// Renaming to a name the user already has conflicts too
+		other := model.ReadingListResponse{}
+		require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodPost, readingListUrl, `{"name":"Weekday"}`, editorToken), &other).Code)
+		otherUrl := fmt.Sprintf("%s/%d", readingListUrl, other.ID)
+		require.Equal(t, http.StatusConflict, serve(t, newRequestWithToken(http.MethodPut, otherUrl, `{"name":"Weekend"}`, editorToken), nil).Code)
+		require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodDelete, otherUrl, "", editorToken), nil).Code)
+
+		add := func(postID uint64, position int) model.ReadingListResponse {
+			result := model.ReadingListResponse{}
+			requestBody := fmt.Sprintf(`{"post_id":%d, "position":%d}`, postID, position)
+			response := serve(t, newRequestWithToken(http.MethodPost, listUrl+"/posts", requestBody, editorToken), &result)
+			require.Equal(t, http.StatusOK, response.Code)
+
+			return result
+		}
+		add(first.ID, 0)
+		add(second.ID, 0)
+		readingList = add(third.ID, 1)
+		require.Equal(t, []uint64{third.ID, first.ID, second.ID}, itemIDs(readingList))
+		require.Equal(t, 1, readingList.Posts[0].Position)
+
+		// Adding a post that is in the list moves it
+		readingList = add(third.ID, 0)
+		require.Equal(t, []uint64{first.ID, second.ID, third.ID}, itemIDs(readingList))
+		require.Equal(t, int64(3), readingList.PostCount)
+
+		// Other users can't see the list
+		require.Equal(t, http.StatusNotFound, serve(t, newRequestWithToken(http.MethodGet, listUrl, "", authorToken), nil).Code)
+
+		requestBody := fmt.Sprintf(`{"post_ids":[%d, %d]}`, second.ID, first.ID)
+		response = serve(t, newRequestWithToken(http.MethodPut, listUrl+"/order", requestBody, editorToken), nil)
+		require.Equal(t, http.StatusBadRequest, response.Code)
+		require.Equal(t, apperror.CODE_INVALID_READING_LIST_ORDER, response.ErrorCode)
+
+		requestBody = fmt.Sprintf(`{"post_ids":[%d, %d, %d]}`, second.ID, third.ID, first.ID)
+		response = serve(t, newRequestWithToken(http.MethodPut, listUrl+"/order", requestBody, editorToken), &readingList)
+		require.Equal(t, http.StatusOK, response.Code)
+		require.Equal(t, []uint64{second.ID, third.ID, first.ID}, itemIDs(readingList))
+
+		url := fmt.Sprintf("%s/posts/%d", listUrl, third.ID)
+		response = serve(t, newRequestWithToken(http.MethodDelete, url, "", editorToken), &readingList)
+		require.Equal(t, http.StatusOK, response.Code)
+		require.Equal(t, []uint64{second.ID, first.ID}, itemIDs(readingList))
+
+		var readingLists []model.ReadingListResponse
+		require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodGet, readingListUrl, "", editorToken), &readingLists).Code)
+		require.Len(t, readingLists, 1)
+		require.Equal(t, int64(2), readingLists[0].PostCount)
+
+		require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodDelete, listUrl, "", editorToken), nil).Code)
+		require.Equal(t, http.StatusNotFound, serve(t, newRequestWithToken(http.MethodGet, listUrl, "", editorToken), nil).Code)
+	})
+
+	t.Run("BOOKMARK_reading_list_concurrent_adds", func(t *testing.T) {
+		readingList := model.ReadingListResponse{}
+		response := serve(t, newRequestWithToken(http.MethodPost, readingListUrl, `{"name":"Concurrent"}`, editorToken), &readingList)
+		require.Equal(t, http.StatusOK, response.Code)
+		listUrl := fmt.Sprintf("%s/%d", readingListUrl, readingList.ID)
+
+		var postIDs []uint64
+		for i := 1; i <= 5; i++ {
+			postIDs = append(postIDs, createPost(fmt.Sprintf("Bookmark Concurrent %d", i), "published").ID)
+		}
+
+		// Each add rewrites the items it read, the list is locked so no add is lost
+		codes := make(chan int, len(postIDs))
+		for _, postID := range postIDs {
+			go func(postID uint64) {
+				recorder := httptest.NewRecorder()
+				app.ServeHTTP(recorder, newRequestWithToken(http.MethodPost, listUrl+"/posts",
+					fmt.Sprintf(`{"post_id":%d}`, postID), editorToken))
+				codes <- recorder.Code
+			}(postID)
+		}
+		for range postIDs {
+			require.Equal(t, http.StatusOK, <-codes)
+		}
+
+		require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodGet, listUrl, "", editorToken), &readingList).Code)
+		require.ElementsMatch(t, postIDs, itemIDs(readingList))
+	})
+
+	t.Run("BOOKMARK_reading_list_concurrent_creates", func(t *testing.T) {
+		// Only one of the lists with the same name is created, the others conflict
+		const attempts = 5
+		codes := make(chan int, attempts)
+		for i := 0; i < attempts; i++ {
+			go func() {
+				recorder := httptest.NewRecorder()
+				app.ServeHTTP(recorder, newRequestWithToken(http.MethodPost, readingListUrl, `{"name":"Concurrent Create"}`, editorToken))
+				codes <- recorder.Code
+			}()
+		}
+
+		created := 0
+		for i := 0; i < attempts; i++ {
+			code := <-codes
+			if code == http.StatusOK {
+				created++
+				continue
+			}
+			require.Equal(t, http.StatusConflict, code)
+		}
+		require.Equal(t, 1, created)
+	})
+}
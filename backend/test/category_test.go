@@ -0,0 +1,141 @@
+package test
+
+import (
+	"backend/internal/apperror"
+	"backend/internal/model"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	categoryUrl      = "http://127.0.0.1:5000/api/categories"
+	categoryAdminUrl = "http://127.0.0.1:5000/api/admin/categories"
+)
+
+func TestCategory(t *testing.T) {
+	editorToken := login("user2@mail.com", "user2")
+	authorToken := login("user3@mail.com", "user3")
+
+	createCategory := func(name string, parentID uint64) model.CategoryResponse {
+		category := model.CategoryResponse{}
+		requestBody := fmt.Sprintf(`{"name":"%s"}`, name)
+		if parentID > 0 {
+			requestBody = fmt.Sprintf(`{"name":"%s", "parent_id":%d}`, name, parentID)
+		}
+		response := serve(t, newRequestWithToken(http.MethodPost, categoryAdminUrl, requestBody, editorToken), &category)
+		require.Equal(t, http.StatusOK, response.Code)
+
+		return category
+	}
+	createPost := func(title string, categoryID uint64) model.PostResponse {
+		post := model.PostResponse{}
+		requestBody := fmt.Sprintf(`{"title":"%s", "content":"CATEGORY_CONTENT", "status":"published", "category_id":%d}`,
+			title, categoryID)
+		response := serve(t, newRequestWithToken(http.MethodPost, postAdminUrl, requestBody, authorToken), &post)
+		require.Equal(t, http.StatusOK, response.Code)
+
+		return post
+	}
+	listIDs := func(category string) []uint64 {
+		var posts []model.PostResponse
+		require.Equal(t, http.StatusOK, serve(t, newRequest(http.MethodGet, postGuestUrl+"?category="+category, ""), &posts).Code)
+
+		IDs := []uint64{}
+		for _, post := range posts {
+			IDs = append(IDs, post.ID)
+		}
+		return IDs
+	}
+
+	engineering := createCategory("Engineering", 0)
+	backend := createCategory("Backend", engineering.ID)
+	golang := createCategory("Go", backend.ID)
+	design := createCategory("Design", 0)
+
+	t.Run("CATEGORY_manage_permission", func(t *testing.T) {
+		response := serve(t, newRequestWithToken(http.MethodPost, categoryAdminUrl, `{"name":"Forbidden"}`, authorToken), nil)
+		require.Equal(t, http.StatusForbidden, response.Code)
+	})
+
+	t.Run("CATEGORY_tree", func(t *testing.T) {
+		var tree []*model.CategoryResponse
+		require.Equal(t, http.StatusOK, serve(t, newRequest(http.MethodGet, categoryUrl, ""), &tree).Code)
+
+		var found *model.CategoryResponse
+		for _, category := range tree {
+			require.NotEqual(t, backend.ID, category.ID)
+			if category.ID == engineering.ID {
+				found = category
+			}
+		}
+		require.NotNil(t, found)
+		require.Len(t, found.Children, 1)
+		require.Equal(t, "backend", found.Children[0].Slug)
+		require.Len(t, found.Children[0].Children, 1)
+		require.Equal(t, golang.ID, found.Children[0].Children[0].ID)
+
+		category := model.CategoryResponse{}
+		require.Equal(t, http.StatusOK, serve(t, newRequest(http.MethodGet, categoryUrl+"/go", ""), &category).Code)
+		require.Equal(t, []model.CategoryPathResponse{
+			{ID: engineering.ID, Name: "Engineering", Slug: "engineering"},
+			{ID: backend.ID, Name: "Backend", Slug: "backend"},
+		}, category.Ancestors)
+
+		require.Equal(t, http.StatusNotFound, serve(t, newRequest(http.MethodGet, categoryUrl+"/unknown", ""), nil).Code)
+	})
+
+	t.Run("CATEGORY_descendant_posts", func(t *testing.T) {
+		engineeringPost := createPost("Category Engineering", engineering.ID)
+		golangPost := createPost("Category Go", golang.ID)
+		designPost := createPost("Category Design", design.ID)
+
+		require.Equal(t, "go", *golangPost.CategorySlug)
+		require.ElementsMatch(t, []uint64{engineeringPost.ID, golangPost.ID}, listIDs("engineering"))
+		require.ElementsMatch(t, []uint64{golangPost.ID}, listIDs("backend"))
+		require.ElementsMatch(t, []uint64{designPost.ID}, listIDs("design"))
+
+		// A category of 0 removes the category of the post
+		post := model.PostResponse{}
+		serve(t, newRequestWithToken(http.MethodPut, fmt.Sprintf("%s/%d", postAdminUrl, designPost.ID),
+			`{"title":"Category Design", "content":"CATEGORY_CONTENT", "category_id":0}`, authorToken), &post)
+		require.Nil(t, post.CategoryID)
+		require.Empty(t, listIDs("design"))
+
+		response := serve(t, newRequestWithToken(http.MethodPost, postAdminUrl,
+			`{"title":"Category Unknown", "content":"CATEGORY_CONTENT", "category_id":999999}`, authorToken), nil)
+		require.Equal(t, http.StatusNotFound, response.Code)
+	})
+
+	t.Run("CATEGORY_move", func(t *testing.T) {
+		// Engineering can't go under its own subcategory
+		requestBody := fmt.Sprintf(`{"name":"Engineering", "parent_id":%d}`, golang.ID)
+		response := serve(t, newRequestWithToken(http.MethodPut, fmt.Sprintf("%s/%d", categoryAdminUrl, engineering.ID),
+			requestBody, editorToken), nil)
+		require.Equal(t, http.StatusBadRequest, response.Code)
+		require.Equal(t, apperror.CODE_CATEGORY_CYCLE, response.ErrorCode)
+
+		requestBody = fmt.Sprintf(`{"name":"Golang", "parent_id":%d}`, engineering.ID)
+		category := model.CategoryResponse{}
+		response = serve(t, newRequestWithToken(http.MethodPut, fmt.Sprintf("%s/%d", categoryAdminUrl, golang.ID),
+			requestBody, editorToken), &category)
+		require.Equal(t, http.StatusOK, response.Code)
+		require.Equal(t, "golang", category.Slug)
+		require.Equal(t, engineering.ID, *category.ParentID)
+		require.Empty(t, listIDs("backend"))
+	})
+
+	t.Run("CATEGORY_delete", func(t *testing.T) {
+		response := serve(t, newRequestWithToken(http.MethodDelete, fmt.Sprintf("%s/%d", categoryAdminUrl, engineering.ID),
+			"", editorToken), nil)
+		require.Equal(t, http.StatusBadRequest, response.Code)
+		require.Equal(t, apperror.CODE_CATEGORY_NOT_EMPTY, response.ErrorCode)
+
+		response = serve(t, newRequestWithToken(http.MethodDelete, fmt.Sprintf("%s/%d", categoryAdminUrl, backend.ID),
+			"", editorToken), nil)
+		require.Equal(t, http.StatusOK, response.Code)
+		require.Equal(t, http.StatusNotFound, serve(t, newRequest(http.MethodGet, categoryUrl+"/backend", ""), nil).Code)
+	})
+}
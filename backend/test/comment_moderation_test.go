@@ -0,0 +1,164 @@
+package test
+
+import (
+	"backend/internal/entity"
+	"backend/internal/model"
+	"backend/internal/moderation"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var commentAdminUrl = "http://127.0.0.1:5000/api/admin/comments"
+
+func TestSpamChecker(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("SPAM_heuristic", func(t *testing.T) {
+		checker := moderation.NewHeuristicChecker(2, []string{" Casino "})
+
+		verdict, err := checker.Check(ctx, &moderation.Submission{Content: "See https://a.example and www.b.example"})
+		require.Nil(t, err)
+		require.False(t, verdict.Spam)
+
+		verdict, _ = checker.Check(ctx, &moderation.Submission{Content: "http://a http://b https://c"})
+		require.True(t, verdict.Spam)
+
+		verdict, _ = checker.Check(ctx, &moderation.Submission{Content: "Nice post", AuthorEmail: "win@CASINO.example"})
+		require.True(t, verdict.Spam)
+	})
+
+	t.Run("SPAM_akismet", func(t *testing.T) {
+		stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/1.1/comment-check", r.URL.Path)
+			require.Nil(t, r.ParseForm())
+			require.Equal(t, "secret-key", r.PostForm.Get("api_key"))
+			require.Equal(t, "comment", r.PostForm.Get("comment_type"))
+
+			switch {
+			case strings.Contains(r.PostForm.Get("comment_content"), "viagra"):
+				fmt.Fprint(w, "true")
+			case r.PostForm.Get("comment_author") == "broken":
+				w.Header().Set("X-akismet-debug-help", "missing required field")
+				fmt.Fprint(w, "invalid")
+			default:
+				fmt.Fprint(w, "false")
+			}
+		}))
+		defer stub.Close()
+
+		checker := moderation.NewAkismetChecker(stub.URL+"/", "secret-key", "https://blog.example")
+
+		verdict, err := checker.Check(ctx, &moderation.Submission{Content: "cheap viagra", AuthorName: "spammer"})
+		require.Nil(t, err)
+		require.True(t, verdict.Spam)
+
+		verdict, err = checker.Check(ctx, &moderation.Submission{Content: "Great read", AuthorName: "reader"})
+		require.Nil(t, err)
+		require.False(t, verdict.Spam)
+
+		_, err = checker.Check(ctx, &moderation.Submission{Content: "Hello", AuthorName: "broken"})
+		require.ErrorContains(t, err, "missing required field")
+	})
+}
+
+func TestCommentModeration(t *testing.T) {
+	adminToken := login("user1@mail.com", "user1")
+	editorToken := login("user2@mail.com", "user2")
+	authorToken := login("user3@mail.com", "user3")
+
+	comment := func(postID uint64, content string, token string) model.CommentResponse {
+		comment := model.CommentResponse{}
+		code := serve(t, newRequestWithToken(http.MethodPost, fmt.Sprintf("%s/%d/comments", postGuestUrl, postID),
+			fmt.Sprintf(`{"content":"%s"}`, content), token), &comment).Code
+		require.Equal(t, http.StatusOK, code)
+
+		return comment
+	}
+	queueIDs := func(query string) []uint64 {
+		var comments []model.CommentQueueResponse
+		require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodGet, commentAdminUrl+"?"+query, "", editorToken), &comments).Code)
+
+		IDs := []uint64{}
+		for _, comment := range comments {
+			IDs = append(IDs, comment.ID)
+		}
+		return IDs
+	}
+	threadIDs := func(postID uint64) []uint64 {
+		var comments []*model.CommentResponse
+		require.Equal(t, http.StatusOK, serve(t, newRequest(http.MethodGet, fmt.Sprintf("%s/%d/comments", postGuestUrl, postID), ""), &comments).Code)
+
+		IDs := []uint64{}
+		for _, comment := range comments {
+			IDs = append(IDs, comment.ID)
+		}
+		return IDs
+	}
+
+	post := model.PostResponse{}
+	require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodPost, postAdminUrl,
+		`{"title":"Moderated Post", "content":"MODERATION_CONTENT", "status":"published"}`, authorToken), &post).Code)
+
+	ham := comment(post.ID, "Thanks for the post", adminToken)
+	spam := comment(post.ID, "Best casino bonus", adminToken)
+	links := comment(post.ID, "http://a.example http://b.example http://c.example", adminToken)
+
+	t.Run("MODERATION_held_comments", func(t *testing.T) {
+		require.Equal(t, "approved", ham.Status)
+		require.Equal(t, "pending", spam.Status)
+		require.Equal(t, "pending", links.Status)
+
+		require.Equal(t, []uint64{ham.ID}, threadIDs(post.ID))
+		require.Subset(t, queueIDs(""), []uint64{spam.ID, links.ID})
+		require.NotContains(t, queueIDs(""), ham.ID)
+
+		var comments []model.CommentQueueResponse
+		serve(t, newRequestWithToken(http.MethodGet, commentAdminUrl, "", editorToken), &comments)
+		for _, queued := range comments {
+			if queued.ID == spam.ID {
+				require.Equal(t, "Moderated Post", queued.PostTitle)
+				require.Contains(t, *queued.SpamReason, "casino")
+			}
+		}
+
+		// Authors can't moderate the queue, even for their own posts
+		require.Equal(t, http.StatusForbidden, serve(t, newRequestWithToken(http.MethodGet, commentAdminUrl, "", authorToken), nil).Code)
+	})
+
+	t.Run("MODERATION_bulk", func(t *testing.T) {
+		result := model.CommentModerateResponse{}
+		requestBody := fmt.Sprintf(`{"ids":[%d, %d]}`, links.ID, ham.ID)
+		require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodPost, commentAdminUrl+"/approve", requestBody, editorToken), &result).Code)
+		require.Equal(t, int64(1), result.Moderated)
+
+		requestBody = fmt.Sprintf(`{"ids":[%d]}`, spam.ID)
+		require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodPost, commentAdminUrl+"/reject", requestBody, editorToken), &result).Code)
+		require.Equal(t, int64(1), result.Moderated)
+
+		require.Equal(t, []uint64{ham.ID, links.ID}, threadIDs(post.ID))
+		require.Contains(t, queueIDs("status=rejected"), spam.ID)
+		require.NotContains(t, queueIDs(""), spam.ID)
+
+		require.Equal(t, http.StatusBadRequest, serve(t, newRequestWithToken(http.MethodPost, commentAdminUrl+"/approve", `{"ids":[]}`, editorToken), nil).Code)
+	})
+
+	t.Run("MODERATION_trusted_commenter", func(t *testing.T) {
+		author := new(entity.User)
+		require.Nil(t, db.First(author, "email = ?", "user3@mail.com").Error)
+		trustUrl := userAdminUrl + "/" + author.ID + "/trusted-commenter"
+
+		user := model.UserResponse{}
+		require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodPut, trustUrl, `{"trusted":true}`, editorToken), &user).Code)
+		require.True(t, user.TrustedCommenter)
+		require.Equal(t, "approved", comment(post.ID, "My casino story", authorToken).Status)
+
+		require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodPut, trustUrl, `{"trusted":false}`, editorToken), &user).Code)
+		require.Equal(t, "pending", comment(post.ID, "Another casino story", authorToken).Status)
+	})
+}
@@ -0,0 +1,129 @@
+package test
+
+import (
+	"backend/internal/model"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var commentUrl = "http://127.0.0.1:5000/api/comments"
+
+func TestComment(t *testing.T) {
+	adminToken := login("user1@mail.com", "user1")
+	editorToken := login("user2@mail.com", "user2")
+	authorToken := login("user3@mail.com", "user3")
+
+	createPost := func(title string, token string) model.PostResponse {
+		post := model.PostResponse{}
+		requestBody := fmt.Sprintf(`{"title":"%s", "content":"COMMENT_CONTENT", "status":"published"}`, title)
+		require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodPost, postAdminUrl, requestBody, token), &post).Code)
+
+		return post
+	}
+	comment := func(postID uint64, parentID uint64, content string, token string) model.CommentResponse {
+		comment := model.CommentResponse{}
+		requestBody := fmt.Sprintf(`{"content":"%s"}`, content)
+		if parentID > 0 {
+			requestBody = fmt.Sprintf(`{"content":"%s", "parent_id":%d}`, content, parentID)
+		}
+		code := serve(t, newRequestWithToken(http.MethodPost, fmt.Sprintf("%s/%d/comments", postGuestUrl, postID), requestBody, token), &comment).Code
+		require.Equal(t, http.StatusOK, code)
+
+		return comment
+	}
+	threads := func(postID uint64, query string) []*model.CommentResponse {
+		var comments []*model.CommentResponse
+		code := serve(t, newRequest(http.MethodGet, fmt.Sprintf("%s/%d/comments?%s", postGuestUrl, postID, query), ""), &comments).Code
+		require.Equal(t, http.StatusOK, code)
+
+		return comments
+	}
+	commentCount := func(postID uint64) int64 {
+		post := model.PostResponse{}
+		require.Equal(t, http.StatusOK, serve(t, newRequest(http.MethodGet, fmt.Sprintf("%s/%d", postGuestUrl, postID), ""), &post).Code)
+
+		return post.CommentCount
+	}
+
+	post := createPost("Commented Post", authorToken)
+	first := comment(post.ID, 0, "First", adminToken)
+	reply := comment(post.ID, first.ID, "Reply", editorToken)
+	nested := comment(post.ID, reply.ID, "Nested reply", adminToken)
+	second := comment(post.ID, 0, "Second", adminToken)
+
+	t.Run("COMMENT_threads", func(t *testing.T) {
+		comments := threads(post.ID, "")
+		require.Len(t, comments, 2)
+		require.Equal(t, first.ID, comments[0].ID)
+		require.Equal(t, "user 1", comments[0].Author)
+		require.Len(t, comments[0].Replies, 1)
+		require.Equal(t, reply.ID, comments[0].Replies[0].ID)
+		require.Equal(t, nested.ID, comments[0].Replies[0].Replies[0].ID)
+		require.Empty(t, comments[1].Replies)
+
+		// Pages count top level comments only
+		comments = threads(post.ID, "page=2&pageSize=1")
+		require.Len(t, comments, 1)
+		require.Equal(t, second.ID, comments[0].ID)
+
+		require.Equal(t, int64(4), commentCount(post.ID))
+	})
+
+	t.Run("COMMENT_reply_to_other_post", func(t *testing.T) {
+		other := createPost("Other Commented Post", authorToken)
+		requestBody := fmt.Sprintf(`{"content":"Misplaced", "parent_id":%d}`, first.ID)
+		code := serve(t, newRequestWithToken(http.MethodPost, fmt.Sprintf("%s/%d/comments", postGuestUrl, other.ID), requestBody, adminToken), nil).Code
+		require.Equal(t, http.StatusNotFound, code)
+	})
+
+	t.Run("COMMENT_edit_own_only", func(t *testing.T) {
+		url := fmt.Sprintf("%s/%d", commentUrl, first.ID)
+		require.Equal(t, http.StatusNotFound, serve(t, newRequestWithToken(http.MethodPut, url, `{"content":"Hijacked"}`, editorToken), nil).Code)
+
+		edited := model.CommentResponse{}
+		require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodPut, url, `{"content":"First, edited"}`, adminToken), &edited).Code)
+		require.Equal(t, "First, edited", edited.Content)
+	})
+
+	t.Run("COMMENT_moderation", func(t *testing.T) {
+		// The author of the post can delete comments on it, a comment with replies stays without its content
+		require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodDelete, fmt.Sprintf("%s/%d", commentUrl, first.ID), "", authorToken), nil).Code)
+		comments := threads(post.ID, "")
+		require.True(t, comments[0].Deleted)
+		require.Empty(t, comments[0].Content)
+		require.Empty(t, comments[0].Author)
+		require.Len(t, comments[0].Replies, 1)
+		require.Equal(t, int64(3), commentCount(post.ID))
+
+		// Other authors can't, editors can
+		editorPost := createPost("Editor Commented Post", editorToken)
+		editorComment := comment(editorPost.ID, 0, "On the editor post", adminToken)
+		url := fmt.Sprintf("%s/%d", commentUrl, editorComment.ID)
+		require.Equal(t, http.StatusNotFound, serve(t, newRequestWithToken(http.MethodDelete, url, "", authorToken), nil).Code)
+		require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodDelete, url, "", editorToken), nil).Code)
+		require.Empty(t, threads(editorPost.ID, ""))
+	})
+
+	t.Run("COMMENT_delete_last_reply", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodDelete, fmt.Sprintf("%s/%d", commentUrl, nested.ID), "", adminToken), nil).Code)
+		require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodDelete, fmt.Sprintf("%s/%d", commentUrl, reply.ID), "", editorToken), nil).Code)
+
+		// The deleted comment was only kept for its replies
+		comments := threads(post.ID, "")
+		require.Len(t, comments, 1)
+		require.Equal(t, second.ID, comments[0].ID)
+		require.Equal(t, int64(1), commentCount(post.ID))
+	})
+
+	t.Run("COMMENT_moderation_trashed_post", func(t *testing.T) {
+		trashed := createPost("Trashed Commented Post", authorToken)
+		trashedComment := comment(trashed.ID, 0, "On the trashed post", adminToken)
+		require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodDelete, fmt.Sprintf("%s/%d", postAdminUrl, trashed.ID), "", authorToken), nil).Code)
+
+		// The post is still the author's while it is in the trash
+		require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodDelete, fmt.Sprintf("%s/%d", commentUrl, trashedComment.ID), "", authorToken), nil).Code)
+	})
+}
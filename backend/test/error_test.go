@@ -0,0 +1,107 @@
+package test
+
+import (
+	"backend/internal/apperror"
+	"backend/internal/model"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorResponse(t *testing.T) {
+	t.Run("ERROR_NOT_FOUND", func(t *testing.T) {
+		response := serve(t, newRequest(http.MethodGet, postGuestUrl+"/999999", ""), nil)
+		require.Equal(t, http.StatusNotFound, response.Code)
+		require.Equal(t, "NOT FOUND", response.Status)
+		require.Equal(t, "POST_NOT_FOUND", response.ErrorCode)
+		require.Equal(t, []string{"post is not found"}, response.Messages)
+	})
+
+	t.Run("ERROR_VALIDATION", func(t *testing.T) {
+		response := serve(t, newRequest(http.MethodPost, loginUrl, `{"email":"", "password":""}`), nil)
+		require.Equal(t, http.StatusBadRequest, response.Code)
+		require.Equal(t, apperror.CODE_VALIDATION_FAILED, response.ErrorCode)
+		require.Len(t, response.Messages, 2)
+	})
+
+	t.Run("ERROR_MALFORMED_BODY", func(t *testing.T) {
+		response := serve(t, newRequest(http.MethodPost, loginUrl, `{"email":`), nil)
+		require.Equal(t, http.StatusBadRequest, response.Code)
+		require.Equal(t, apperror.CODE_BAD_REQUEST, response.ErrorCode)
+	})
+
+	t.Run("ERROR_TOKEN", func(t *testing.T) {
+		response := serve(t, newRequestWithToken(http.MethodGet, "http://127.0.0.1:5000/api/user/current", "", "invalid"), nil)
+		require.Equal(t, http.StatusUnauthorized, response.Code)
+		require.Equal(t, apperror.CODE_INVALID_TOKEN, response.ErrorCode)
+		require.Equal(t, []string{apperror.CODE_INVALID_TOKEN}, response.Messages)
+	})
+
+	t.Run("ERROR_PROBLEM_JSON", func(t *testing.T) {
+		request := newRequest(http.MethodPost, loginUrl, `{"email":"johndoe", "password":""}`)
+		request.Header.Set(echo.HeaderAccept, "application/problem+json")
+
+		recorder := httptest.NewRecorder()
+		app.ServeHTTP(recorder, request)
+		require.Equal(t, http.StatusBadRequest, recorder.Code)
+		require.Equal(t, "application/problem+json", recorder.Header().Get(echo.HeaderContentType))
+
+		responseBody, _ := io.ReadAll(recorder.Result().Body)
+		problem := model.ProblemResponse{}
+		require.Nil(t, json.Unmarshal(responseBody, &problem))
+		require.Equal(t, "/problems/validation-failed", problem.Type)
+		require.Equal(t, http.StatusBadRequest, problem.Status)
+		require.Equal(t, "/api/auth/login", problem.Instance)
+		require.ElementsMatch(t, []model.ProblemFieldError{
+			{Field: "email", Rule: "email", Detail: "email must be a valid email"},
+			{Field: "password", Rule: "required", Detail: "password is required"},
+		}, problem.Errors)
+	})
+
+	// Errors used to overwrite the message of shared echo errors, so concurrent requests mixed up their messages
+	t.Run("ERROR_CONCURRENT_REQUESTS", func(t *testing.T) {
+		requests := map[string]func() *http.Request{
+			"post is not found": func() *http.Request {
+				return newRequest(http.MethodGet, postGuestUrl+"/999999", "")
+			},
+			"verification token is invalid or has already been used": func() *http.Request {
+				return newRequest(http.MethodPost, verifyUrl, `{"token":"invalid"}`)
+			},
+		}
+
+		type result struct {
+			expected string
+			body     []byte
+		}
+		results := make(chan result, 40)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			for expected, newRequest := range requests {
+				wg.Add(1)
+				go func(expected string, request *http.Request) {
+					defer wg.Done()
+
+					recorder := httptest.NewRecorder()
+					app.ServeHTTP(recorder, request)
+					responseBody, _ := io.ReadAll(recorder.Result().Body)
+					results <- result{expected: expected, body: responseBody}
+				}(expected, newRequest())
+			}
+		}
+		wg.Wait()
+		close(results)
+
+		for result := range results {
+			testResponse := TestResponse[any]{}
+			require.Nil(t, json.Unmarshal(result.body, &testResponse))
+			require.Equal(t, []string{result.expected}, testResponse.Messages)
+		}
+	})
+}
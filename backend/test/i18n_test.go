@@ -0,0 +1,157 @@
+package test
+
+import (
+	"backend/internal/apperror"
+	"backend/internal/i18n"
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageCatalogCoverage(t *testing.T) {
+	locales := i18n.Default.Locales()
+	require.Contains(t, locales, "en")
+	require.Contains(t, locales, "id")
+
+	t.Run("I18N_every_locale_has_every_key", func(t *testing.T) {
+		for _, locale := range locales {
+			for _, key := range i18n.Default.Keys(i18n.DEFAULT_LOCALE) {
+				require.True(t, i18n.Default.Has(locale, key), "%s is missing %s", locale, key)
+			}
+		}
+	})
+
+	t.Run("I18N_every_error_code_is_translated", func(t *testing.T) {
+		codes := parseStringConstants(t, "../internal/apperror", "CODE_")
+		require.NotEmpty(t, codes)
+
+		for _, locale := range locales {
+			for _, code := range codes {
+				require.True(t, i18n.Default.Has(locale, "error."+code), "%s is missing error.%s", locale, code)
+			}
+		}
+	})
+
+	t.Run("I18N_every_validation_tag_is_translated", func(t *testing.T) {
+		tags := parseValidationTags(t, "../internal/model")
+		require.NotEmpty(t, tags)
+
+		for _, locale := range locales {
+			for _, tag := range tags {
+				require.True(t, i18n.Default.Has(locale, "validation."+tag), "%s is missing validation.%s", locale, tag)
+			}
+		}
+	})
+}
+
+func TestLocalizedErrors(t *testing.T) {
+	t.Run("I18N_Accept_Language", func(t *testing.T) {
+		request := newRequest(http.MethodGet, postGuestUrl+"/999999", "")
+		request.Header.Set("Accept-Language", "fr-FR, id-ID;q=0.8, en;q=0.5")
+		require.Equal(t, []string{"tulisan tidak ditemukan"}, serve(t, request, nil).Messages)
+
+		request = newRequest(http.MethodPost, loginUrl, `{"email":"", "password":"abc"}`)
+		request.Header.Set("Accept-Language", "id")
+		require.Equal(t, []string{"email wajib diisi"}, serve(t, request, nil).Messages)
+
+		// Unsupported languages get English
+		request = newRequest(http.MethodGet, postGuestUrl+"/999999", "")
+		request.Header.Set("Accept-Language", "fr")
+		require.Equal(t, []string{"post is not found"}, serve(t, request, nil).Messages)
+	})
+
+	t.Run("I18N_user_locale", func(t *testing.T) {
+		token := login("user2@mail.com", "user2")
+		updateLocale := func(locale string) TestResponse[json.RawMessage] {
+			return serve(t, newRequestWithToken(http.MethodPut, "http://127.0.0.1:5000/api/user/locale",
+				`{"locale":"`+locale+`"}`, token), nil)
+		}
+
+		response := updateLocale("fr")
+		require.Equal(t, http.StatusBadRequest, response.Code)
+		require.Equal(t, apperror.CODE_UNSUPPORTED_LOCALE, response.ErrorCode)
+
+		require.Equal(t, http.StatusOK, updateLocale("id").Code)
+		defer updateLocale("")
+
+		// The locale comes with the next access token, and wins over the Accept-Language header
+		token = login("user2@mail.com", "user2")
+		request := newRequestWithToken(http.MethodDelete, sessionUrl+"/unknown", "", token)
+		request.Header.Set("Accept-Language", "en")
+		require.Equal(t, []string{"sesi tidak ditemukan"}, serve(t, request, nil).Messages)
+	})
+}
+
+// parseStringConstants returns the values of the string constants named prefix* in the package at dir
+func parseStringConstants(t *testing.T, dir string, prefix string) []string {
+	packages, err := parser.ParseDir(token.NewFileSet(), dir, nil, 0)
+	require.Nil(t, err)
+
+	var values []string
+	for _, pkg := range packages {
+		for _, file := range pkg.Files {
+			ast.Inspect(file, func(node ast.Node) bool {
+				spec, ok := node.(*ast.ValueSpec)
+				if !ok {
+					return true
+				}
+
+				for i, name := range spec.Names {
+					if !strings.HasPrefix(name.Name, prefix) || i >= len(spec.Values) {
+						continue
+					}
+					if literal, ok := spec.Values[i].(*ast.BasicLit); ok && literal.Kind == token.STRING {
+						value, _ := strconv.Unquote(literal.Value)
+						values = append(values, value)
+					}
+				}
+				return true
+			})
+		}
+	}
+
+	return values
+}
+
+// parseValidationTags returns every rule used in the validate tags of the structs in dir
+func parseValidationTags(t *testing.T, dir string) []string {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	require.Nil(t, err)
+
+	seen := make(map[string]bool)
+	var tags []string
+	for _, path := range paths {
+		file, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+		require.Nil(t, err)
+
+		ast.Inspect(file, func(node ast.Node) bool {
+			field, ok := node.(*ast.Field)
+			if !ok || field.Tag == nil {
+				return true
+			}
+
+			structTag, _ := strconv.Unquote(field.Tag.Value)
+			for _, rule := range strings.Split(reflect.StructTag(structTag).Get("validate"), ",") {
+				name, _, _ := strings.Cut(rule, "=")
+				// These change how fields are validated, they never fail on their own
+				if name == "" || name == "omitempty" || name == "dive" || seen[name] {
+					continue
+				}
+				seen[name] = true
+				tags = append(tags, name)
+			}
+			return true
+		})
+	}
+
+	return tags
+}
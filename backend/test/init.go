@@ -1,8 +1,17 @@
 package test
 
 import (
+	"backend/db/migrate"
+	"backend/db/seeder"
 	"backend/internal/config"
+	"backend/internal/mail"
 	"backend/internal/model"
+	"backend/internal/moderation"
+	"backend/internal/repository"
+	"backend/internal/scheduler"
+	"backend/internal/utils"
+	"context"
+	"os"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
@@ -17,6 +26,9 @@ var (
 	redisClient *redis.Client
 	validate    *validator.Validate
 	viperConfig *viper.Viper
+	keyRing     *utils.KeyRing
+	mailDir     string
+	jobs        *scheduler.Scheduler // Never started, tests run the jobs they need with jobs.Run
 )
 
 var (
@@ -26,24 +38,65 @@ var (
 
 type TestSchema map[string]interface{}
 type TestResponse[T any] struct {
-	Code     int      `json:"code"`
-	Status   string   `json:"status"`
-	Data     T        `json:"data"`
-	Messages []string `json:"messages"`
+	Code      int      `json:"code"`
+	Status    string   `json:"status"`
+	Data      T        `json:"data"`
+	ErrorCode string   `json:"error_code"`
+	Messages  []string `json:"messages"`
 }
 
 func init() {
 	viperConfig = config.NewViper()
-	app = config.NewEcho()
+	app = config.NewEcho(viperConfig)
 	db = config.NewDatabase(viperConfig)
 	redisClient = config.NewRedisClient(viperConfig)
-	validate = validator.New()
+	validate = config.NewValidator()
+	keyRing = config.NewKeyRing(viperConfig)
+	jobs = config.NewScheduler()
+
+	// Emails are written to a temporary directory, so tests can read the links they contain
+	var err error
+	if mailDir, err = os.MkdirTemp("", "blog-test-mails"); err != nil {
+		panic(err)
+	}
+
+	// Every test request comes from the same address and the seeded users log in from many tests,
+	// so only the lockout after failed logins is exercised
+	viperConfig.Set("auth.loginLimit.ipLimit", 1000)
+	viperConfig.Set("auth.loginLimit.emailLimit", 1000)
 
 	config.Bootstrap(&config.BootstrapConfig{
-		App:      app,
-		DB:       db,
-		Redis:    redisClient,
-		Validate: validate,
-		Config:   viperConfig,
+		App:         app,
+		DB:          db,
+		Redis:       redisClient,
+		Validate:    validate,
+		Config:      viperConfig,
+		KeyRing:     keyRing,
+		Mailer:      mail.NewFileMailer(mailDir, "test@mail.com"),
+		SpamChecker: moderation.NewHeuristicChecker(2, []string{"casino"}),
+		Scheduler:   jobs,
 	})
+
+	// Start every test run from an empty schema and the seeded users and posts
+	migrator, err := migrate.NewMigrator(db)
+	if err != nil {
+		panic(err)
+	}
+	if err := migrator.Reset(context.Background()); err != nil {
+		panic(err)
+	}
+	// Counters kept in Redis belong to the posts of the previous run
+	if err := redisClient.FlushDB(context.Background()).Err(); err != nil {
+		panic(err)
+	}
+
+	fixtures, err := seeder.DefaultFixtures()
+	if err != nil {
+		panic(err)
+	}
+	s := seeder.NewSeeder(db, 1, repository.NewUserRepository(), repository.NewPostRepository(),
+		repository.NewPostSlugRepository(), repository.NewPostRevisionRepository())
+	if err := s.Seed(fixtures); err != nil {
+		panic(err)
+	}
 }
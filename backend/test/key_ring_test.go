@@ -0,0 +1,200 @@
+package test
+
+import (
+	"backend/internal/apperror"
+	delivery "backend/internal/delivery/http"
+	"backend/internal/entity"
+	"backend/internal/utils"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyRing(t *testing.T) {
+	user := &entity.User{ID: "key-ring-user", Email: "keyring@mail.com", Name: "Key Ring", Role: "author"}
+
+	writeRSAKey := func(dir string, ID string) *rsa.PrivateKey {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.Nil(t, err)
+		block := &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+		require.Nil(t, os.WriteFile(filepath.Join(dir, ID+".pem"), pem.EncodeToMemory(block), 0600))
+
+		return key
+	}
+	writeEd25519Key := func(dir string, ID string) {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		require.Nil(t, err)
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		require.Nil(t, err)
+		block := &pem.Block{Type: "PRIVATE KEY", Bytes: der}
+		require.Nil(t, os.WriteFile(filepath.Join(dir, ID+".pem"), pem.EncodeToMemory(block), 0600))
+	}
+	sign := func(keyRing *utils.KeyRing) string {
+		token, _, err := utils.GenerateAuthToken(user, "key-ring-session", keyRing, 5)
+		require.Nil(t, err)
+
+		return token
+	}
+	header := func(token string) map[string]any {
+		parsed, _, err := new(jwt.Parser).ParseUnverified(token, jwt.MapClaims{})
+		require.Nil(t, err)
+
+		return parsed.Header
+	}
+	verify := func(keyRing *utils.KeyRing, token string) error {
+		authData, err := utils.ParseAccessToken(keyRing, token)
+		if err == nil {
+			require.Equal(t, user.ID, authData.UserID)
+		}
+
+		return err
+	}
+
+	t.Run("KEY_RING_rs256", func(t *testing.T) {
+		dir := t.TempDir()
+		writeRSAKey(dir, "2026-01")
+		keyRing, err := utils.NewFileKeyRing(dir, "")
+		require.Nil(t, err)
+
+		token := sign(keyRing)
+		require.Equal(t, utils.ALGORITHM_RS256, header(token)["alg"])
+		require.Equal(t, "2026-01", header(token)["kid"])
+		require.Nil(t, verify(keyRing, token))
+	})
+
+	t.Run("KEY_RING_eddsa", func(t *testing.T) {
+		dir := t.TempDir()
+		writeEd25519Key(dir, "2026-01")
+		keyRing, err := utils.NewFileKeyRing(dir, "")
+		require.Nil(t, err)
+
+		token := sign(keyRing)
+		require.Equal(t, utils.ALGORITHM_EDDSA, header(token)["alg"])
+		require.Nil(t, verify(keyRing, token))
+	})
+
+	t.Run("KEY_RING_kid", func(t *testing.T) {
+		dir := t.TempDir()
+		writeRSAKey(dir, "2026-01")
+		writeEd25519Key(dir, "2026-02")
+
+		// The greatest ID is active unless another key is chosen
+		keyRing, err := utils.NewFileKeyRing(dir, "")
+		require.Nil(t, err)
+		newest := sign(keyRing)
+		require.Equal(t, "2026-02", header(newest)["kid"])
+
+		chosen, err := utils.NewFileKeyRing(dir, "2026-01")
+		require.Nil(t, err)
+		oldest := sign(chosen)
+		require.Equal(t, "2026-01", header(oldest)["kid"])
+
+		// Either ring verifies tokens of both keys, by their kid
+		require.Nil(t, verify(keyRing, oldest))
+		require.Nil(t, verify(chosen, newest))
+
+		_, err = utils.NewFileKeyRing(dir, "2025-12")
+		require.NotNil(t, err)
+	})
+
+	t.Run("KEY_RING_rotation", func(t *testing.T) {
+		dir := t.TempDir()
+		writeRSAKey(dir, "2026-01")
+		keyRing, err := utils.NewFileKeyRing(dir, "")
+		require.Nil(t, err)
+		previous := sign(keyRing)
+
+		// A pinned key stays active while the next one is published
+		writeEd25519Key(dir, "2026-02")
+		require.Nil(t, keyRing.Reload("2026-01"))
+		require.Equal(t, "2026-01", header(sign(keyRing))["kid"])
+
+		// Unpinning it activates the newest key
+		require.Nil(t, keyRing.Reload(""))
+		current := sign(keyRing)
+		require.Equal(t, "2026-02", header(current)["kid"])
+
+		// Tokens of the previous key stay valid as long as its file is kept
+		require.Nil(t, verify(keyRing, previous))
+		require.Nil(t, verify(keyRing, current))
+
+		// A pinned key that isn't in the directory leaves the key ring unchanged
+		require.NotNil(t, keyRing.Reload("2026-03"))
+		require.Equal(t, "2026-02", header(sign(keyRing))["kid"])
+
+		require.Nil(t, os.Remove(filepath.Join(dir, "2026-01.pem")))
+		require.Nil(t, keyRing.Reload(""))
+		require.ErrorIs(t, verify(keyRing, previous), apperror.NewTokenError(apperror.CODE_INVALID_TOKEN))
+		require.Nil(t, verify(keyRing, current))
+	})
+
+	t.Run("KEY_RING_algorithm_confusion", func(t *testing.T) {
+		dir := t.TempDir()
+		key := writeRSAKey(dir, "2026-01")
+		keyRing, err := utils.NewFileKeyRing(dir, "")
+		require.Nil(t, err)
+
+		// An HS256 token signed with the public key, which anyone can get from the JWKS, as the HMAC secret
+		publicKey, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+		require.Nil(t, err)
+		forged := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"exp":  time.Now().Add(time.Minute).Unix(),
+			"data": map[string]any{"user_id": user.ID},
+		})
+		forged.Header["kid"] = "2026-01"
+		for _, secret := range [][]byte{publicKey, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey})} {
+			token, err := forged.SignedString(secret)
+			require.Nil(t, err)
+			require.ErrorIs(t, verify(keyRing, token), apperror.NewTokenError(apperror.CODE_INVALID_TOKEN))
+		}
+	})
+
+	t.Run("KEY_RING_jwks", func(t *testing.T) {
+		dir := t.TempDir()
+		writeRSAKey(dir, "2026-01")
+		writeEd25519Key(dir, "2026-02")
+		keyRing, err := utils.NewFileKeyRing(dir, "")
+		require.Nil(t, err)
+
+		e := echo.New()
+		e.GET("/.well-known/jwks.json", delivery.NewKeyController(keyRing).JWKS)
+		recorder := httptest.NewRecorder()
+		e.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		set := struct {
+			Keys []map[string]any `json:"keys"`
+		}{}
+		require.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &set))
+		require.Len(t, set.Keys, 2)
+		for _, key := range set.Keys {
+			// Private parts of the keys are never published
+			for _, private := range []string{"d", "p", "q", "dp", "dq", "qi", "k"} {
+				require.NotContains(t, key, private)
+			}
+		}
+		require.Equal(t, map[string]any{"kty": "RSA", "kid": "2026-01", "use": "sig", "alg": "RS256",
+			"n": set.Keys[0]["n"], "e": "AQAB"}, set.Keys[0])
+		require.Equal(t, map[string]any{"kty": "OKP", "kid": "2026-02", "use": "sig", "alg": "EdDSA",
+			"crv": "Ed25519", "x": set.Keys[1]["x"]}, set.Keys[1])
+
+		// The HS256 secret of the test app has nothing to publish
+		recorder = httptest.NewRecorder()
+		app.ServeHTTP(recorder, newRequest(http.MethodGet, "http://127.0.0.1:5000/.well-known/jwks.json", ""))
+		require.Equal(t, http.StatusOK, recorder.Code)
+		require.JSONEq(t, `{"keys":[]}`, recorder.Body.String())
+	})
+}
@@ -0,0 +1,63 @@
+package test
+
+import (
+	"backend/internal/entity"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoginLockout(t *testing.T) {
+	email := "lockout@mail.com"
+
+	recorder := httptest.NewRecorder()
+	app.ServeHTTP(recorder, newRequest(http.MethodPost, registerUrl,
+		fmt.Sprintf(`{"name":"Lockout User", "email":"%s", "password":"lockout"}`, email)))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	user := new(entity.User)
+	require.Nil(t, db.First(user, "email = ?", email).Error)
+	unlockUrl := userAdminUrl + "/" + user.ID + "/unlock"
+
+	loginRequest := func(password string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		app.ServeHTTP(recorder, newRequest(http.MethodPost, loginUrl,
+			fmt.Sprintf(`{"email":"%s", "password":"%s"}`, email, password)))
+
+		return recorder
+	}
+
+	for i := 0; i < 5; i++ {
+		require.Equal(t, http.StatusUnauthorized, loginRequest("wrong-password").Code)
+	}
+
+	// The account is locked now, even the right password is refused
+	recorder = loginRequest("lockout")
+	require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	retryAfter, err := strconv.Atoi(recorder.Header().Get(echo.HeaderRetryAfter))
+	require.Nil(t, err)
+	require.Greater(t, retryAfter, 0)
+
+	t.Run("USER_Unlock_FORBIDDEN_not_admin", func(t *testing.T) {
+		token := login("user3@mail.com", "user3")
+
+		recorder := httptest.NewRecorder()
+		app.ServeHTTP(recorder, newRequestWithToken(http.MethodPost, unlockUrl, "", token))
+		require.Equal(t, http.StatusForbidden, recorder.Code)
+	})
+
+	t.Run("USER_Unlock_OK", func(t *testing.T) {
+		token := login("user1@mail.com", "user1")
+
+		recorder := httptest.NewRecorder()
+		app.ServeHTTP(recorder, newRequestWithToken(http.MethodPost, unlockUrl, "", token))
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		require.Equal(t, http.StatusOK, loginRequest("lockout").Code)
+	})
+}
@@ -0,0 +1,52 @@
+package test
+
+import (
+	"backend/internal/apperror"
+	"backend/internal/entity"
+	"backend/internal/model"
+	"backend/internal/utils"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptionalAuth(t *testing.T) {
+	authorToken := login("user3@mail.com", "user3")
+
+	post := model.PostResponse{}
+	response := serve(t, newRequestWithToken(http.MethodPost, postAdminUrl,
+		`{"title":"Optional Auth", "content":"OPTIONAL_AUTH_CONTENT", "status":"published"}`, authorToken), &post)
+	require.Equal(t, http.StatusOK, response.Code)
+	url := fmt.Sprintf("%s/%d", postGuestUrl, post.ID)
+
+	t.Run("OPTIONAL_AUTH_valid", func(t *testing.T) {
+		post := model.PostResponse{}
+		require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodGet, url, "", authorToken), &post).Code)
+		require.NotNil(t, post.Bookmarked)
+	})
+
+	t.Run("OPTIONAL_AUTH_invalid", func(t *testing.T) {
+		// An invalid token is served like a guest
+		post := model.PostResponse{}
+		require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodGet, url, "", "invalid"), &post).Code)
+		require.Nil(t, post.Bookmarked)
+	})
+
+	t.Run("OPTIONAL_AUTH_expired", func(t *testing.T) {
+		// An expired token is rejected, so the frontend refreshes it instead of showing the guest response
+		user := new(entity.User)
+		require.Nil(t, db.First(user, "email = ?", "user3@mail.com").Error)
+		expiredToken, _, err := utils.GenerateAuthToken(user, "expired-session", keyRing, -1)
+		require.Nil(t, err)
+
+		response := serve(t, newRequestWithToken(http.MethodGet, url, "", expiredToken), nil)
+		require.Equal(t, http.StatusUnauthorized, response.Code)
+		require.Equal(t, apperror.CODE_EXPIRED_TOKEN, response.ErrorCode)
+
+		// Routes that require authentication answer the same
+		response = serve(t, newRequestWithToken(http.MethodGet, "http://127.0.0.1:5000/api/user/current", "", expiredToken), nil)
+		require.Equal(t, apperror.CODE_EXPIRED_TOKEN, response.ErrorCode)
+	})
+}
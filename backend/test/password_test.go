@@ -0,0 +1,72 @@
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	passwordUrl       = "http://127.0.0.1:5000/api/user/password"
+	forgotPasswordUrl = "http://127.0.0.1:5000/api/auth/password/forgot"
+	resetPasswordUrl  = "http://127.0.0.1:5000/api/auth/password/reset"
+)
+
+func TestPassword(t *testing.T) {
+	email := "janedoe@mail.com"
+
+	require.Equal(t, http.StatusOK, serve(t, newRequest(http.MethodPost, registerUrl,
+		fmt.Sprintf(`{"name":"Jane Doe", "email":"%s", "password":"janedoe"}`, email)), nil).Code)
+
+	currentUser := func(token string) int {
+		return serve(t, newRequestWithToken(http.MethodGet, "http://127.0.0.1:5000/api/user/current", "", token), nil).Code
+	}
+
+	t.Run("USER_ChangePassword_OK", func(t *testing.T) {
+		otherToken := login(email, "janedoe")
+		token := login(email, "janedoe")
+		require.NotEmpty(t, token)
+
+		require.Equal(t, http.StatusBadRequest, serve(t, newRequestWithToken(http.MethodPut, passwordUrl,
+			`{"old_password":"wrong-password", "new_password":"janedoe2"}`, token), nil).Code)
+		require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodPut, passwordUrl,
+			`{"old_password":"janedoe", "new_password":"janedoe2"}`, token), nil).Code)
+
+		// Other sessions are logged out, the one that changed the password stays
+		require.Equal(t, http.StatusUnauthorized, currentUser(otherToken))
+		require.Equal(t, http.StatusOK, currentUser(token))
+
+		require.Empty(t, login(email, "janedoe"))
+		require.NotEmpty(t, login(email, "janedoe2"))
+	})
+
+	t.Run("USER_ResetPassword_OK", func(t *testing.T) {
+		token := login(email, "janedoe2")
+		require.NotEmpty(t, token)
+
+		// Unknown emails get the same response as registered ones
+		require.Equal(t, http.StatusOK, serve(t, newRequest(http.MethodPost, forgotPasswordUrl, `{"email":"nobody@mail.com"}`), nil).Code)
+		require.Equal(t, http.StatusOK, serve(t, newRequest(http.MethodPost, forgotPasswordUrl, fmt.Sprintf(`{"email":"%s"}`, email)), nil).Code)
+		require.Equal(t, http.StatusTooManyRequests,
+			serve(t, newRequest(http.MethodPost, forgotPasswordUrl, fmt.Sprintf(`{"email":"%s"}`, email)), nil).Code)
+
+		resetToken := latestMailToken(email)
+		require.NotEmpty(t, resetToken)
+
+		resetRequest := func(resetToken string) *http.Request {
+			return newRequest(http.MethodPost, resetPasswordUrl,
+				fmt.Sprintf(`{"token":"%s", "new_password":"janedoe3"}`, resetToken))
+		}
+		require.Equal(t, http.StatusBadRequest, serve(t, resetRequest("invalid-token"), nil).Code)
+		require.Equal(t, http.StatusOK, serve(t, resetRequest(resetToken), nil).Code)
+
+		// Reset tokens are single-use and every session is logged out
+		require.Equal(t, http.StatusBadRequest, serve(t, resetRequest(resetToken), nil).Code)
+		require.Equal(t, http.StatusUnauthorized, currentUser(token))
+
+		require.Empty(t, login(email, "janedoe2"))
+		require.NotEmpty(t, login(email, "janedoe3"))
+	})
+}
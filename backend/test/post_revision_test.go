@@ -0,0 +1,104 @@
+package test
+
+import (
+	"backend/internal/constant"
+	"backend/internal/model"
+	"backend/internal/utils"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostRevision(t *testing.T) {
+	token := login("user3@mail.com", "user3")
+
+	write := func(method string, url string, title string, content string) model.PostResponse {
+		post := model.PostResponse{}
+		requestBody := fmt.Sprintf(`{"title":"%s", "content":"%s"}`, title, content)
+		require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(method, url, requestBody, token), &post).Code)
+
+		return post
+	}
+
+	post := write(http.MethodPost, postAdminUrl, "Revised Post", `first line\nsecond line\n`)
+	postUrl := fmt.Sprintf("%s/%d", postAdminUrl, post.ID)
+	write(http.MethodPut, postUrl, "Revised Post", `first line\nsecond line, edited\n`)
+	write(http.MethodPut, postUrl, "Revised Post Again", `first line\nsecond line, edited\nthird line\n`)
+
+	t.Run("REVISION_list_and_get", func(t *testing.T) {
+		var revisions []model.PostRevisionResponse
+		require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodGet, postUrl+"/revisions", "", token), &revisions).Code)
+		require.Len(t, revisions, 3)
+		require.Equal(t, 3, revisions[0].Number)
+		require.Equal(t, "Revised Post Again", revisions[0].Title)
+		require.Empty(t, revisions[0].Content)
+
+		revision := model.PostRevisionResponse{}
+		require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodGet, postUrl+"/revisions/1", "", token), &revision).Code)
+		require.Equal(t, "first line\nsecond line\n", revision.Content)
+		require.Equal(t, "user 3", revision.Author)
+
+		require.Equal(t, http.StatusNotFound, serve(t, newRequestWithToken(http.MethodGet, postUrl+"/revisions/99", "", token), nil).Code)
+	})
+
+	t.Run("REVISION_unchanged_update_is_not_a_revision", func(t *testing.T) {
+		requestBody := `{"title":"Revised Post Again", "content":"first line\nsecond line, edited\nthird line\n", "status":"published"}`
+		require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodPut, postUrl, requestBody, token), nil).Code)
+
+		var revisions []model.PostRevisionResponse
+		serve(t, newRequestWithToken(http.MethodGet, postUrl+"/revisions", "", token), &revisions)
+		require.Len(t, revisions, 3)
+	})
+
+	t.Run("REVISION_diff", func(t *testing.T) {
+		diff := model.PostRevisionDiffResponse{}
+		require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodGet, postUrl+"/revisions/diff?from=1&to=3", "", token), &diff).Code)
+		require.Equal(t, utils.DIFF_MODE_LINE, diff.Mode)
+		require.Equal(t, []model.DiffChunk{
+			{Op: utils.DIFF_OP_EQUAL, Text: "first line\n"},
+			{Op: utils.DIFF_OP_DELETE, Text: "second line\n"},
+			{Op: utils.DIFF_OP_INSERT, Text: "second line, edited\nthird line\n"},
+		}, diff.Content)
+		require.Equal(t, []model.DiffChunk{
+			{Op: utils.DIFF_OP_EQUAL, Text: "Revised Post"},
+			{Op: utils.DIFF_OP_INSERT, Text: " Again"},
+		}, diff.Title)
+
+		require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodGet, postUrl+"/revisions/diff?from=1&to=2&mode=word", "", token), &diff).Code)
+		require.Equal(t, []model.DiffChunk{
+			{Op: utils.DIFF_OP_EQUAL, Text: "first line\nsecond "},
+			{Op: utils.DIFF_OP_DELETE, Text: "line"},
+			{Op: utils.DIFF_OP_INSERT, Text: "line, edited"},
+			{Op: utils.DIFF_OP_EQUAL, Text: "\n"},
+		}, diff.Content)
+
+		require.Equal(t, http.StatusBadRequest, serve(t, newRequestWithToken(http.MethodGet, postUrl+"/revisions/diff?from=1", "", token), nil).Code)
+		require.Equal(t, http.StatusNotFound, serve(t, newRequestWithToken(http.MethodGet, postUrl+"/revisions/diff?from=1&to=99", "", token), nil).Code)
+	})
+
+	t.Run("REVISION_restore", func(t *testing.T) {
+		restored := model.PostResponse{}
+		require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodPost, postUrl+"/revisions/1/restore", "", token), &restored).Code)
+		require.Equal(t, "Revised Post", restored.Title)
+		require.Equal(t, "first line\nsecond line\n", restored.Content)
+		require.Equal(t, post.Slug, restored.Slug)
+		require.Len(t, restored.Reactions, len(constant.REACTION_KINDS))
+
+		// Restoring adds a revision, the newer versions stay
+		var revisions []model.PostRevisionResponse
+		serve(t, newRequestWithToken(http.MethodGet, postUrl+"/revisions", "", token), &revisions)
+		require.Len(t, revisions, 4)
+		require.Equal(t, 4, revisions[0].Number)
+		require.Equal(t, 1, *revisions[0].RestoredFrom)
+	})
+
+	t.Run("REVISION_other_author", func(t *testing.T) {
+		otherToken := login("user1@mail.com", "user1")
+		require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodGet, postUrl+"/revisions", "", otherToken), nil).Code)
+
+		require.Equal(t, http.StatusNotFound, serve(t, newRequestWithToken(http.MethodGet,
+			fmt.Sprintf("%s/%d/revisions", postAdminUrl, 1), "", token), nil).Code)
+	})
+}
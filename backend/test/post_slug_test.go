@@ -0,0 +1,97 @@
+package test
+
+import (
+	"backend/internal/model"
+	"backend/internal/utils"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var postSlugUrl = "http://127.0.0.1:5000/api/posts/by-slug"
+
+func TestPostSlug(t *testing.T) {
+	token := login("user3@mail.com", "user3")
+
+	// redirect returns where the request is permanently redirected to
+	redirect := func(request *http.Request) string {
+		recorder := httptest.NewRecorder()
+		app.ServeHTTP(recorder, request)
+		require.Equal(t, http.StatusMovedPermanently, recorder.Code)
+
+		return recorder.Header().Get("Location")
+	}
+	create := func(title string, status string) model.PostResponse {
+		requestBody := fmt.Sprintf(`{"title":"%s", "content":"SLUG_CONTENT", "status":"%s"}`, title, status)
+		post := model.PostResponse{}
+		require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodPost, postAdminUrl, requestBody, token), &post).Code)
+
+		return post
+	}
+	rename := func(ID uint64, title string) model.PostResponse {
+		requestBody := fmt.Sprintf(`{"title":"%s", "content":"SLUG_CONTENT"}`, title)
+		post := model.PostResponse{}
+		require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodPut, fmt.Sprintf("%s/%d", postAdminUrl, ID), requestBody, token), &post).Code)
+
+		return post
+	}
+
+	first := create("Crème Brûlée, Step by Step!", "published")
+	require.Equal(t, "creme-brulee-step-by-step", first.Slug)
+
+	t.Run("SLUG_duplicate_titles_get_a_suffix", func(t *testing.T) {
+		second := create("Creme brulee: step by step", "published")
+		require.Equal(t, "creme-brulee-step-by-step-2", second.Slug)
+	})
+
+	t.Run("SLUG_get_by_slug", func(t *testing.T) {
+		post := model.PostResponse{}
+		require.Equal(t, http.StatusOK, serve(t, newRequest(http.MethodGet, postSlugUrl+"/creme-brulee-step-by-step", ""), &post).Code)
+		require.Equal(t, first.ID, post.ID)
+
+		require.Equal(t, http.StatusNotFound, serve(t, newRequest(http.MethodGet, postSlugUrl+"/not-a-post", ""), nil).Code)
+
+		// Drafts have a slug, but aren't public yet
+		draft := create("Slug Draft", "draft")
+		require.Equal(t, http.StatusNotFound, serve(t, newRequest(http.MethodGet, postSlugUrl+"/"+draft.Slug, ""), nil).Code)
+	})
+
+	t.Run("SLUG_renamed_post_redirects", func(t *testing.T) {
+		renamed := rename(first.ID, "Привет, мир")
+		require.Equal(t, "privet-mir", renamed.Slug)
+
+		require.Equal(t, "/api/posts/by-slug/privet-mir", redirect(newRequest(http.MethodGet, postSlugUrl+"/creme-brulee-step-by-step", "")))
+
+		// The old slug still belongs to the renamed post
+		third := create("Crème Brûlée, Step by Step!", "published")
+		require.Equal(t, "creme-brulee-step-by-step-3", third.Slug)
+
+		// Renaming the post back gives it its old slug back
+		renamed = rename(first.ID, "Crème Brûlée, Step by Step!")
+		require.Equal(t, "creme-brulee-step-by-step", renamed.Slug)
+
+		require.Equal(t, "/api/posts/by-slug/creme-brulee-step-by-step", redirect(newRequest(http.MethodGet, postSlugUrl+"/privet-mir", "")))
+		require.Equal(t, "/api/posts/by-slug/creme-brulee-step-by-step?ref=feed", redirect(newRequest(http.MethodGet, postSlugUrl+"/privet-mir?ref=feed", "")))
+
+		post := model.PostResponse{}
+		require.Equal(t, http.StatusOK, serve(t, newRequest(http.MethodGet, postSlugUrl+"/creme-brulee-step-by-step", ""), &post).Code)
+		require.Equal(t, first.ID, post.ID)
+	})
+
+	t.Run("SLUG_suffix_fits_max_length", func(t *testing.T) {
+		title := strings.Repeat("Slug ", utils.SLUG_MAX_LENGTH/5)
+		long := create(title, "published")
+		require.Equal(t, utils.SLUG_MAX_LENGTH-1, len(long.Slug))
+
+		duplicate := create(title, "published")
+		require.LessOrEqual(t, len(duplicate.Slug), utils.SLUG_MAX_LENGTH)
+		require.True(t, strings.HasSuffix(duplicate.Slug, "-2"))
+
+		// Saving the same title again keeps the shortened slug
+		require.Equal(t, duplicate.Slug, rename(duplicate.ID, title).Slug)
+	})
+}
@@ -0,0 +1,106 @@
+package test
+
+import (
+	"backend/internal/apperror"
+	"backend/internal/constant"
+	"backend/internal/model"
+	"backend/internal/repository"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostStat(t *testing.T) {
+	ctx := context.Background()
+	editorToken := login("user2@mail.com", "user2")
+	authorToken := login("user3@mail.com", "user3")
+
+	post := model.PostResponse{}
+	response := serve(t, newRequestWithToken(http.MethodPost, postAdminUrl,
+		`{"title":"Viewed Post", "content":"POST_STAT_CONTENT", "status":"published"}`, authorToken), &post)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	postUrl := fmt.Sprintf("%s/%d", postGuestUrl, post.ID)
+	statsUrl := fmt.Sprintf("%s/%d/stats", postAdminUrl, post.ID)
+	today := time.Now().UTC().Format(time.DateOnly)
+
+	view := func(request *http.Request, ip string, userAgent string) {
+		request.RemoteAddr = ip + ":4000"
+		request.Header.Set("User-Agent", userAgent)
+		require.Equal(t, http.StatusOK, serve(t, request, nil).Code)
+	}
+	todayStats := func() model.PostDayStatsResponse {
+		stats := model.PostStatsResponse{}
+		require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodGet, statsUrl, "", authorToken), &stats).Code)
+		require.Equal(t, today, stats.To)
+		require.Len(t, stats.Days, 30)
+
+		return stats.Days[len(stats.Days)-1]
+	}
+
+	t.Run("POST_STAT_views", func(t *testing.T) {
+		// A visitor is counted once within the window
+		view(newRequest(http.MethodGet, postUrl, ""), "10.0.0.1", "browser A")
+		view(newRequest(http.MethodGet, postUrl, ""), "10.0.0.1", "browser A")
+		view(newRequest(http.MethodGet, postUrl, ""), "10.0.0.1", "browser B")
+		// Clients can't pass for new visitors with forwarding headers, only trusted proxies set them
+		request := newRequest(http.MethodGet, postUrl, "")
+		request.Header.Set(echo.HeaderXForwardedFor, "203.0.113.7")
+		request.Header.Set(echo.HeaderXRealIP, "203.0.113.8")
+		view(request, "10.0.0.1", "browser A")
+		view(newRequestWithToken(http.MethodGet, postUrl, "", editorToken), "10.0.0.2", "browser A")
+		view(newRequestWithToken(http.MethodGet, postGuestUrl+"/by-slug/"+post.Slug, "", editorToken), "10.0.0.3", "browser C")
+
+		// Views only show once they are flushed
+		require.Equal(t, int64(0), todayStats().Views)
+		require.Nil(t, jobs.Run(ctx, constant.JOB_FLUSH_POST_STATS))
+		require.Equal(t, model.PostDayStatsResponse{Date: today, Views: 3, Uniques: 3}, todayStats())
+
+		// Later flushes add up
+		view(newRequest(http.MethodGet, postUrl, ""), "10.0.0.4", "browser A")
+		require.Nil(t, jobs.Run(ctx, constant.JOB_FLUSH_POST_STATS))
+		require.Nil(t, jobs.Run(ctx, constant.JOB_FLUSH_POST_STATS))
+		require.Equal(t, model.PostDayStatsResponse{Date: today, Views: 4, Uniques: 4}, todayStats())
+	})
+
+	t.Run("POST_STAT_unfinished_flush", func(t *testing.T) {
+		// A flush took the views, then died before committing them
+		view(newRequest(http.MethodGet, postUrl, ""), "10.0.0.5", "browser A")
+		postViewCounterRepository := repository.NewPostViewCounterRepository()
+		stats, err := postViewCounterRepository.Claim(ctx, redisClient, 100, time.Now().Add(-time.Hour))
+		require.Nil(t, err)
+		require.Nil(t, postViewCounterRepository.Take(ctx, redisClient, stats))
+
+		// Views counted meanwhile wait until the claim is taken over, and are flushed with the taken ones
+		view(newRequest(http.MethodGet, postUrl, ""), "10.0.0.6", "browser A")
+		require.Nil(t, jobs.Run(ctx, constant.JOB_FLUSH_POST_STATS))
+		require.Equal(t, model.PostDayStatsResponse{Date: today, Views: 6, Uniques: 6}, todayStats())
+		require.Nil(t, jobs.Run(ctx, constant.JOB_FLUSH_POST_STATS))
+		require.Equal(t, int64(6), todayStats().Views)
+	})
+
+	t.Run("POST_STAT_range", func(t *testing.T) {
+		stats := model.PostStatsResponse{}
+		response := serve(t, newRequestWithToken(http.MethodGet, statsUrl+"?from="+today+"&to="+today, "", editorToken), &stats)
+		require.Equal(t, http.StatusOK, response.Code)
+		require.Len(t, stats.Days, 1)
+		require.Equal(t, int64(6), stats.Views)
+
+		response = serve(t, newRequestWithToken(http.MethodGet, statsUrl+"?from=2026-02-01&to=2026-01-01", "", authorToken), nil)
+		require.Equal(t, http.StatusBadRequest, response.Code)
+		require.Equal(t, apperror.CODE_INVALID_DATE_RANGE, response.ErrorCode)
+
+		response = serve(t, newRequestWithToken(http.MethodGet, statsUrl+"?from=2024-01-01&to=2026-01-01", "", authorToken), nil)
+		require.Equal(t, apperror.CODE_INVALID_DATE_RANGE, response.ErrorCode)
+
+		response = serve(t, newRequestWithToken(http.MethodGet, statsUrl+"?from=yesterday", "", authorToken), nil)
+		require.Equal(t, http.StatusBadRequest, response.Code)
+
+		require.Equal(t, http.StatusUnauthorized, serve(t, newRequest(http.MethodGet, statsUrl, ""), nil).Code)
+	})
+}
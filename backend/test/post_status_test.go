@@ -0,0 +1,124 @@
+package test
+
+import (
+	"backend/internal/apperror"
+	"backend/internal/constant"
+	"backend/internal/entity"
+	"backend/internal/model"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostStatus(t *testing.T) {
+	token := login("user3@mail.com", "user3")
+
+	servePost := func(request *http.Request) (int, model.PostResponse) {
+		post := model.PostResponse{}
+		return serve(t, request, &post).Code, post
+	}
+	guestGet := func(ID uint64) (int, model.PostResponse) {
+		return servePost(newRequest(http.MethodGet, fmt.Sprintf("%s/%d", postGuestUrl, ID), ""))
+	}
+	update := func(ID uint64, requestBody string) (int, model.PostResponse) {
+		return servePost(newRequestWithToken(http.MethodPut, fmt.Sprintf("%s/%d", postAdminUrl, ID), requestBody, token))
+	}
+	listIDs := func(request *http.Request) []uint64 {
+		var posts []model.PostResponse
+		require.Equal(t, http.StatusOK, serve(t, request, &posts).Code)
+
+		var IDs []uint64
+		for _, post := range posts {
+			IDs = append(IDs, post.ID)
+		}
+		return IDs
+	}
+
+	var draftID uint64
+
+	t.Run("POST_STATUS_new_posts_are_drafts", func(t *testing.T) {
+		code, post := servePost(newRequestWithToken(http.MethodPost, postAdminUrl,
+			`{"title":"DRAFT_POST", "content":"DRAFT_CONTENT"}`, token))
+		require.Equal(t, http.StatusOK, code)
+		require.Equal(t, constant.POST_STATUS_DRAFT, post.Status)
+		require.Nil(t, post.PublishedAt)
+		draftID = post.ID
+
+		code, _ = guestGet(draftID)
+		require.Equal(t, http.StatusNotFound, code)
+		require.NotContains(t, listIDs(newRequest(http.MethodGet, postGuestUrl, "")), draftID)
+
+		// The author still sees the draft
+		code, post = servePost(newRequestWithToken(http.MethodGet, fmt.Sprintf("%s/%d", postAdminUrl, draftID), "", token))
+		require.Equal(t, http.StatusOK, code)
+		require.Equal(t, "DRAFT_POST", post.Title)
+		require.Contains(t, listIDs(newRequestWithToken(http.MethodGet, postAdminUrl+"?status=draft", "", token)), draftID)
+		require.NotContains(t, listIDs(newRequestWithToken(http.MethodGet, postAdminUrl+"?status=published", "", token)), draftID)
+	})
+
+	t.Run("POST_STATUS_publish_and_archive", func(t *testing.T) {
+		code, post := update(draftID, `{"title":"DRAFT_POST", "content":"DRAFT_CONTENT", "status":"published"}`)
+		require.Equal(t, http.StatusOK, code)
+		require.NotNil(t, post.PublishedAt)
+		publishedAt := *post.PublishedAt
+
+		code, _ = guestGet(draftID)
+		require.Equal(t, http.StatusOK, code)
+		require.Contains(t, listIDs(newRequest(http.MethodGet, postGuestUrl, "")), draftID)
+
+		// Editing a post without a status keeps it published, at the time it was first published
+		_, post = update(draftID, `{"title":"EDITED_POST", "content":"DRAFT_CONTENT"}`)
+		require.Equal(t, constant.POST_STATUS_PUBLISHED, post.Status)
+		require.Equal(t, publishedAt, *post.PublishedAt)
+
+		_, post = update(draftID, `{"title":"EDITED_POST", "content":"DRAFT_CONTENT", "status":"archived"}`)
+		require.Equal(t, constant.POST_STATUS_ARCHIVED, post.Status)
+		code, _ = guestGet(draftID)
+		require.Equal(t, http.StatusNotFound, code)
+	})
+
+	t.Run("POST_STATUS_scheduled", func(t *testing.T) {
+		response := serve(t, newRequestWithToken(http.MethodPost, postAdminUrl,
+			`{"title":"LATE_POST", "content":"LATE_CONTENT", "status":"scheduled"}`, token), nil)
+		require.Equal(t, http.StatusBadRequest, response.Code)
+		require.Equal(t, apperror.CODE_INVALID_PUBLISH_TIME, response.ErrorCode)
+
+		publishAt := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+		code, post := servePost(newRequestWithToken(http.MethodPost, postAdminUrl,
+			`{"title":"SCHEDULED_POST", "content":"SCHEDULED_CONTENT", "status":"scheduled", "published_at":"`+publishAt+`"}`, token))
+		require.Equal(t, http.StatusOK, code)
+		require.Equal(t, constant.POST_STATUS_SCHEDULED, post.Status)
+		scheduledID := post.ID
+
+		// Not due yet
+		require.Nil(t, jobs.Run(context.Background(), constant.JOB_PUBLISH_SCHEDULED_POSTS))
+		code, _ = guestGet(scheduledID)
+		require.Equal(t, http.StatusNotFound, code)
+
+		require.Nil(t, db.Model(new(entity.Post)).Where("id = ?", scheduledID).
+			Update("published_at", time.Now().Add(-time.Second)).Error)
+
+		// Replicas run the job at the same time, each post is published once
+		errs := make(chan error, 3)
+		for i := 0; i < cap(errs); i++ {
+			go func() {
+				errs <- jobs.Run(context.Background(), constant.JOB_PUBLISH_SCHEDULED_POSTS)
+			}()
+		}
+		for i := 0; i < cap(errs); i++ {
+			require.Nil(t, <-errs)
+		}
+
+		code, post = guestGet(scheduledID)
+		require.Equal(t, http.StatusOK, code)
+		require.Equal(t, constant.POST_STATUS_PUBLISHED, post.Status)
+	})
+
+	t.Run("POST_STATUS_invalid_status_filter", func(t *testing.T) {
+		require.Equal(t, http.StatusBadRequest, serve(t, newRequestWithToken(http.MethodGet, postAdminUrl+"?status=secret", "", token), nil).Code)
+	})
+}
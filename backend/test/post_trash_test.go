@@ -0,0 +1,85 @@
+package test
+
+import (
+	"backend/internal/constant"
+	"backend/internal/entity"
+	"backend/internal/model"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostTrash(t *testing.T) {
+	token := login("user3@mail.com", "user3")
+
+	create := func(title string) (uint64, string) {
+		post := model.PostResponse{}
+		requestBody := fmt.Sprintf(`{"title":"%s", "content":"TRASH_CONTENT", "status":"published"}`, title)
+		require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodPost, postAdminUrl, requestBody, token), &post).Code)
+
+		return post.ID, fmt.Sprintf("%s/%d", postAdminUrl, post.ID)
+	}
+	trashIDs := func() []uint64 {
+		var posts []model.PostResponse
+		require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodGet, postAdminUrl+"/trash", "", token), &posts).Code)
+
+		var IDs []uint64
+		for _, post := range posts {
+			require.NotNil(t, post.DeletedAt)
+			IDs = append(IDs, post.ID)
+		}
+		return IDs
+	}
+
+	t.Run("TRASH_delete_and_restore", func(t *testing.T) {
+		ID, postUrl := create("Trashed Post")
+		guestUrl := fmt.Sprintf("%s/%d", postGuestUrl, ID)
+
+		require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodDelete, postUrl, "", token), nil).Code)
+		require.Equal(t, http.StatusNotFound, serve(t, newRequest(http.MethodGet, guestUrl, ""), nil).Code)
+		require.Equal(t, http.StatusNotFound, serve(t, newRequestWithToken(http.MethodGet, postUrl, "", token), nil).Code)
+		require.Contains(t, trashIDs(), ID)
+
+		// Posts in the trash can't be edited, only restored
+		require.Equal(t, http.StatusNotFound, serve(t, newRequestWithToken(http.MethodPut, postUrl,
+			`{"title":"Edited", "content":"TRASH_CONTENT"}`, token), nil).Code)
+
+		// Other authors can't restore it
+		otherToken := login("johndoe@mail.com", "johndoe")
+		require.Equal(t, http.StatusNotFound, serve(t, newRequestWithToken(http.MethodPost, postUrl+"/restore", "", otherToken), nil).Code)
+
+		restored := model.PostResponse{}
+		require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodPost, postUrl+"/restore", "", token), &restored).Code)
+		require.Nil(t, restored.DeletedAt)
+		require.Equal(t, constant.POST_STATUS_PUBLISHED, restored.Status)
+		require.NotContains(t, trashIDs(), ID)
+		require.Equal(t, http.StatusOK, serve(t, newRequest(http.MethodGet, guestUrl, ""), nil).Code)
+
+		require.Equal(t, http.StatusNotFound, serve(t, newRequestWithToken(http.MethodPost, postUrl+"/restore", "", token), nil).Code)
+	})
+
+	t.Run("TRASH_purge", func(t *testing.T) {
+		oldID, oldUrl := create("Long Trashed Post")
+		recentID, recentUrl := create("Recently Trashed Post")
+		require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodDelete, oldUrl, "", token), nil).Code)
+		require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodDelete, recentUrl, "", token), nil).Code)
+
+		retention := time.Duration(viperConfig.GetInt("post.trashRetentionDays")) * 24 * time.Hour
+		require.Nil(t, db.Unscoped().Model(new(entity.Post)).Where("id = ?", oldID).
+			Update("deleted_at", time.Now().Add(-retention-time.Hour)).Error)
+
+		require.Nil(t, jobs.Run(context.Background(), constant.JOB_PURGE_TRASHED_POSTS))
+
+		var count int64
+		require.Nil(t, db.Unscoped().Model(new(entity.Post)).Where("id = ?", oldID).Count(&count).Error)
+		require.Zero(t, count)
+		require.Nil(t, db.Model(new(entity.PostRevision)).Where("post_id = ?", oldID).Count(&count).Error)
+		require.Zero(t, count)
+
+		require.Contains(t, trashIDs(), recentID)
+	})
+}
@@ -0,0 +1,98 @@
+package test
+
+import (
+	"backend/internal/constant"
+	"backend/internal/entity"
+	"backend/internal/model"
+	"backend/internal/utils"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReaction(t *testing.T) {
+	ctx := context.Background()
+	adminToken := login("user1@mail.com", "user1")
+	editorToken := login("user2@mail.com", "user2")
+	authorToken := login("user3@mail.com", "user3")
+
+	toggle := func(postID uint64, kind string, token string) model.ReactionToggleResponse {
+		result := model.ReactionToggleResponse{}
+		code := serve(t, newRequestWithToken(http.MethodPost, fmt.Sprintf("%s/%d/reactions/%s", postGuestUrl, postID, kind), "", token), &result).Code
+		require.Equal(t, http.StatusOK, code)
+
+		return result
+	}
+	reactions := func(postID uint64) map[string]int64 {
+		post := model.PostResponse{}
+		require.Equal(t, http.StatusOK, serve(t, newRequest(http.MethodGet, fmt.Sprintf("%s/%d", postGuestUrl, postID), ""), &post).Code)
+
+		return post.Reactions
+	}
+
+	post := model.PostResponse{}
+	require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodPost, postAdminUrl,
+		`{"title":"Reacted Post", "content":"REACTION_CONTENT", "status":"published"}`, authorToken), &post).Code)
+	require.Equal(t, int64(0), post.Reactions[constant.REACTION_LIKE])
+	require.Len(t, post.Reactions, len(constant.REACTION_KINDS))
+
+	t.Run("REACTION_toggle", func(t *testing.T) {
+		result := toggle(post.ID, "like", adminToken)
+		require.True(t, result.Reacted)
+		require.Equal(t, int64(1), result.Reactions["like"])
+
+		toggle(post.ID, "like", editorToken)
+		toggle(post.ID, "clap", adminToken)
+		require.Equal(t, int64(2), reactions(post.ID)["like"])
+		require.Equal(t, int64(1), reactions(post.ID)["clap"])
+
+		// Reacting again with the same kind takes the reaction back
+		result = toggle(post.ID, "clap", adminToken)
+		require.False(t, result.Reacted)
+		require.Equal(t, int64(0), result.Reactions["clap"])
+
+		var posts []model.PostResponse
+		require.Equal(t, http.StatusOK, serve(t, newRequest(http.MethodGet, postGuestUrl+"?title=Reacted", ""), &posts).Code)
+		require.Len(t, posts, 1)
+		require.Equal(t, int64(2), posts[0].Reactions["like"])
+
+		require.Equal(t, http.StatusBadRequest,
+			serve(t, newRequestWithToken(http.MethodPost, fmt.Sprintf("%s/%d/reactions/angry", postGuestUrl, post.ID), "", adminToken), nil).Code)
+		require.Equal(t, http.StatusUnauthorized,
+			serve(t, newRequest(http.MethodPost, fmt.Sprintf("%s/%d/reactions/like", postGuestUrl, post.ID), ""), nil).Code)
+	})
+
+	t.Run("REACTION_reconcile", func(t *testing.T) {
+		// Counts only reach the database when the job reconciles them
+		var stored []entity.PostReactionCount
+		require.Nil(t, db.Where("post_id = ?", post.ID).Find(&stored).Error)
+		require.Empty(t, stored)
+
+		require.Nil(t, jobs.Run(ctx, constant.JOB_RECONCILE_POST_REACTIONS))
+		require.Nil(t, db.Where("post_id = ?", post.ID).Find(&stored).Error)
+		require.Equal(t, []entity.PostReactionCount{{PostID: post.ID, Kind: "like", Count: 2}}, stored)
+
+		// A drifted counter is corrected from the reactions themselves
+		key := utils.GeneratePostReactionsRedisKey(post.ID)
+		require.Nil(t, redisClient.HSet(ctx, key, "like", 7).Err())
+		require.Equal(t, int64(7), reactions(post.ID)["like"])
+		require.Nil(t, redisClient.SAdd(ctx, utils.GenerateDirtyPostReactionsRedisKey(), post.ID).Err())
+		require.Nil(t, jobs.Run(ctx, constant.JOB_RECONCILE_POST_REACTIONS))
+		require.Equal(t, int64(2), reactions(post.ID)["like"])
+
+		// Without the counter in Redis, the reconciled counts are read and put back
+		require.Nil(t, redisClient.Del(ctx, key).Err())
+		require.Equal(t, int64(2), reactions(post.ID)["like"])
+		require.Equal(t, int64(1), redisClient.Exists(ctx, key).Val())
+
+		// Redis lost a toggle that wasn't reconciled yet: the stale stored count is shown, then corrected
+		toggle(post.ID, "clap", editorToken)
+		require.Nil(t, redisClient.Del(ctx, key, utils.GenerateDirtyPostReactionsRedisKey()).Err())
+		require.Equal(t, int64(0), reactions(post.ID)["clap"])
+		require.Nil(t, jobs.Run(ctx, constant.JOB_RECONCILE_POST_REACTIONS))
+		require.Equal(t, int64(1), reactions(post.ID)["clap"])
+	})
+}
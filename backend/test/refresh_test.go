@@ -0,0 +1,111 @@
+package test
+
+import (
+	"backend/internal/constant"
+	"backend/internal/model"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var refreshUrl = "http://127.0.0.1:5000/api/auth/refresh"
+
+// loginWithCookie logs in and returns the access token and the refresh token cookie
+func loginWithCookie(t *testing.T, email string, password string) (string, *http.Cookie) {
+	request := newRequest(http.MethodPost, loginUrl, `{"email":"`+email+`", "password":"`+password+`"}`)
+
+	recorder := httptest.NewRecorder()
+	app.ServeHTTP(recorder, request)
+	response := recorder.Result()
+
+	responseBody, _ := io.ReadAll(response.Body)
+	testResponse := new(TestResponse[model.TokenData])
+	require.Nil(t, json.Unmarshal(responseBody, testResponse))
+	require.Equal(t, http.StatusOK, testResponse.Code)
+
+	return testResponse.Data.AccessToken, findRefreshCookie(t, response)
+}
+
+// refresh presents cookie to /api/auth/refresh and returns the response code and the rotated cookie, if any
+func refresh(t *testing.T, cookie *http.Cookie) (int, string, *http.Cookie) {
+	request := newRequest(http.MethodPost, refreshUrl, "")
+	request.AddCookie(cookie)
+
+	recorder := httptest.NewRecorder()
+	app.ServeHTTP(recorder, request)
+	response := recorder.Result()
+
+	responseBody, _ := io.ReadAll(response.Body)
+	testResponse := new(TestResponse[model.TokenData])
+	require.Nil(t, json.Unmarshal(responseBody, testResponse))
+
+	if testResponse.Code != http.StatusOK {
+		return testResponse.Code, "", nil
+	}
+
+	return testResponse.Code, testResponse.Data.AccessToken, findRefreshCookie(t, response)
+}
+
+func findRefreshCookie(t *testing.T, response *http.Response) *http.Cookie {
+	for _, cookie := range response.Cookies() {
+		if cookie.Name == constant.REFRESH_TOKEN_COOKIE_NAME && cookie.Value != "" {
+			return cookie
+		}
+	}
+
+	require.Fail(t, "response has no refresh token cookie")
+	return nil
+}
+
+func TestRefreshRotation(t *testing.T) {
+	_, firstCookie := loginWithCookie(t, "user3@mail.com", "user3")
+
+	// Every refresh returns a new refresh token
+	code, accessToken, secondCookie := refresh(t, firstCookie)
+	require.Equal(t, http.StatusOK, code)
+	require.NotEmpty(t, accessToken)
+	require.NotEqual(t, firstCookie.Value, secondCookie.Value)
+
+	code, accessToken, thirdCookie := refresh(t, secondCookie)
+	require.Equal(t, http.StatusOK, code)
+	require.NotEqual(t, secondCookie.Value, thirdCookie.Value)
+
+	// The latest access token works until the family is revoked
+	recorder := httptest.NewRecorder()
+	app.ServeHTTP(recorder, newRequestWithToken(http.MethodGet, sessionUrl, "", accessToken))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	t.Run("REFRESH_REPLAY_revokes_family", func(t *testing.T) {
+		// Presenting an already rotated refresh token is rejected...
+		code, _, _ := refresh(t, firstCookie)
+		require.Equal(t, http.StatusUnauthorized, code)
+
+		// ...and revokes every token of the family, including the latest refresh token
+		code, _, _ = refresh(t, thirdCookie)
+		require.Equal(t, http.StatusUnauthorized, code)
+
+		// ...and the access token of the session, so the user has to log in again
+		recorder := httptest.NewRecorder()
+		app.ServeHTTP(recorder, newRequestWithToken(http.MethodGet, sessionUrl, "", accessToken))
+		require.Equal(t, http.StatusUnauthorized, recorder.Code)
+	})
+
+	t.Run("REFRESH_REPLAY_keeps_other_families", func(t *testing.T) {
+		_, laptopCookie := loginWithCookie(t, "user3@mail.com", "user3")
+		_, phoneCookie := loginWithCookie(t, "user3@mail.com", "user3")
+
+		code, _, _ := refresh(t, laptopCookie)
+		require.Equal(t, http.StatusOK, code)
+
+		// Replaying the laptop token only revokes the laptop session
+		code, _, _ = refresh(t, laptopCookie)
+		require.Equal(t, http.StatusUnauthorized, code)
+
+		code, _, _ = refresh(t, phoneCookie)
+		require.Equal(t, http.StatusOK, code)
+	})
+}
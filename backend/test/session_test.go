@@ -0,0 +1,109 @@
+package test
+
+import (
+	"backend/internal/entity"
+	"backend/internal/model"
+	"backend/internal/repository"
+	"backend/internal/utils"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+var sessionUrl = "http://127.0.0.1:5000/api/user/sessions"
+
+func TestSessions(t *testing.T) {
+	// Logging in twice, like from a laptop and a phone, keeps both sessions active
+	laptopToken := login("user2@mail.com", "user2")
+	phoneToken := login("user2@mail.com", "user2")
+	require.NotEmpty(t, laptopToken)
+	require.NotEmpty(t, phoneToken)
+
+	listSessions := func(token string) (int, []model.SessionResponse) {
+		var sessions []model.SessionResponse
+		code := serve(t, newRequestWithToken(http.MethodGet, sessionUrl, "", token), &sessions).Code
+
+		return code, sessions
+	}
+
+	code, sessions := listSessions(phoneToken)
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, sessions, 2)
+
+	var laptopSessionID string
+	for _, session := range sessions {
+		if !session.Current {
+			laptopSessionID = session.ID
+		}
+	}
+	require.NotEmpty(t, laptopSessionID)
+
+	t.Run("SESSION_Revoke_OK", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		app.ServeHTTP(recorder, newRequestWithToken(http.MethodDelete, sessionUrl+"/"+laptopSessionID, "", phoneToken))
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		// The revoked session can't be used anymore, the other one still works
+		code, _ := listSessions(laptopToken)
+		require.Equal(t, http.StatusUnauthorized, code)
+		code, _ = listSessions(phoneToken)
+		require.Equal(t, http.StatusOK, code)
+	})
+
+	t.Run("SESSION_Revoke_NOT_FOUND_other_user_session", func(t *testing.T) {
+		otherToken := login("user3@mail.com", "user3")
+		var otherSessionID string
+		_, sessions := listSessions(otherToken)
+		for _, session := range sessions {
+			if session.Current {
+				otherSessionID = session.ID
+			}
+		}
+		require.NotEmpty(t, otherSessionID)
+
+		recorder := httptest.NewRecorder()
+		app.ServeHTTP(recorder, newRequestWithToken(http.MethodDelete, sessionUrl+"/"+otherSessionID, "", phoneToken))
+		require.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+
+	t.Run("SESSION_RevokeAll_OK", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		app.ServeHTTP(recorder, newRequestWithToken(http.MethodDelete, sessionUrl, "", phoneToken))
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		code, _ := listSessions(phoneToken)
+		require.Equal(t, http.StatusUnauthorized, code)
+	})
+
+	t.Run("SESSION_Touch_revoked", func(t *testing.T) {
+		ctx := context.Background()
+		token := login("user2@mail.com", "user2")
+		authData, err := utils.ParseAccessToken(keyRing, token)
+		require.Nil(t, err)
+
+		sessionRepository := repository.NewSessionRepository()
+		session := new(entity.Session)
+		require.Nil(t, sessionRepository.FindByID(ctx, redisClient, session, authData.SessionID))
+
+		// Activity is recorded without touching the expiration of the session
+		ttl := redisClient.TTL(ctx, utils.GenerateSessionRedisKey(session.ID)).Val()
+		require.Nil(t, sessionRepository.Touch(ctx, redisClient, session))
+		touched := new(entity.Session)
+		require.Nil(t, sessionRepository.FindByID(ctx, redisClient, touched, session.ID))
+		require.True(t, touched.LastSeenAt.Equal(session.LastSeenAt))
+		require.InDelta(t, ttl.Seconds(), redisClient.TTL(ctx, utils.GenerateSessionRedisKey(session.ID)).Val().Seconds(), time.Second.Seconds())
+
+		// A request that read the session before it was revoked records its activity afterwards
+		require.Nil(t, sessionRepository.Delete(ctx, redisClient, session))
+		require.Nil(t, sessionRepository.Touch(ctx, redisClient, session))
+		require.Equal(t, redis.Nil, sessionRepository.FindByID(ctx, redisClient, touched, session.ID))
+
+		code, _ := listSessions(token)
+		require.Equal(t, http.StatusUnauthorized, code)
+	})
+}
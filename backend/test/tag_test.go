@@ -0,0 +1,97 @@
+package test
+
+import (
+	"backend/internal/apperror"
+	"backend/internal/model"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var tagUrl = "http://127.0.0.1:5000/api/tags"
+
+func TestTag(t *testing.T) {
+	token := login("user3@mail.com", "user3")
+
+	create := func(title string, status string, tags string) model.PostResponse {
+		post := model.PostResponse{}
+		requestBody := fmt.Sprintf(`{"title":"%s", "content":"TAG_CONTENT", "status":"%s", "tags":%s}`, title, status, tags)
+		response := serve(t, newRequestWithToken(http.MethodPost, postAdminUrl, requestBody, token), &post)
+		require.Equal(t, http.StatusOK, response.Code)
+
+		return post
+	}
+	listIDs := func(query string) []uint64 {
+		var posts []model.PostResponse
+		require.Equal(t, http.StatusOK, serve(t, newRequest(http.MethodGet, postGuestUrl+"?"+query, ""), &posts).Code)
+
+		IDs := []uint64{}
+		for _, post := range posts {
+			IDs = append(IDs, post.ID)
+		}
+		return IDs
+	}
+
+	postgres := create("Tagged Postgres", "published", `["Golang", " #Postgres  Tips "]`)
+	redis := create("Tagged Redis", "published", `["golang", "Redis", "GOLANG"]`)
+	create("Tagged Draft", "draft", `["Unreleased"]`)
+
+	t.Run("TAG_normalized_on_posts", func(t *testing.T) {
+		require.Equal(t, []model.TagResponse{{Name: "Golang", Slug: "golang"}, {Name: "Postgres Tips", Slug: "postgres-tips"}}, postgres.Tags)
+		// The tag keeps the name it was first written with
+		require.Equal(t, []model.TagResponse{{Name: "Golang", Slug: "golang"}, {Name: "Redis", Slug: "redis"}}, redis.Tags)
+
+		response := serve(t, newRequestWithToken(http.MethodPost, postAdminUrl,
+			`{"title":"Bad Tag", "content":"TAG_CONTENT", "tags":["!!!"]}`, token), nil)
+		require.Equal(t, http.StatusBadRequest, response.Code)
+		require.Equal(t, apperror.CODE_INVALID_TAG, response.ErrorCode)
+	})
+
+	t.Run("TAG_filters", func(t *testing.T) {
+		require.ElementsMatch(t, []uint64{postgres.ID, redis.ID}, listIDs("tag=Golang"))
+		require.ElementsMatch(t, []uint64{postgres.ID, redis.ID}, listIDs("tags=postgres-tips,redis"))
+		require.ElementsMatch(t, []uint64{redis.ID}, listIDs("tags=golang,redis&match=all"))
+		require.ElementsMatch(t, []uint64{}, listIDs("tags=postgres-tips,redis&match=all"))
+		require.ElementsMatch(t, []uint64{}, listIDs("tag=unreleased"))
+
+		require.Equal(t, http.StatusBadRequest, serve(t, newRequest(http.MethodGet, postGuestUrl+"?tags=golang&match=some", ""), nil).Code)
+
+		// A tag that can't have a slug doesn't turn the filter off
+		response := serve(t, newRequest(http.MethodGet, postGuestUrl+"?tag=%21%21%21", ""), nil)
+		require.Equal(t, http.StatusBadRequest, response.Code)
+		require.Equal(t, apperror.CODE_INVALID_TAG, response.ErrorCode)
+		response = serve(t, newRequest(http.MethodGet, postGuestUrl+"?tags=golang,", ""), nil)
+		require.Equal(t, http.StatusBadRequest, response.Code)
+		require.Equal(t, apperror.CODE_INVALID_TAG, response.ErrorCode)
+	})
+
+	t.Run("TAG_pages", func(t *testing.T) {
+		var tags []model.TagResponse
+		require.Equal(t, http.StatusOK, serve(t, newRequest(http.MethodGet, tagUrl, ""), &tags).Code)
+		require.Contains(t, tags, model.TagResponse{Name: "Golang", Slug: "golang", PostCount: 2})
+		for _, tag := range tags {
+			require.NotEqual(t, "unreleased", tag.Slug)
+		}
+
+		tag := model.TagResponse{}
+		require.Equal(t, http.StatusOK, serve(t, newRequest(http.MethodGet, tagUrl+"/redis", ""), &tag).Code)
+		require.Equal(t, model.TagResponse{Name: "Redis", Slug: "redis", PostCount: 1}, tag)
+
+		require.Equal(t, http.StatusNotFound, serve(t, newRequest(http.MethodGet, tagUrl+"/unreleased", ""), nil).Code)
+	})
+
+	t.Run("TAG_update", func(t *testing.T) {
+		postUrl := fmt.Sprintf("%s/%d", postAdminUrl, redis.ID)
+
+		// Leaving tags out keeps them, an empty list removes them
+		post := model.PostResponse{}
+		serve(t, newRequestWithToken(http.MethodPut, postUrl, `{"title":"Tagged Redis", "content":"EDITED"}`, token), &post)
+		require.Len(t, post.Tags, 2)
+
+		serve(t, newRequestWithToken(http.MethodPut, postUrl, `{"title":"Tagged Redis", "content":"EDITED", "tags":[]}`, token), &post)
+		require.Empty(t, post.Tags)
+		require.Equal(t, http.StatusNotFound, serve(t, newRequest(http.MethodGet, tagUrl+"/redis", ""), nil).Code)
+	})
+}
@@ -1,11 +1,21 @@
 package test
 
 import (
+	"backend/internal/model"
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
+	"testing"
 
 	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
 )
 
 func newRequest(method string, url string, requestBody string) *http.Request {
@@ -22,3 +32,57 @@ func newRequestWithToken(method string, url string, requestBody string, token st
 
 	return request
 }
+
+// serve sends request to the app and returns its response, decoding the data of a successful response into data unless it is nil
+func serve(t *testing.T, request *http.Request, data any) TestResponse[json.RawMessage] {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	app.ServeHTTP(recorder, request)
+
+	responseBody, _ := io.ReadAll(recorder.Result().Body)
+	testResponse := TestResponse[json.RawMessage]{}
+	require.Nil(t, json.Unmarshal(responseBody, &testResponse))
+	require.Equal(t, recorder.Code, testResponse.Code)
+	if testResponse.Code == http.StatusOK && data != nil {
+		require.Nil(t, json.Unmarshal(testResponse.Data, data))
+	}
+
+	return testResponse
+}
+
+// login returns a new access token for email and password, or an empty string if the login fails
+func login(email string, password string) string {
+	requestBody := fmt.Sprintf(`{"email":"%s", "password":"%s"}`, email, password)
+	request := newRequest(http.MethodPost, "http://127.0.0.1:5000/api/auth/login", requestBody)
+
+	recorder := httptest.NewRecorder()
+	app.ServeHTTP(recorder, request)
+
+	responseBody, _ := io.ReadAll(recorder.Result().Body)
+	testResponse := new(TestResponse[model.TokenData])
+	json.Unmarshal(responseBody, testResponse)
+
+	return testResponse.Data.AccessToken
+}
+
+var mailTokenRegex = regexp.MustCompile(`token=(\S+)`)
+
+// latestMailToken returns the token of the link in the latest email sent to `to`, or an empty string
+func latestMailToken(to string) string {
+	// File names start with the time they were written, so the latest email is the last one
+	entries, _ := os.ReadDir(mailDir)
+
+	for i := len(entries) - 1; i >= 0; i-- {
+		content, _ := os.ReadFile(filepath.Join(mailDir, entries[i].Name()))
+		if !strings.Contains(string(content), "To: "+to+"\r\n") {
+			continue
+		}
+
+		if match := mailTokenRegex.FindStringSubmatch(string(content)); match != nil {
+			token, _ := url.QueryUnescape(match[1])
+			return token
+		}
+	}
+
+	return ""
+}
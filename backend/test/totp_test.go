@@ -0,0 +1,150 @@
+package test
+
+import (
+	"backend/internal/entity"
+	"backend/internal/model"
+	"backend/internal/utils"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	totpUrl     = "http://127.0.0.1:5000/api/user/totp"
+	loginMFAUrl = "http://127.0.0.1:5000/api/auth/login/mfa"
+)
+
+func TestTOTP(t *testing.T) {
+	email := "totp@mail.com"
+
+	require.Equal(t, http.StatusOK, serve(t, newRequest(http.MethodPost, registerUrl,
+		fmt.Sprintf(`{"name":"Totp User", "email":"%s", "password":"totpuser"}`, email)), nil).Code)
+
+	loginStep := func(url string, requestBody string) (int, model.TokenData) {
+		tokenData := model.TokenData{}
+		code := serve(t, newRequest(http.MethodPost, url, requestBody), &tokenData).Code
+
+		return code, tokenData
+	}
+	mfaChallenge := func() string {
+		code, tokenData := loginStep(loginUrl, fmt.Sprintf(`{"email":"%s", "password":"totpuser"}`, email))
+		require.Equal(t, http.StatusOK, code)
+		require.True(t, tokenData.MFARequired)
+		require.Empty(t, tokenData.AccessToken)
+
+		return tokenData.MFAToken
+	}
+	loginMFA := func(mfaToken string, code string) (int, model.TokenData) {
+		return loginStep(loginMFAUrl, fmt.Sprintf(`{"mfa_token":"%s", "code":"%s"}`, mfaToken, code))
+	}
+
+	token := login(email, "totpuser")
+	require.NotEmpty(t, token)
+
+	enrollment := model.TOTPEnrollmentResponse{}
+	require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodPost, totpUrl, "", token), &enrollment).Code)
+	require.Contains(t, enrollment.URI, "otpauth://totp/")
+
+	// Two-factor authentication is off until a code from the authenticator app is confirmed
+	require.NotEmpty(t, login(email, "totpuser"))
+
+	require.Equal(t, http.StatusBadRequest, serve(t, newRequestWithToken(http.MethodPost, totpUrl+"/confirm", `{"code":"000000"}`, token), nil).Code)
+
+	code, err := utils.GenerateTOTPCode(enrollment.Secret, time.Now())
+	require.Nil(t, err)
+	recoveryCodes := model.RecoveryCodesResponse{}
+	require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodPost, totpUrl+"/confirm",
+		fmt.Sprintf(`{"code":"%s"}`, code), token), &recoveryCodes).Code)
+	require.Len(t, recoveryCodes.RecoveryCodes, 10)
+
+	t.Run("TOTP_LoginMFA_OK_recovery_code", func(t *testing.T) {
+		mfaToken := mfaChallenge()
+
+		statusCode, _ := loginMFA(mfaToken, "000000")
+		require.Equal(t, http.StatusUnauthorized, statusCode)
+
+		statusCode, tokenData := loginMFA(mfaToken, recoveryCodes.RecoveryCodes[0])
+		require.Equal(t, http.StatusOK, statusCode)
+		require.NotEmpty(t, tokenData.AccessToken)
+
+		// Challenges and recovery codes are single-use
+		statusCode, _ = loginMFA(mfaToken, recoveryCodes.RecoveryCodes[1])
+		require.Equal(t, http.StatusUnauthorized, statusCode)
+		statusCode, _ = loginMFA(mfaChallenge(), recoveryCodes.RecoveryCodes[0])
+		require.Equal(t, http.StatusUnauthorized, statusCode)
+	})
+
+	t.Run("TOTP_LoginMFA_OK_totp_code", func(t *testing.T) {
+		// The confirmation used the code of the current period, the next period is accepted too
+		code, err := utils.GenerateTOTPCode(enrollment.Secret, time.Now().Add(utils.TOTP_PERIOD*time.Second))
+		require.Nil(t, err)
+
+		statusCode, tokenData := loginMFA(mfaChallenge(), code)
+		require.Equal(t, http.StatusOK, statusCode)
+		require.NotEmpty(t, tokenData.AccessToken)
+
+		// A code can't be replayed
+		statusCode, _ = loginMFA(mfaChallenge(), code)
+		require.Equal(t, http.StatusUnauthorized, statusCode)
+	})
+
+	t.Run("TOTP_LoginMFA_locked", func(t *testing.T) {
+		user := new(entity.User)
+		require.Nil(t, db.First(user, "email = ?", email).Error)
+		unlock := func() {
+			require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodPost, userAdminUrl+"/"+user.ID+"/unlock", "",
+				login("user1@mail.com", "user1")), nil).Code)
+		}
+		// Forgets the failure of the replayed code above
+		unlock()
+
+		// Wrong codes lock the account like wrong passwords, starting new challenges doesn't help
+		pending := mfaChallenge()
+		for i := 0; i < 5; i++ {
+			statusCode, _ := loginMFA(mfaChallenge(), "000000")
+			require.Equal(t, http.StatusUnauthorized, statusCode)
+		}
+
+		// Even a right code is refused now, and isn't used up
+		statusCode, _ := loginMFA(pending, recoveryCodes.RecoveryCodes[3])
+		require.Equal(t, http.StatusTooManyRequests, statusCode)
+
+		unlock()
+		statusCode, tokenData := loginMFA(pending, recoveryCodes.RecoveryCodes[3])
+		require.Equal(t, http.StatusOK, statusCode)
+		require.NotEmpty(t, tokenData.AccessToken)
+	})
+
+	t.Run("TOTP_LoginMFA_recovery_code_race", func(t *testing.T) {
+		// The same recovery code sent with two challenges at once only logs in once
+		challenges := []string{mfaChallenge(), mfaChallenge()}
+		codes := make(chan int, len(challenges))
+		for _, mfaToken := range challenges {
+			go func(mfaToken string) {
+				recorder := httptest.NewRecorder()
+				app.ServeHTTP(recorder, newRequest(http.MethodPost, loginMFAUrl,
+					fmt.Sprintf(`{"mfa_token":"%s", "code":"%s"}`, mfaToken, recoveryCodes.RecoveryCodes[4])))
+				codes <- recorder.Code
+			}(mfaToken)
+		}
+
+		var statusCodes []int
+		for range challenges {
+			statusCodes = append(statusCodes, <-codes)
+		}
+		require.ElementsMatch(t, []int{http.StatusOK, http.StatusUnauthorized}, statusCodes)
+	})
+
+	t.Run("TOTP_Disable_OK", func(t *testing.T) {
+		require.Equal(t, http.StatusBadRequest, serve(t, newRequestWithToken(http.MethodDelete, totpUrl,
+			fmt.Sprintf(`{"password":"wrong-password", "code":"%s"}`, recoveryCodes.RecoveryCodes[2]), token), nil).Code)
+		require.Equal(t, http.StatusOK, serve(t, newRequestWithToken(http.MethodDelete, totpUrl,
+			fmt.Sprintf(`{"password":"totpuser", "code":"%s"}`, recoveryCodes.RecoveryCodes[2]), token), nil).Code)
+
+		require.NotEmpty(t, login(email, "totpuser"))
+	})
+}